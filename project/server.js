@@ -0,0 +1,96 @@
+// Serves the built frontend and forwards its API calls to the PDF API with
+// the API key attached, so the key stays on the server and never reaches the
+// browser bundle.
+//
+//   VIGOVIA_API_URL=http://localhost:5000 VIGOVIA_API_KEY=<raw-key> npm run serve
+import http from 'node:http';
+import https from 'node:https';
+import { readFile } from 'node:fs/promises';
+import { extname, join, normalize } from 'node:path';
+import { fileURLToPath } from 'node:url';
+
+const port = Number(process.env.PORT || 4173);
+const apiUrl = new URL(process.env.VIGOVIA_API_URL || 'http://localhost:5000');
+const apiKey = process.env.VIGOVIA_API_KEY || '';
+const root = fileURLToPath(new URL('./dist/', import.meta.url));
+
+// Only the calls the frontend makes are forwarded, so the proxy can't be used
+// to reach the rest of the API with its key; vite.config.ts keeps the same list
+// for the dev server
+const forwarded = new Map([
+  ['/api/v1/generate-pdf', 'POST'],
+  ['/api/v1/health', 'GET'],
+]);
+
+const contentTypes = {
+  '.html': 'text/html; charset=utf-8',
+  '.js': 'text/javascript',
+  '.css': 'text/css',
+  '.svg': 'image/svg+xml',
+  '.png': 'image/png',
+  '.json': 'application/json',
+};
+
+function sendError(res, status, error, message) {
+  res.writeHead(status, { 'Content-Type': 'application/json' });
+  res.end(JSON.stringify({ error, message }));
+}
+
+function proxy(req, res) {
+  const headers = { ...req.headers, host: apiUrl.host };
+  // Whatever the browser sends, the request goes out with the proxy's key
+  delete headers['x-api-key'];
+  delete headers.authorization;
+  if (apiKey) {
+    headers['x-api-key'] = apiKey;
+  }
+  const client = apiUrl.protocol === 'https:' ? https : http;
+  const upstream = client.request(
+    { protocol: apiUrl.protocol, hostname: apiUrl.hostname, port: apiUrl.port, path: req.url, method: req.method, headers },
+    (answer) => {
+      res.writeHead(answer.statusCode, answer.headers);
+      answer.pipe(res);
+    },
+  );
+  upstream.on('error', () => sendError(res, 502, 'Bad Gateway', 'The PDF API is not reachable'));
+  req.pipe(upstream);
+}
+
+// serveStatic sends a file from dist/, or index.html for the app's own routes
+async function serveStatic(pathname, res) {
+  const path = normalize(pathname);
+  for (const file of [join(root, path), join(root, 'index.html')]) {
+    try {
+      const body = await readFile(file);
+      res.writeHead(200, { 'Content-Type': contentTypes[extname(file)] || 'application/octet-stream' });
+      res.end(body);
+      return;
+    } catch {
+      // Not a file: try the next one
+    }
+  }
+  sendError(res, 404, 'Not Found', 'Run npm run build first');
+}
+
+http
+  .createServer((req, res) => {
+    let pathname;
+    try {
+      pathname = decodeURIComponent(new URL(req.url, 'http://localhost').pathname);
+    } catch {
+      sendError(res, 400, 'Bad Request', 'Malformed URL');
+      return;
+    }
+    if (pathname.startsWith('/api/')) {
+      if (forwarded.get(pathname) !== req.method) {
+        sendError(res, 404, 'Not Found', 'The frontend proxy only forwards PDF generation and health checks');
+        return;
+      }
+      proxy(req, res);
+      return;
+    }
+    serveStatic(pathname, res);
+  })
+  .listen(port, () => {
+    console.log(`Serving dist/ on http://localhost:${port}, forwarding the API to ${apiUrl.origin}`);
+  });
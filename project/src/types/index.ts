@@ -102,6 +102,7 @@ export interface InclusionItem {
 }
 
 export interface ItineraryData {
+  schemaVersion?: number;
   tripDetails: TripDetails;
   dailyItinerary: DayItinerary[];
   flights: Flight[];
@@ -1,4 +1,6 @@
-const API_BASE_URL = import.meta.env.VITE_API_URL || 'http://localhost:5000/api';
+// Requests go to the same origin, where the dev server or server.js forwards
+// them to the PDF API with its key. The key is never part of the bundle.
+const API_BASE_URL = import.meta.env.VITE_API_URL || '/api/v1';
 
 console.log('API Base URL:', API_BASE_URL);
 
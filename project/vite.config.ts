@@ -1,10 +1,35 @@
-import { defineConfig } from 'vite';
+import { defineConfig, loadEnv } from 'vite';
 import react from '@vitejs/plugin-react';
 
+// Only the calls the frontend makes are forwarded with the key, the same list
+// server.js forwards, so the dev proxy can't reach the rest of the API with it
+const forwarded = new Map([
+  ['/api/v1/generate-pdf', 'POST'],
+  ['/api/v1/health', 'GET'],
+]);
+
 // https://vitejs.dev/config/
-export default defineConfig({
-  plugins: [react()],
-  optimizeDeps: {
-    exclude: ['lucide-react'],
-  },
+export default defineConfig(({ mode }) => {
+  // VIGOVIA_ variables are read here, on the dev server, and are never
+  // bundled: only VITE_ variables reach the browser
+  const env = loadEnv(mode, '.', 'VIGOVIA_');
+  const apiProxy = {
+    '/api': {
+      target: env.VIGOVIA_API_URL || 'http://localhost:5000',
+      changeOrigin: true,
+      headers: env.VIGOVIA_API_KEY ? { 'X-API-Key': env.VIGOVIA_API_KEY } : {},
+      // false answers 404 instead of forwarding
+      bypass: (req: { url?: string; method?: string }) =>
+        forwarded.get(new URL(req.url ?? '', 'http://localhost').pathname) === req.method ? undefined : false,
+    },
+  };
+
+  return {
+    plugins: [react()],
+    optimizeDeps: {
+      exclude: ['lucide-react'],
+    },
+    server: { proxy: apiProxy },
+    preview: { proxy: apiProxy },
+  };
 });
@@ -0,0 +1,226 @@
+package auth
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+func testKeys() []Key {
+    return []Key{
+        {ID: "web", Hash: HashKey("web-secret"), Scopes: []Scope{ScopeGenerate}},
+        {ID: "reader", Hash: HashKey("read-secret"), Scopes: []Scope{ScopeRead}},
+        {ID: "ops", Hash: HashKey("admin-secret"), Scopes: []Scope{ScopeAdmin}},
+        {ID: "old", Hash: HashKey("old-secret"), Scopes: []Scope{ScopeGenerate}, Disabled: true},
+        {ID: "limited", Hash: HashKey("limited-secret"), Scopes: []Scope{ScopeGenerate}, RateLimit: 2},
+        {ID: "quota", Hash: HashKey("quota-secret"), Scopes: []Scope{ScopeGenerate}, MonthlyQuota: 3},
+    }
+}
+
+// newTestAuthenticator returns an authenticator whose clock reads *now
+func newTestAuthenticator(t *testing.T, keys []Key, usagePath string, now *time.Time) *Authenticator {
+    t.Helper()
+    a, err := NewAuthenticator(keys, usagePath)
+    if err != nil {
+        t.Fatal(err)
+    }
+    a.limiter.now = func() time.Time { return *now }
+    t.Cleanup(func() { a.Close() })
+    return a
+}
+
+// call sends a request through Require and returns the response and the ID
+// of the key the handler saw
+func call(a *Authenticator, scope Scope, method string, header http.Header) (*httptest.ResponseRecorder, string) {
+    seen := ""
+    handler := a.Require(scope, func(w http.ResponseWriter, r *http.Request) {
+        if key, ok := KeyFromContext(r.Context()); ok {
+            seen = key.ID
+        }
+        w.WriteHeader(http.StatusOK)
+    })
+    r := httptest.NewRequest(method, "/api/v1/generate-pdf", nil)
+    for name, values := range header {
+        r.Header.Set(name, values[0])
+    }
+    w := httptest.NewRecorder()
+    handler(w, r)
+    return w, seen
+}
+
+func withKey(raw string) http.Header {
+    return http.Header{HeaderAPIKey: {raw}}
+}
+
+func TestRequire(t *testing.T) {
+    now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+    a := newTestAuthenticator(t, testKeys(), "", &now)
+
+    tests := []struct {
+        name   string
+        scope  Scope
+        method string
+        header http.Header
+        status int
+        key    string
+    }{
+        {"no key", ScopeGenerate, "POST", nil, http.StatusUnauthorized, ""},
+        {"unknown key", ScopeGenerate, "POST", withKey("guess"), http.StatusUnauthorized, ""},
+        {"disabled key", ScopeGenerate, "POST", withKey("old-secret"), http.StatusUnauthorized, ""},
+        {"missing scope", ScopeGenerate, "POST", withKey("read-secret"), http.StatusForbidden, ""},
+        {"scope", ScopeGenerate, "POST", withKey("web-secret"), http.StatusOK, "web"},
+        {"bearer token", ScopeGenerate, "POST", http.Header{"Authorization": {"Bearer web-secret"}}, http.StatusOK, "web"},
+        {"admin has every scope", ScopeRead, "GET", withKey("admin-secret"), http.StatusOK, "ops"},
+        {"admin scope", ScopeAdmin, "GET", withKey("web-secret"), http.StatusForbidden, ""},
+        {"preflight", ScopeGenerate, "OPTIONS", nil, http.StatusOK, ""},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            w, key := call(a, tt.scope, tt.method, tt.header)
+            if w.Code != tt.status || key != tt.key {
+                t.Fatalf("status %d for key %q, want %d for %q: %s", w.Code, key, tt.status, tt.key, w.Body)
+            }
+            if w.Code == http.StatusUnauthorized && w.Header().Get("WWW-Authenticate") == "" {
+                t.Error("401 without WWW-Authenticate")
+            }
+        })
+    }
+}
+
+func TestRequireWithoutKeys(t *testing.T) {
+    now := time.Now()
+    a := newTestAuthenticator(t, nil, "", &now)
+    if w, _ := call(a, ScopeAdmin, "GET", nil); w.Code != http.StatusOK {
+        t.Errorf("status %d with authentication disabled", w.Code)
+    }
+}
+
+// expect sends one request with the key and checks its status, and for a
+// 429 the Retry-After header
+func expect(t *testing.T, a *Authenticator, raw string, status int, retryAfter string) {
+    t.Helper()
+    w, _ := call(a, ScopeGenerate, "POST", withKey(raw))
+    if w.Code != status {
+        t.Fatalf("status %d, want %d: %s", w.Code, status, w.Body)
+    }
+    if got := w.Header().Get("Retry-After"); got != retryAfter {
+        t.Fatalf("Retry-After %q, want %q", got, retryAfter)
+    }
+}
+
+func TestRateLimitRefill(t *testing.T) {
+    // Two requests a minute refill a token every 30 seconds
+    now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+    a := newTestAuthenticator(t, testKeys(), "", &now)
+
+    expect(t, a, "limited-secret", http.StatusOK, "")
+    expect(t, a, "limited-secret", http.StatusOK, "")
+    expect(t, a, "limited-secret", http.StatusTooManyRequests, "30")
+
+    now = now.Add(20 * time.Second)
+    expect(t, a, "limited-secret", http.StatusTooManyRequests, "10")
+    now = now.Add(10 * time.Second)
+    expect(t, a, "limited-secret", http.StatusOK, "")
+    expect(t, a, "limited-secret", http.StatusTooManyRequests, "30")
+
+    // A long pause refills the bucket to the limit, not beyond it
+    now = now.Add(time.Hour)
+    expect(t, a, "limited-secret", http.StatusOK, "")
+    expect(t, a, "limited-secret", http.StatusOK, "")
+    expect(t, a, "limited-secret", http.StatusTooManyRequests, "30")
+
+    // Other keys have their own buckets
+    expect(t, a, "web-secret", http.StatusOK, "")
+}
+
+func TestMonthlyQuotaRollover(t *testing.T) {
+    now := time.Date(2026, 10, 31, 23, 59, 0, 0, time.UTC)
+    a := newTestAuthenticator(t, testKeys(), "", &now)
+
+    for i := 0; i < 3; i++ {
+        expect(t, a, "quota-secret", http.StatusOK, "")
+    }
+    // Retry once the month turns, a minute away
+    expect(t, a, "quota-secret", http.StatusTooManyRequests, "60")
+
+    now = time.Date(2026, 11, 1, 0, 0, 0, 0, time.UTC)
+    expect(t, a, "quota-secret", http.StatusOK, "")
+    key := &a.keys[5]
+    if u := a.Usage(key); u.Month != "2026-11" || u.Used != 1 || u.MonthlyQuota != 3 {
+        t.Errorf("usage %+v, want 1 of 3 in 2026-11", u)
+    }
+}
+
+func TestUsagePersisted(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "usage.json")
+    now := time.Date(2026, 10, 19, 12, 0, 0, 0, time.UTC)
+
+    a, err := NewAuthenticator(testKeys(), path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    a.limiter.now = func() time.Time { return now }
+    for i := 0; i < 3; i++ {
+        expect(t, a, "quota-secret", http.StatusOK, "")
+    }
+    expect(t, a, "web-secret", http.StatusOK, "")
+    // Requests don't write the file; closing does
+    if _, err := os.Stat(path); !os.IsNotExist(err) {
+        t.Fatalf("usage written before close: %v", err)
+    }
+    if err := a.Close(); err != nil {
+        t.Fatal(err)
+    }
+
+    // After a restart the quota is still used up
+    b := newTestAuthenticator(t, testKeys(), path, &now)
+    expect(t, b, "quota-secret", http.StatusTooManyRequests, "1080000")
+    for _, u := range b.AllUsage() {
+        want := map[string]int{"quota": 3, "web": 1}[u.KeyID]
+        if u.Used != want || u.Month != "2026-10" {
+            t.Errorf("%s used %d in %s, want %d in 2026-10", u.KeyID, u.Used, u.Month, want)
+        }
+    }
+
+    // and starts again the next month
+    now = time.Date(2026, 11, 2, 0, 0, 0, 0, time.UTC)
+    expect(t, b, "quota-secret", http.StatusOK, "")
+}
+
+func TestUsageUnreadable(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "usage.json")
+    if err := os.WriteFile(path, []byte("{not json"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := NewAuthenticator(testKeys(), path); err == nil {
+        t.Error("no error for a corrupt usage file")
+    }
+}
+
+func TestValidateKeys(t *testing.T) {
+    hash := HashKey("secret")
+    tests := []struct {
+        name string
+        keys []Key
+    }{
+        {"no id", []Key{{Hash: hash, Scopes: []Scope{ScopeRead}}}},
+        {"duplicate id", []Key{{ID: "a", Hash: hash, Scopes: []Scope{ScopeRead}}, {ID: "a", Hash: hash, Scopes: []Scope{ScopeRead}}}},
+        {"raw key instead of a hash", []Key{{ID: "a", Hash: "secret", Scopes: []Scope{ScopeRead}}}},
+        {"no scopes", []Key{{ID: "a", Hash: hash}}},
+        {"unknown scope", []Key{{ID: "a", Hash: hash, Scopes: []Scope{"write"}}}},
+        {"negative limit", []Key{{ID: "a", Hash: hash, Scopes: []Scope{ScopeRead}, RateLimit: -1}}},
+    }
+    for _, tt := range tests {
+        if err := validateKeys(tt.keys); err == nil {
+            t.Errorf("%s: no error", tt.name)
+        }
+    }
+    upper := []Key{{ID: "a", Hash: " " + strings.ToUpper(HashKey("secret")) + " ", Scopes: []Scope{ScopeRead}}}
+    if err := validateKeys(upper); err != nil || matchKey(upper, "secret") == nil {
+        t.Errorf("padded upper case hash: %v", err)
+    }
+}
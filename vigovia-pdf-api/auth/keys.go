@@ -0,0 +1,116 @@
+// auth/keys.go
+package auth
+
+import (
+    "crypto/sha256"
+    "crypto/subtle"
+    "encoding/hex"
+    "encoding/json"
+    "fmt"
+    "os"
+    "strings"
+)
+
+type Scope string
+
+const (
+    ScopeGenerate Scope = "generate"
+    ScopeRead     Scope = "read"
+    ScopeAdmin    Scope = "admin"
+)
+
+// Key describes a single API key. Only the SHA-256 hash of the raw key is
+// ever stored; the raw value is handed to the client once and never persisted.
+type Key struct {
+    ID           string  `json:"id"`
+    Hash         string  `json:"hash"`
+    Scopes       []Scope `json:"scopes"`
+    RateLimit    int     `json:"rateLimit"`    // requests per minute, 0 = unlimited
+    MonthlyQuota int     `json:"monthlyQuota"` // requests per calendar month, 0 = unlimited
+    Disabled     bool    `json:"disabled"`
+}
+
+type keyFile struct {
+    Keys []Key `json:"keys"`
+}
+
+// HashKey returns the hex encoded SHA-256 hash of a raw API key
+func HashKey(raw string) string {
+    sum := sha256.Sum256([]byte(raw))
+    return hex.EncodeToString(sum[:])
+}
+
+// HasScope reports whether the key grants the given scope. Admin implies every scope.
+func (k *Key) HasScope(scope Scope) bool {
+    for _, s := range k.Scopes {
+        if s == scope || s == ScopeAdmin {
+            return true
+        }
+    }
+    return false
+}
+
+// LoadKeys reads API key definitions from a JSON file of the form
+// {"keys": [{"id": "...", "hash": "<sha256 hex>", "scopes": ["generate"]}]}
+func LoadKeys(path string) ([]Key, error) {
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return nil, fmt.Errorf("reading API keys: %w", err)
+    }
+
+    var file keyFile
+    if err := json.Unmarshal(raw, &file); err != nil {
+        return nil, fmt.Errorf("parsing API keys: %w", err)
+    }
+
+    if err := validateKeys(file.Keys); err != nil {
+        return nil, err
+    }
+    return file.Keys, nil
+}
+
+func validateKeys(keys []Key) error {
+    seen := make(map[string]bool)
+    for i := range keys {
+        key := &keys[i]
+        if key.ID == "" {
+            return fmt.Errorf("API key %d: id is required", i)
+        }
+        if seen[key.ID] {
+            return fmt.Errorf("API key %q: duplicate id", key.ID)
+        }
+        seen[key.ID] = true
+
+        key.Hash = strings.ToLower(strings.TrimSpace(key.Hash))
+        if decoded, err := hex.DecodeString(key.Hash); err != nil || len(decoded) != sha256.Size {
+            return fmt.Errorf("API key %q: hash must be a hex encoded SHA-256 digest", key.ID)
+        }
+        if len(key.Scopes) == 0 {
+            return fmt.Errorf("API key %q: at least one scope is required", key.ID)
+        }
+        for _, scope := range key.Scopes {
+            switch scope {
+            case ScopeGenerate, ScopeRead, ScopeAdmin:
+            default:
+                return fmt.Errorf("API key %q: unknown scope %q", key.ID, scope)
+            }
+        }
+        if key.RateLimit < 0 || key.MonthlyQuota < 0 {
+            return fmt.Errorf("API key %q: limits must not be negative", key.ID)
+        }
+    }
+    return nil
+}
+
+// matchKey finds the key whose hash matches the raw value. Every stored hash is
+// compared in constant time so lookups don't leak which prefix matched.
+func matchKey(keys []Key, raw string) *Key {
+    hash := []byte(HashKey(raw))
+    var found *Key
+    for i := range keys {
+        if subtle.ConstantTimeCompare(hash, []byte(keys[i].Hash)) == 1 {
+            found = &keys[i]
+        }
+    }
+    return found
+}
@@ -0,0 +1,238 @@
+// auth/limiter.go
+package auth
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "log/slog"
+    "math"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+)
+
+// saveInterval is how often changed monthly counts are written to the usage
+// file. Requests only mark them changed, so they never wait on the disk.
+const saveInterval = 5 * time.Second
+
+// usage tracks the per-key token bucket and the monthly request counter
+type usage struct {
+    tokens     float64
+    lastRefill time.Time
+    month      string
+    monthCount int
+}
+
+type Usage struct {
+    KeyID        string `json:"keyId"`
+    Month        string `json:"month"`
+    Used         int    `json:"used"`
+    MonthlyQuota int    `json:"monthlyQuota"`
+    RateLimit    int    `json:"rateLimit"`
+}
+
+// savedUsage is a key's monthly count as written to the usage file
+type savedUsage struct {
+    Month string `json:"month"`
+    Used  int    `json:"used"`
+}
+
+type limiter struct {
+    mu    sync.Mutex
+    usage map[string]*usage
+    now   func() time.Time
+    // path is where monthly counts are saved so quotas survive restarts;
+    // empty keeps them in memory only
+    path string
+    // dirty is set when a count changed after the last save
+    dirty bool
+
+    // saving serialises writes of the usage file, which happen outside mu
+    saving sync.Mutex
+    // stop ends the background saves; done is closed once they ended
+    stop      chan struct{}
+    done      chan struct{}
+    closeOnce sync.Once
+}
+
+// newLimiter loads the monthly counts saved at path, a missing file starting
+// every key at zero, and saves changed counts in the background until close
+func newLimiter(path string) (*limiter, error) {
+    l := &limiter{
+        usage: make(map[string]*usage),
+        now:   time.Now,
+        path:  path,
+    }
+    if path == "" {
+        return l, nil
+    }
+
+    raw, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return l, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("reading API key usage: %w", err)
+    }
+    var saved map[string]savedUsage
+    if err := json.Unmarshal(raw, &saved); err != nil {
+        return nil, fmt.Errorf("parsing API key usage %s: %w", path, err)
+    }
+    for id, s := range saved {
+        // The rate limit bucket starts full, as it does for a new key
+        l.usage[id] = &usage{tokens: math.Inf(1), lastRefill: l.now().UTC(), month: s.Month, monthCount: s.Used}
+    }
+
+    l.stop, l.done = make(chan struct{}), make(chan struct{})
+    go l.saveEvery(saveInterval)
+    return l, nil
+}
+
+// saveEvery writes changed counts every interval until close is called
+func (l *limiter) saveEvery(interval time.Duration) {
+    defer close(l.done)
+    ticker := time.NewTicker(interval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-ticker.C:
+            if err := l.save(); err != nil {
+                slog.Error("Failed to save API key usage", "error", err)
+            }
+        case <-l.stop:
+            return
+        }
+    }
+}
+
+// close stops the background saves and writes the counts they haven't
+func (l *limiter) close() error {
+    l.closeOnce.Do(func() {
+        if l.stop != nil {
+            close(l.stop)
+            <-l.done
+        }
+    })
+    return l.save()
+}
+
+// allow consumes one request for the key. When the request is rejected it
+// returns false and how long the caller should wait before retrying.
+func (l *limiter) allow(key *Key) (bool, time.Duration, string) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    now := l.now().UTC()
+    u := l.entry(key, now)
+
+    if key.MonthlyQuota > 0 && u.monthCount >= key.MonthlyQuota {
+        nextMonth := time.Date(now.Year(), now.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+        return false, nextMonth.Sub(now), "Monthly quota exceeded"
+    }
+
+    if key.RateLimit > 0 {
+        perSecond := float64(key.RateLimit) / 60
+        elapsed := now.Sub(u.lastRefill).Seconds()
+        u.tokens = math.Min(float64(key.RateLimit), u.tokens+elapsed*perSecond)
+        u.lastRefill = now
+        if u.tokens < 1 {
+            wait := time.Duration((1 - u.tokens) / perSecond * float64(time.Second))
+            return false, wait, "Rate limit exceeded"
+        }
+        u.tokens--
+    }
+
+    u.monthCount++
+    l.dirty = true
+    return true, 0, ""
+}
+
+// save writes every key's monthly count, when one changed since the last
+// save, to a temporary file and renames it over the old one so a crash never
+// leaves a half written file behind. The file is written without holding mu.
+func (l *limiter) save() error {
+    if l.path == "" {
+        return nil
+    }
+    l.saving.Lock()
+    defer l.saving.Unlock()
+
+    l.mu.Lock()
+    if !l.dirty {
+        l.mu.Unlock()
+        return nil
+    }
+    saved := make(map[string]savedUsage, len(l.usage))
+    for id, u := range l.usage {
+        saved[id] = savedUsage{Month: u.month, Used: u.monthCount}
+    }
+    l.dirty = false
+    l.mu.Unlock()
+
+    if err := writeUsage(l.path, saved); err != nil {
+        // Try again on the next save
+        l.mu.Lock()
+        l.dirty = true
+        l.mu.Unlock()
+        return err
+    }
+    return nil
+}
+
+func writeUsage(path string, saved map[string]savedUsage) error {
+    raw, err := json.MarshalIndent(saved, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), ".usage-*")
+    if err != nil {
+        return fmt.Errorf("saving API key usage: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(raw); err != nil {
+        tmp.Close()
+        return fmt.Errorf("saving API key usage: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("saving API key usage: %w", err)
+    }
+    if err := os.Rename(tmp.Name(), path); err != nil {
+        return fmt.Errorf("saving API key usage: %w", err)
+    }
+    return nil
+}
+
+func (l *limiter) entry(key *Key, now time.Time) *usage {
+    month := now.Format("2006-01")
+    u, ok := l.usage[key.ID]
+    if !ok {
+        u = &usage{
+            tokens:     float64(key.RateLimit),
+            lastRefill: now,
+            month:      month,
+        }
+        l.usage[key.ID] = u
+    }
+    if u.month != month {
+        u.month = month
+        u.monthCount = 0
+    }
+    return u
+}
+
+func (l *limiter) snapshot(key *Key) Usage {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    u := l.entry(key, l.now().UTC())
+    return Usage{
+        KeyID:        key.ID,
+        Month:        u.month,
+        Used:         u.monthCount,
+        MonthlyQuota: key.MonthlyQuota,
+        RateLimit:    key.RateLimit,
+    }
+}
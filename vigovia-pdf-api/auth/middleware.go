@@ -0,0 +1,130 @@
+// auth/middleware.go
+package auth
+
+import (
+    "context"
+    "encoding/json"
+    "fmt"
+    "math"
+    "net/http"
+    "strings"
+    "vigovia-pdf-api/logging"
+)
+
+const HeaderAPIKey = "X-API-Key"
+
+type contextKey struct{}
+
+// Authenticator guards handlers with API keys, scopes, rate limits and quotas
+type Authenticator struct {
+    keys    []Key
+    limiter *limiter
+}
+
+// NewAuthenticator builds an authenticator for the given keys, keeping their
+// monthly usage in usagePath, or in memory when it is empty. With no keys
+// configured authentication is disabled and every request is let through.
+func NewAuthenticator(keys []Key, usagePath string) (*Authenticator, error) {
+    if err := validateKeys(keys); err != nil {
+        return nil, err
+    }
+    l, err := newLimiter(usagePath)
+    if err != nil {
+        return nil, err
+    }
+    return &Authenticator{
+        keys:    keys,
+        limiter: l,
+    }, nil
+}
+
+// Close writes usage not saved yet; call it when the server stops
+func (a *Authenticator) Close() error {
+    return a.limiter.close()
+}
+
+func (a *Authenticator) Enabled() bool {
+    return len(a.keys) > 0
+}
+
+// Require wraps a handler so it only runs for requests carrying a valid key
+// with the given scope. CORS preflight requests are passed through untouched.
+func (a *Authenticator) Require(scope Scope, next http.HandlerFunc) http.HandlerFunc {
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == http.MethodOptions || !a.Enabled() {
+            next(w, r)
+            return
+        }
+
+        raw := extractKey(r)
+        if raw == "" {
+            w.Header().Set("WWW-Authenticate", `ApiKey header="`+HeaderAPIKey+`"`)
+            writeError(w, r, http.StatusUnauthorized, "Unauthorized", "API key is required")
+            return
+        }
+
+        key := matchKey(a.keys, raw)
+        if key == nil || key.Disabled {
+            w.Header().Set("WWW-Authenticate", `ApiKey header="`+HeaderAPIKey+`"`)
+            writeError(w, r, http.StatusUnauthorized, "Unauthorized", "API key is invalid")
+            return
+        }
+
+        if !key.HasScope(scope) {
+            writeError(w, r, http.StatusForbidden, "Forbidden", fmt.Sprintf("API key does not have the %q scope", scope))
+            return
+        }
+
+        allowed, retryAfter, reason := a.limiter.allow(key)
+        if !allowed {
+            logging.FromContext(r.Context()).Warn("API key limit reached", "key_id", key.ID, "reason", reason)
+            w.Header().Set("Retry-After", fmt.Sprintf("%d", int(math.Ceil(retryAfter.Seconds()))))
+            writeError(w, r, http.StatusTooManyRequests, "Too Many Requests", reason)
+            return
+        }
+
+        next(w, r.WithContext(context.WithValue(r.Context(), contextKey{}, key)))
+    }
+}
+
+// KeyFromContext returns the API key that authenticated the request, if any
+func KeyFromContext(ctx context.Context) (*Key, bool) {
+    key, ok := ctx.Value(contextKey{}).(*Key)
+    return key, ok
+}
+
+// Usage returns the current month's usage for the given key
+func (a *Authenticator) Usage(key *Key) Usage {
+    return a.limiter.snapshot(key)
+}
+
+// AllUsage returns the current month's usage for every configured key
+func (a *Authenticator) AllUsage() []Usage {
+    result := make([]Usage, 0, len(a.keys))
+    for i := range a.keys {
+        result = append(result, a.limiter.snapshot(&a.keys[i]))
+    }
+    return result
+}
+
+// Helper function to read the key from X-API-Key or an Authorization bearer token
+func extractKey(r *http.Request) string {
+    if key := strings.TrimSpace(r.Header.Get(HeaderAPIKey)); key != "" {
+        return key
+    }
+    authHeader := r.Header.Get("Authorization")
+    if len(authHeader) > 7 && strings.EqualFold(authHeader[:7], "Bearer ") {
+        return strings.TrimSpace(authHeader[7:])
+    }
+    return ""
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, status int, title, message string) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(map[string]string{
+        "error":     title,
+        "message":   message,
+        "requestId": logging.RequestIDFromContext(r.Context()),
+    })
+}
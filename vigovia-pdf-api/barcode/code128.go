@@ -0,0 +1,117 @@
+// barcode/code128.go
+package barcode
+
+import (
+    "errors"
+    "fmt"
+)
+
+// ErrUnencodable is returned for text a symbology can't hold
+var ErrUnencodable = errors.New("barcode: text can't be encoded")
+
+// QuietZone is the blank margin, in modules, a Code 128 symbol needs on
+// each side to scan
+const QuietZone = 10
+
+// code128Patterns are the bar and space widths of each Code 128 symbol value,
+// bar first; 106 is the stop pattern with its final bar
+var code128Patterns = [107]string{
+    "212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+    "221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+    "221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+    "212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+    "231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+    "231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+    "314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+    "112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+    "111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+    "214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+    "114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// Code 128 control values
+const (
+    codeC  = 99
+    codeB  = 100
+    startB = 104
+    startC = 105
+    stop   = 106
+)
+
+// Code128 encodes printable ASCII text as a Code 128 symbol and returns the
+// widths of its bars and spaces in modules, starting with a bar. Runs of
+// digits are packed two to a symbol in code set C.
+func Code128(text string) ([]int, error) {
+    if text == "" {
+        return nil, fmt.Errorf("%w: empty text", ErrUnencodable)
+    }
+    for _, r := range text {
+        if r < 32 || r > 126 {
+            return nil, fmt.Errorf("%w: %q is not printable ASCII", ErrUnencodable, r)
+        }
+    }
+
+    var values []int
+    set := 0
+    for i := 0; i < len(text); {
+        run := digitRun(text[i:])
+        // Switching to code set C pays off for four digits, or two that
+        // start or end the text
+        if run >= 4 || run >= 2 && (i+run == len(text) || i == 0 && run == len(text)) {
+            if run%2 == 1 {
+                values, set = appendB(values, set, text[i])
+                i++
+                run--
+            }
+            switch set {
+            case 0:
+                values = append(values, startC)
+            case startB:
+                values = append(values, codeC)
+            }
+            set = startC
+            for ; run > 0; run -= 2 {
+                values = append(values, int(text[i]-'0')*10+int(text[i+1]-'0'))
+                i += 2
+            }
+            continue
+        }
+        values, set = appendB(values, set, text[i])
+        i++
+    }
+
+    // The check symbol weights each value by its position, the start
+    // symbol counting once
+    sum := values[0]
+    for i, v := range values[1:] {
+        sum += (i + 1) * v
+    }
+    values = append(values, sum%103, stop)
+
+    var widths []int
+    for _, v := range values {
+        for _, w := range code128Patterns[v] {
+            widths = append(widths, int(w-'0'))
+        }
+    }
+    return widths, nil
+}
+
+// appendB adds a character in code set B, switching to it first if needed
+func appendB(values []int, set int, c byte) ([]int, int) {
+    switch set {
+    case 0:
+        values = append(values, startB)
+    case startC:
+        values = append(values, codeB)
+    }
+    return append(values, int(c)-32), startB
+}
+
+func digitRun(s string) int {
+    n := 0
+    for n < len(s) && s[n] >= '0' && s[n] <= '9' {
+        n++
+    }
+    return n
+}
@@ -0,0 +1,464 @@
+// barcode/qr.go
+package barcode
+
+import "fmt"
+
+// Level is how much of a QR code can be damaged and still read
+type Level int
+
+// Error correction levels, recovering about 7%, 15%, 25% and 30% of a code
+const (
+    LevelL Level = iota
+    LevelM
+    LevelQ
+    LevelH
+)
+
+// QRQuietZone is the blank margin, in modules, a QR code needs on each side
+const QRQuietZone = 4
+
+// QR is an encoded QR code symbol, Size modules square
+type QR struct {
+    Size    int
+    modules [][]bool
+    // function marks the finder, timing, alignment and format modules,
+    // which masks leave alone
+    function [][]bool
+}
+
+// Dark reports whether the module at column x, row y is dark
+func (q *QR) Dark(x, y int) bool {
+    return q.modules[y][x]
+}
+
+// QR capacity tables by level and version, from ISO/IEC 18004
+var (
+    eccPerBlock = [4][41]int{
+        {-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+        {-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+        {-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+        {-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+    }
+    eccBlocks = [4][41]int{
+        {-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+        {-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+        {-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+        {-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+    }
+    // formatLevel is each level's two bit code in the format information
+    formatLevel = [4]int{1, 0, 3, 2}
+)
+
+// EncodeQR encodes text, as UTF-8 bytes, in the smallest QR code that holds
+// it at the level, choosing the mask that reads best
+func EncodeQR(text string, level Level) (*QR, error) {
+    if level < LevelL || level > LevelH {
+        return nil, fmt.Errorf("barcode: unknown QR level %d", level)
+    }
+    data := []byte(text)
+    version := 0
+    for v := 1; v <= 40; v++ {
+        if 4+countBits(v)+8*len(data) <= 8*dataCodewords(v, level) {
+            version = v
+            break
+        }
+    }
+    if version == 0 {
+        return nil, fmt.Errorf("%w: %d bytes is too long for a QR code", ErrUnencodable, len(data))
+    }
+
+    // Byte mode: the mode, the length, the bytes, a terminator and padding
+    var bits bitBuffer
+    bits.append(0x4, 4)
+    bits.append(len(data), countBits(version))
+    for _, b := range data {
+        bits.append(int(b), 8)
+    }
+    capacity := 8 * dataCodewords(version, level)
+    bits.append(0, min(4, capacity-len(bits)))
+    bits.append(0, (8-len(bits)%8)%8)
+    for pad := 0xEC; len(bits) < capacity; pad ^= 0xEC ^ 0x11 {
+        bits.append(pad, 8)
+    }
+    codewords := make([]byte, len(bits)/8)
+    for i, bit := range bits {
+        if bit {
+            codewords[i/8] |= 1 << (7 - i%8)
+        }
+    }
+
+    q := newQR(version)
+    q.drawCodewords(addECC(codewords, version, level))
+
+    // Keep the mask with the lowest penalty
+    best, bestPenalty := 0, -1
+    for mask := 0; mask < 8; mask++ {
+        q.applyMask(mask)
+        q.drawFormat(level, mask)
+        if p := q.penalty(); bestPenalty < 0 || p < bestPenalty {
+            best, bestPenalty = mask, p
+        }
+        q.applyMask(mask)
+    }
+    q.applyMask(best)
+    q.drawFormat(level, best)
+    return q, nil
+}
+
+// countBits is the width of the byte count in byte mode
+func countBits(version int) int {
+    if version < 10 {
+        return 8
+    }
+    return 16
+}
+
+// rawModules is how many modules of a version hold data and error
+// correction, after the function patterns and format information
+func rawModules(version int) int {
+    n := (16*version+128)*version + 64
+    if version >= 2 {
+        align := version/7 + 2
+        n -= (25*align-10)*align - 55
+        if version >= 7 {
+            n -= 36
+        }
+    }
+    return n
+}
+
+func dataCodewords(version int, level Level) int {
+    return rawModules(version)/8 - eccPerBlock[level][version]*eccBlocks[level][version]
+}
+
+type bitBuffer []bool
+
+func (b *bitBuffer) append(value, n int) {
+    for i := n - 1; i >= 0; i-- {
+        *b = append(*b, (value>>i)&1 == 1)
+    }
+}
+
+// addECC splits the data into blocks, adds each block's Reed-Solomon error
+// correction and interleaves the blocks codeword by codeword
+func addECC(data []byte, version int, level Level) []byte {
+    blocks := eccBlocks[level][version]
+    eccLen := eccPerBlock[level][version]
+    raw := rawModules(version) / 8
+    shortBlocks := blocks - raw%blocks
+    shortLen := raw / blocks
+
+    divisor := rsDivisor(eccLen)
+    var all [][]byte
+    for i, k := 0, 0; i < blocks; i++ {
+        n := shortLen - eccLen
+        if i >= shortBlocks {
+            n++
+        }
+        block := append([]byte(nil), data[k:k+n]...)
+        k += n
+        ecc := rsRemainder(block, divisor)
+        if i < shortBlocks {
+            // Short blocks are padded so every block lines up
+            block = append(block, 0)
+        }
+        all = append(all, append(block, ecc...))
+    }
+
+    var out []byte
+    for i := range all[0] {
+        for j, block := range all {
+            // Skip the padding of short blocks
+            if i != shortLen-eccLen || j >= shortBlocks {
+                out = append(out, block[i])
+            }
+        }
+    }
+    return out
+}
+
+// rsDivisor is the Reed-Solomon generator polynomial of a degree, highest
+// coefficient first without the leading 1
+func rsDivisor(degree int) []byte {
+    result := make([]byte, degree)
+    result[degree-1] = 1
+    root := byte(1)
+    for i := 0; i < degree; i++ {
+        for j := range result {
+            result[j] = gfMul(result[j], root)
+            if j+1 < len(result) {
+                result[j] ^= result[j+1]
+            }
+        }
+        root = gfMul(root, 2)
+    }
+    return result
+}
+
+func rsRemainder(data, divisor []byte) []byte {
+    result := make([]byte, len(divisor))
+    for _, b := range data {
+        factor := b ^ result[0]
+        copy(result, result[1:])
+        result[len(result)-1] = 0
+        for i := range result {
+            result[i] ^= gfMul(divisor[i], factor)
+        }
+    }
+    return result
+}
+
+// gfMul multiplies in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+func gfMul(x, y byte) byte {
+    z := 0
+    for i := 7; i >= 0; i-- {
+        z = (z << 1) ^ ((z >> 7) * 0x11D)
+        z ^= int((y>>i)&1) * int(x)
+    }
+    return byte(z)
+}
+
+// newQR lays out an empty symbol with its finder, timing and alignment
+// patterns and the version information
+func newQR(version int) *QR {
+    size := version*4 + 17
+    q := &QR{Size: size, modules: make([][]bool, size), function: make([][]bool, size)}
+    for i := range q.modules {
+        q.modules[i] = make([]bool, size)
+        q.function[i] = make([]bool, size)
+    }
+
+    for i := 0; i < size; i++ {
+        q.set(6, i, i%2 == 0)
+        q.set(i, 6, i%2 == 0)
+    }
+    for _, c := range [][2]int{{3, 3}, {size - 4, 3}, {3, size - 4}} {
+        for dy := -4; dy <= 4; dy++ {
+            for dx := -4; dx <= 4; dx++ {
+                x, y := c[0]+dx, c[1]+dy
+                if x >= 0 && x < size && y >= 0 && y < size {
+                    d := max(abs(dx), abs(dy))
+                    q.set(x, y, d != 2 && d != 4)
+                }
+            }
+        }
+    }
+    align := alignmentPositions(version)
+    last := len(align) - 1
+    for i, ax := range align {
+        for j, ay := range align {
+            // The corners with finder patterns have none
+            if i == 0 && j == 0 || i == 0 && j == last || i == last && j == 0 {
+                continue
+            }
+            for dy := -2; dy <= 2; dy++ {
+                for dx := -2; dx <= 2; dx++ {
+                    q.set(ax+dx, ay+dy, max(abs(dx), abs(dy)) != 1)
+                }
+            }
+        }
+    }
+
+    // Reserve the format information until a mask is chosen
+    q.drawFormat(LevelL, 0)
+    if version >= 7 {
+        rem := version
+        for i := 0; i < 12; i++ {
+            rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+        }
+        bits := version<<12 | rem
+        for i := 0; i < 18; i++ {
+            dark := (bits>>i)&1 == 1
+            a, b := size-11+i%3, i/3
+            q.set(a, b, dark)
+            q.set(b, a, dark)
+        }
+    }
+    return q
+}
+
+func alignmentPositions(version int) []int {
+    if version == 1 {
+        return nil
+    }
+    n := version/7 + 2
+    step := 26
+    if version != 32 {
+        step = (version*4 + n*2 + 1) / (n*2 - 2) * 2
+    }
+    positions := make([]int, n)
+    positions[0] = 6
+    for i, pos := n-1, version*4+10; i >= 1; i, pos = i-1, pos-step {
+        positions[i] = pos
+    }
+    return positions
+}
+
+// set places a function module
+func (q *QR) set(x, y int, dark bool) {
+    q.modules[y][x] = dark
+    q.function[y][x] = true
+}
+
+// drawFormat writes the level and mask, with their error correction, in
+// both copies beside the finder patterns
+func (q *QR) drawFormat(level Level, mask int) {
+    data := formatLevel[level]<<3 | mask
+    rem := data
+    for i := 0; i < 10; i++ {
+        rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+    }
+    bits := (data<<10 | rem) ^ 0x5412
+    bit := func(i int) bool { return (bits>>i)&1 == 1 }
+
+    for i := 0; i <= 5; i++ {
+        q.set(8, i, bit(i))
+    }
+    q.set(8, 7, bit(6))
+    q.set(8, 8, bit(7))
+    q.set(7, 8, bit(8))
+    for i := 9; i < 15; i++ {
+        q.set(14-i, 8, bit(i))
+    }
+    for i := 0; i < 8; i++ {
+        q.set(q.Size-1-i, 8, bit(i))
+    }
+    for i := 8; i < 15; i++ {
+        q.set(8, q.Size-15+i, bit(i))
+    }
+    // The module that is always dark
+    q.set(8, q.Size-8, true)
+}
+
+// drawCodewords places the codewords in the zigzag of two module columns
+// from the bottom right, skipping function modules
+func (q *QR) drawCodewords(data []byte) {
+    i := 0
+    for right := q.Size - 1; right >= 1; right -= 2 {
+        if right == 6 {
+            right = 5
+        }
+        for vert := 0; vert < q.Size; vert++ {
+            for j := 0; j < 2; j++ {
+                x := right - j
+                y := vert
+                if (right+1)&2 == 0 {
+                    y = q.Size - 1 - vert
+                }
+                if !q.function[y][x] && i < len(data)*8 {
+                    q.modules[y][x] = (data[i/8]>>(7-i%8))&1 == 1
+                    i++
+                }
+            }
+        }
+    }
+}
+
+// applyMask flips the data modules the mask pattern selects; applying it
+// again undoes it
+func (q *QR) applyMask(mask int) {
+    for y := 0; y < q.Size; y++ {
+        for x := 0; x < q.Size; x++ {
+            var flip bool
+            switch mask {
+            case 0:
+                flip = (x+y)%2 == 0
+            case 1:
+                flip = y%2 == 0
+            case 2:
+                flip = x%3 == 0
+            case 3:
+                flip = (x+y)%3 == 0
+            case 4:
+                flip = (x/3+y/2)%2 == 0
+            case 5:
+                flip = x*y%2+x*y%3 == 0
+            case 6:
+                flip = (x*y%2+x*y%3)%2 == 0
+            case 7:
+                flip = ((x+y)%2+x*y%3)%2 == 0
+            }
+            if flip && !q.function[y][x] {
+                q.modules[y][x] = !q.modules[y][x]
+            }
+        }
+    }
+}
+
+// penalty scores how hard the symbol is to read: long runs, blocks of one
+// colour, patterns that look like finders and an uneven balance of dark
+// and light modules
+func (q *QR) penalty() int {
+    n := q.Size
+    score := 0
+    finderLike := []bool{true, false, true, true, true, false, true}
+    for _, vertical := range []bool{false, true} {
+        at := func(line, i int) bool {
+            if vertical {
+                return q.modules[i][line]
+            }
+            return q.modules[line][i]
+        }
+        for line := 0; line < n; line++ {
+            run := 1
+            for i := 1; i <= n; i++ {
+                if i < n && at(line, i) == at(line, i-1) {
+                    run++
+                    continue
+                }
+                if run >= 5 {
+                    score += 3 + run - 5
+                }
+                run = 1
+            }
+            for i := 0; i+7 <= n; i++ {
+                matches := true
+                for k, dark := range finderLike {
+                    if at(line, i+k) != dark {
+                        matches = false
+                        break
+                    }
+                }
+                if matches && (lightRun(at, line, i-4, i, n) || lightRun(at, line, i+7, i+11, n)) {
+                    score += 40
+                }
+            }
+        }
+    }
+
+    dark := 0
+    for y := 0; y < n; y++ {
+        for x := 0; x < n; x++ {
+            if q.modules[y][x] {
+                dark++
+            }
+            if x+1 < n && y+1 < n {
+                c := q.modules[y][x]
+                if q.modules[y][x+1] == c && q.modules[y+1][x] == c && q.modules[y+1][x+1] == c {
+                    score += 3
+                }
+            }
+        }
+    }
+    total := n * n
+    k := (abs(dark*20-total*10)+total-1)/total - 1
+    return score + k*10
+}
+
+// lightRun reports whether modules from to to of a line are light, counting
+// the quiet zone beyond the symbol as light
+func lightRun(at func(line, i int) bool, line, from, to, n int) bool {
+    for i := from; i < to; i++ {
+        if i >= 0 && i < n && at(line, i) {
+            return false
+        }
+    }
+    return true
+}
+
+func abs(n int) int {
+    if n < 0 {
+        return -n
+    }
+    return n
+}
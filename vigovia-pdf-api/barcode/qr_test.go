@@ -0,0 +1,395 @@
+package barcode
+
+import (
+    "strconv"
+    "strings"
+    "testing"
+)
+
+// alpha returns α^n in GF(2^8)
+func alpha(n int) byte {
+    x := byte(1)
+    for i := 0; i < n; i++ {
+        x = gfMul(x, 2)
+    }
+    return x
+}
+
+func TestGFMul(t *testing.T) {
+    // Powers of α from the QR log table
+    for n, want := range map[int]byte{0: 1, 7: 128, 8: 29, 9: 58, 12: 205, 25: 3, 255: 1} {
+        if got := alpha(n); got != want {
+            t.Errorf("α^%d = %d, want %d", n, got, want)
+        }
+    }
+    // α is primitive: it doesn't come back to 1 before α^255
+    for n := 1; n < 255; n++ {
+        if alpha(n) == 1 {
+            t.Fatalf("α^%d = 1", n)
+        }
+    }
+}
+
+func TestRSDivisor(t *testing.T) {
+    // The degree 10 generator polynomial, as powers of α
+    want := []int{251, 67, 46, 61, 118, 70, 64, 94, 32, 45}
+    got := rsDivisor(10)
+    for i, n := range want {
+        if got[i] != alpha(n) {
+            t.Errorf("coefficient %d = %d, want α^%d = %d", i, got[i], n, alpha(n))
+        }
+    }
+}
+
+func TestRSRemainder(t *testing.T) {
+    // HELLO WORLD at 1-M, the worked example of thonky.com's QR tutorial
+    data := []byte{32, 91, 11, 120, 209, 114, 220, 77, 67, 64, 236, 17, 236, 17, 236, 17}
+    want := []byte{196, 35, 39, 119, 235, 215, 231, 226, 93, 23}
+    if got := rsRemainder(data, rsDivisor(10)); string(got) != string(want) {
+        t.Errorf("error correction = %v, want %v", got, want)
+    }
+}
+
+func TestDataCodewords(t *testing.T) {
+    // From the capacity table of ISO/IEC 18004
+    tests := []struct {
+        version int
+        level   Level
+        want    int
+    }{
+        {1, LevelL, 19}, {1, LevelM, 16}, {1, LevelQ, 13}, {1, LevelH, 9},
+        {2, LevelH, 16},
+        {7, LevelM, 124},
+        {10, LevelM, 216},
+        {40, LevelL, 2956}, {40, LevelH, 1276},
+    }
+    for _, tt := range tests {
+        if got := dataCodewords(tt.version, tt.level); got != tt.want {
+            t.Errorf("dataCodewords(%d, %d) = %d, want %d", tt.version, tt.level, got, tt.want)
+        }
+    }
+}
+
+func TestAlignmentPositions(t *testing.T) {
+    // From Annex E of ISO/IEC 18004
+    tests := map[int][]int{
+        1:  nil,
+        2:  {6, 18},
+        7:  {6, 22, 38},
+        10: {6, 28, 50},
+        14: {6, 26, 46, 66},
+        22: {6, 26, 50, 74, 98},
+        32: {6, 34, 60, 86, 112, 138},
+        36: {6, 24, 50, 76, 102, 128, 154},
+        40: {6, 30, 58, 86, 114, 142, 170},
+    }
+    for version, want := range tests {
+        got := alignmentPositions(version)
+        if len(got) != len(want) {
+            t.Errorf("version %d: %v, want %v", version, got, want)
+            continue
+        }
+        for i := range want {
+            if got[i] != want[i] {
+                t.Errorf("version %d: %v, want %v", version, got, want)
+                break
+            }
+        }
+    }
+}
+
+// readFormat reads both copies of the format information, most significant
+// bit first
+func readFormat(q *QR) (first, second string) {
+    var a, b [15]bool
+    for i := 0; i <= 5; i++ {
+        a[i] = q.Dark(8, i)
+    }
+    a[6], a[7], a[8] = q.Dark(8, 7), q.Dark(8, 8), q.Dark(7, 8)
+    for i := 9; i < 15; i++ {
+        a[i] = q.Dark(14-i, 8)
+    }
+    for i := 0; i < 8; i++ {
+        b[i] = q.Dark(q.Size-1-i, 8)
+    }
+    for i := 8; i < 15; i++ {
+        b[i] = q.Dark(8, q.Size-15+i)
+    }
+    return bitString(a[:]), bitString(b[:])
+}
+
+// bitString writes bits with the highest index first
+func bitString(bits []bool) string {
+    var s strings.Builder
+    for i := len(bits) - 1; i >= 0; i-- {
+        if bits[i] {
+            s.WriteByte('1')
+        } else {
+            s.WriteByte('0')
+        }
+    }
+    return s.String()
+}
+
+func TestFormatInformation(t *testing.T) {
+    // From the format information table of ISO/IEC 18004
+    tests := []struct {
+        level Level
+        mask  int
+        want  string
+    }{
+        {LevelL, 0, "111011111000100"}, {LevelL, 1, "111001011110011"},
+        {LevelL, 2, "111110110101010"}, {LevelL, 3, "111100010011101"},
+        {LevelL, 4, "110011000101111"}, {LevelL, 5, "110001100011000"},
+        {LevelL, 6, "110110001000001"}, {LevelL, 7, "110100101110110"},
+        {LevelM, 0, "101010000010010"}, {LevelM, 1, "101000100100101"},
+        {LevelM, 2, "101111001111100"}, {LevelM, 3, "101101101001011"},
+        {LevelM, 4, "100010111111001"}, {LevelM, 5, "100000011001110"},
+        {LevelM, 6, "100111110010111"}, {LevelM, 7, "100101010100000"},
+        {LevelQ, 0, "011010101011111"},
+        {LevelH, 0, "001011010001001"},
+    }
+    q := newQR(1)
+    for _, tt := range tests {
+        q.drawFormat(tt.level, tt.mask)
+        first, second := readFormat(q)
+        if first != tt.want || second != tt.want {
+            t.Errorf("level %d mask %d: %s and %s, want %s", tt.level, tt.mask, first, second, tt.want)
+        }
+    }
+}
+
+func TestVersionInformation(t *testing.T) {
+    // From the version information table of ISO/IEC 18004
+    tests := map[int]string{
+        7:  "000111110010010100",
+        8:  "001000010110111100",
+        10: "001010010011010011",
+        40: "101000110001101001",
+    }
+    for version, want := range tests {
+        q := newQR(version)
+        var below, beside [18]bool
+        for i := 0; i < 18; i++ {
+            below[i] = q.Dark(q.Size-11+i%3, i/3)
+            beside[i] = q.Dark(i/3, q.Size-11+i%3)
+        }
+        if got := bitString(below[:]); got != want {
+            t.Errorf("version %d, top right: %s, want %s", version, got, want)
+        }
+        if got := bitString(beside[:]); got != want {
+            t.Errorf("version %d, bottom left: %s, want %s", version, got, want)
+        }
+    }
+}
+
+// masks are the mask patterns of ISO/IEC 18004 for row i and column j
+var masks = [8]func(i, j int) bool{
+    func(i, j int) bool { return (i+j)%2 == 0 },
+    func(i, j int) bool { return i%2 == 0 },
+    func(i, j int) bool { return j%3 == 0 },
+    func(i, j int) bool { return (i+j)%3 == 0 },
+    func(i, j int) bool { return (i/2+j/3)%2 == 0 },
+    func(i, j int) bool { return i*j%2+i*j%3 == 0 },
+    func(i, j int) bool { return (i*j%2+i*j%3)%2 == 0 },
+    func(i, j int) bool { return ((i+j)%2+i*j%3)%2 == 0 },
+}
+
+// decode reads a symbol back the way a scanner would: it checks the fixed
+// patterns, reads the level and mask from the format information, unmasks
+// the data, takes the codewords out of the zigzag, checks each block's error
+// correction and returns the byte mode text
+func decode(t *testing.T, q *QR) (string, Level) {
+    t.Helper()
+    size := q.Size
+    version := (size - 17) / 4
+
+    // Finder patterns and their separators
+    for _, corner := range [][2]int{{0, 0}, {size - 7, 0}, {0, size - 7}} {
+        for dy := -1; dy <= 7; dy++ {
+            for dx := -1; dx <= 7; dx++ {
+                x, y := corner[0]+dx, corner[1]+dy
+                if x < 0 || x >= size || y < 0 || y >= size {
+                    continue
+                }
+                ring := min(dx, dy, 6-dx, 6-dy)
+                if want := ring == 0 || ring >= 2; q.Dark(x, y) != want {
+                    t.Fatalf("finder module (%d, %d) dark = %t", x, y, !want)
+                }
+            }
+        }
+    }
+    for i := 8; i < size-8; i++ {
+        if q.Dark(i, 6) != (i%2 == 0) || q.Dark(6, i) != (i%2 == 0) {
+            t.Fatalf("timing pattern broken at %d", i)
+        }
+    }
+    if !q.Dark(8, size-8) {
+        t.Fatal("the dark module is light")
+    }
+
+    function := make([][]bool, size)
+    for y := range function {
+        function[y] = make([]bool, size)
+        for x := range function[y] {
+            function[y][x] = x < 9 && y < 9 || x >= size-8 && y < 9 || x < 9 && y >= size-8 ||
+                x == 6 || y == 6 ||
+                version >= 7 && (x >= size-11 && x < size-8 && y < 6 || y >= size-11 && y < size-8 && x < 6)
+        }
+    }
+    align := alignmentPositions(version)
+    for i, ax := range align {
+        for j, ay := range align {
+            if i == 0 && j == 0 || i == 0 && j == len(align)-1 || i == len(align)-1 && j == 0 {
+                continue
+            }
+            for dy := -2; dy <= 2; dy++ {
+                for dx := -2; dx <= 2; dx++ {
+                    function[ay+dy][ax+dx] = true
+                    if q.Dark(ax+dx, ay+dy) != (max(abs(dx), abs(dy)) != 1) {
+                        t.Fatalf("alignment pattern at (%d, %d) broken", ax, ay)
+                    }
+                }
+            }
+        }
+    }
+
+    first, second := readFormat(q)
+    if first != second {
+        t.Fatalf("format copies differ: %s and %s", first, second)
+    }
+    word, _ := strconv.ParseInt(first, 2, 32)
+    word ^= 0x5412
+    level := map[int64]Level{1: LevelL, 0: LevelM, 3: LevelQ, 2: LevelH}[word>>13]
+    mask := masks[word>>10&7]
+
+    var bits []bool
+    up := true
+    for right := size - 1; right > 0; right -= 2 {
+        if right == 6 {
+            right--
+        }
+        for k := 0; k < size; k++ {
+            y := k
+            if up {
+                y = size - 1 - k
+            }
+            for _, x := range []int{right, right - 1} {
+                if !function[y][x] {
+                    bits = append(bits, q.Dark(x, y) != mask(y, x))
+                }
+            }
+        }
+        up = !up
+    }
+    codewords := make([]byte, len(bits)/8)
+    for i := range codewords {
+        for _, bit := range bits[i*8 : i*8+8] {
+            codewords[i] <<= 1
+            if bit {
+                codewords[i] |= 1
+            }
+        }
+    }
+
+    // Undo the interleaving: data codewords first, then error correction
+    blocks, eccLen := eccBlocks[level][version], eccPerBlock[level][version]
+    dataLen := len(codewords) - blocks*eccLen
+    data := make([][]byte, blocks)
+    ecc := make([][]byte, blocks)
+    k := 0
+    for i := 0; i <= dataLen/blocks; i++ {
+        for b := range data {
+            // The longer blocks come last
+            if i < dataLen/blocks || b >= blocks-dataLen%blocks {
+                data[b] = append(data[b], codewords[k])
+                k++
+            }
+        }
+    }
+    for i := 0; i < eccLen; i++ {
+        for b := range ecc {
+            ecc[b] = append(ecc[b], codewords[k])
+            k++
+        }
+    }
+
+    // A valid block is a multiple of the generator, so it vanishes at the
+    // generator's roots α^0 to α^(eccLen-1)
+    var stream []byte
+    for b := range data {
+        block := append(append([]byte(nil), data[b]...), ecc[b]...)
+        for i := 0; i < eccLen; i++ {
+            var s byte
+            for _, c := range block {
+                s = gfMul(s, alpha(i)) ^ c
+            }
+            if s != 0 {
+                t.Fatalf("block %d fails its error correction check at α^%d", b, i)
+            }
+        }
+        stream = append(stream, data[b]...)
+    }
+
+    pos := 0
+    read := func(n int) int {
+        v := 0
+        for i := 0; i < n; i++ {
+            v = v<<1 | int(stream[pos/8]>>(7-pos%8)&1)
+            pos++
+        }
+        return v
+    }
+    if mode := read(4); mode != 0x4 {
+        t.Fatalf("mode %04b, want byte mode", mode)
+    }
+    n := read(countBits(version))
+    text := make([]byte, n)
+    for i := range text {
+        text[i] = byte(read(8))
+    }
+    return string(text), level
+}
+
+func TestEncodeQR(t *testing.T) {
+    url := "https://vigovia.com/itinerary/"
+    tests := []struct {
+        name    string
+        text    string
+        level   Level
+        version int
+    }{
+        {"hello world", "HELLO WORLD", LevelM, 1},
+        {"fills version 1-L", strings.Repeat("a", 17), LevelL, 1},
+        {"one byte over version 1-L", strings.Repeat("a", 18), LevelL, 2},
+        {"high level", "HELLO WORLD", LevelH, 2},
+        {"utf-8", "Café ✈ दिल्ली", LevelQ, 3},
+        {"version information", url + strings.Repeat("x", 80), LevelM, 7},
+        {"long count and uneven blocks", url + strings.Repeat("y", 170), LevelM, 10},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            q, err := EncodeQR(tt.text, tt.level)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if want := tt.version*4 + 17; q.Size != want {
+                t.Fatalf("size %d, want %d (version %d)", q.Size, want, tt.version)
+            }
+            text, level := decode(t, q)
+            if text != tt.text || level != tt.level {
+                t.Errorf("decoded %q at level %d, want %q at level %d", text, level, tt.text, tt.level)
+            }
+        })
+    }
+}
+
+func TestEncodeQRTooLong(t *testing.T) {
+    // Version 40-L holds 2953 bytes
+    if _, err := EncodeQR(strings.Repeat("a", 2953), LevelL); err != nil {
+        t.Errorf("2953 bytes: %v", err)
+    }
+    if _, err := EncodeQR(strings.Repeat("a", 2954), LevelL); err == nil {
+        t.Error("2954 bytes: no error")
+    }
+}
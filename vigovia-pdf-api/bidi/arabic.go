@@ -0,0 +1,190 @@
+// bidi/arabic.go
+package bidi
+
+// Arabic letters change shape with their neighbours. PDF text isn't shaped
+// by the viewer, so each letter is replaced with its isolated, final,
+// initial or medial presentation form before the line is drawn.
+
+// joining is how a letter connects to its neighbours
+type joining int
+
+const (
+    joinNone  joining = iota // doesn't join, e.g. hamza or a space
+    joinRight                // joins only the letter before it, e.g. alef
+    joinDual                 // joins on both sides, e.g. beh
+    joinCause                // tatweel, which joins but has no forms
+)
+
+// forms are a letter's isolated, final, initial and medial presentation
+// forms; right-joining letters only have the first two
+type forms [4]rune
+
+const (
+    isolated = iota
+    final
+    initial
+    medial
+)
+
+var arabicForms = map[rune]forms{
+    0x0621: {0xFE80},
+    0x0622: {0xFE81, 0xFE82},
+    0x0623: {0xFE83, 0xFE84},
+    0x0624: {0xFE85, 0xFE86},
+    0x0625: {0xFE87, 0xFE88},
+    0x0626: {0xFE89, 0xFE8A, 0xFE8B, 0xFE8C},
+    0x0627: {0xFE8D, 0xFE8E},
+    0x0628: {0xFE8F, 0xFE90, 0xFE91, 0xFE92},
+    0x0629: {0xFE93, 0xFE94},
+    0x062A: {0xFE95, 0xFE96, 0xFE97, 0xFE98},
+    0x062B: {0xFE99, 0xFE9A, 0xFE9B, 0xFE9C},
+    0x062C: {0xFE9D, 0xFE9E, 0xFE9F, 0xFEA0},
+    0x062D: {0xFEA1, 0xFEA2, 0xFEA3, 0xFEA4},
+    0x062E: {0xFEA5, 0xFEA6, 0xFEA7, 0xFEA8},
+    0x062F: {0xFEA9, 0xFEAA},
+    0x0630: {0xFEAB, 0xFEAC},
+    0x0631: {0xFEAD, 0xFEAE},
+    0x0632: {0xFEAF, 0xFEB0},
+    0x0633: {0xFEB1, 0xFEB2, 0xFEB3, 0xFEB4},
+    0x0634: {0xFEB5, 0xFEB6, 0xFEB7, 0xFEB8},
+    0x0635: {0xFEB9, 0xFEBA, 0xFEBB, 0xFEBC},
+    0x0636: {0xFEBD, 0xFEBE, 0xFEBF, 0xFEC0},
+    0x0637: {0xFEC1, 0xFEC2, 0xFEC3, 0xFEC4},
+    0x0638: {0xFEC5, 0xFEC6, 0xFEC7, 0xFEC8},
+    0x0639: {0xFEC9, 0xFECA, 0xFECB, 0xFECC},
+    0x063A: {0xFECD, 0xFECE, 0xFECF, 0xFED0},
+    0x0641: {0xFED1, 0xFED2, 0xFED3, 0xFED4},
+    0x0642: {0xFED5, 0xFED6, 0xFED7, 0xFED8},
+    0x0643: {0xFED9, 0xFEDA, 0xFEDB, 0xFEDC},
+    0x0644: {0xFEDD, 0xFEDE, 0xFEDF, 0xFEE0},
+    0x0645: {0xFEE1, 0xFEE2, 0xFEE3, 0xFEE4},
+    0x0646: {0xFEE5, 0xFEE6, 0xFEE7, 0xFEE8},
+    0x0647: {0xFEE9, 0xFEEA, 0xFEEB, 0xFEEC},
+    0x0648: {0xFEED, 0xFEEE},
+    0x0649: {0xFEEF, 0xFEF0},
+    0x064A: {0xFEF1, 0xFEF2, 0xFEF3, 0xFEF4},
+    // Persian and Urdu letters
+    0x067E: {0xFB56, 0xFB57, 0xFB58, 0xFB59},
+    0x0686: {0xFB7A, 0xFB7B, 0xFB7C, 0xFB7D},
+    0x0698: {0xFB8A, 0xFB8B},
+    0x06A9: {0xFB8E, 0xFB8F, 0xFB90, 0xFB91},
+    0x06AF: {0xFB92, 0xFB93, 0xFB94, 0xFB95},
+    0x06CC: {0xFBFC, 0xFBFD, 0xFBFE, 0xFBFF},
+}
+
+// lamAlef are the isolated and final ligatures of lam followed by an alef
+var lamAlef = map[rune][2]rune{
+    0x0622: {0xFEF5, 0xFEF6},
+    0x0623: {0xFEF7, 0xFEF8},
+    0x0625: {0xFEF9, 0xFEFA},
+    0x0627: {0xFEFB, 0xFEFC},
+}
+
+const (
+    lam     = 0x0644
+    tatweel = 0x0640
+)
+
+func joiningOf(r rune) joining {
+    if r == tatweel {
+        return joinCause
+    }
+    f, ok := arabicForms[r]
+    switch {
+    case !ok:
+        return joinNone
+    case f[initial] != 0:
+        return joinDual
+    case f[final] != 0:
+        return joinRight
+    default:
+        return joinNone
+    }
+}
+
+// transparent reports whether r is a vowel mark, which letters join across
+func transparent(r rune) bool {
+    return r >= 0x064B && r <= 0x065F || r == 0x0670
+}
+
+// Shape replaces Arabic letters with their contextual presentation forms and
+// joins lam-alef pairs into their ligature, and moves Devanagari's short i
+// sign before its consonants. Text in logical order goes in and comes out;
+// other scripts are left untouched.
+func Shape(s string) string {
+    runes := []rune(s)
+    reordered := reorderDevanagari(runes)
+    shaped := false
+    for _, r := range runes {
+        if _, ok := arabicForms[r]; ok {
+            shaped = true
+            break
+        }
+    }
+    if !shaped {
+        if reordered {
+            return string(runes)
+        }
+        return s
+    }
+
+    // neighbour finds the joining of the nearest letter in direction step,
+    // skipping vowel marks
+    neighbour := func(i, step int) (int, joining) {
+        for j := i + step; j >= 0 && j < len(runes); j += step {
+            if !transparent(runes[j]) {
+                return j, joiningOf(runes[j])
+            }
+        }
+        return -1, joinNone
+    }
+
+    out := make([]rune, 0, len(runes))
+    // prevJoins records whether the last letter written joins to the next one
+    prevJoins := false
+    for i := 0; i < len(runes); i++ {
+        r := runes[i]
+        if transparent(r) {
+            out = append(out, r)
+            continue
+        }
+        f, ok := arabicForms[r]
+        if !ok {
+            out = append(out, r)
+            prevJoins = r == tatweel
+            continue
+        }
+
+        if r == lam {
+            if j, _ := neighbour(i, 1); j >= 0 {
+                if lig, ok := lamAlef[runes[j]]; ok {
+                    form := lig[0]
+                    if prevJoins {
+                        form = lig[1]
+                    }
+                    out = append(out, form)
+                    out = append(out, runes[i+1:j]...)
+                    i = j
+                    // Alef never joins the letter after it
+                    prevJoins = false
+                    continue
+                }
+            }
+        }
+
+        _, next := neighbour(i, 1)
+        joinsNext := joiningOf(r) == joinDual && (next == joinDual || next == joinRight || next == joinCause)
+        switch {
+        case prevJoins && joinsNext:
+            out = append(out, f[medial])
+        case prevJoins && f[final] != 0:
+            out = append(out, f[final])
+        case joinsNext:
+            out = append(out, f[initial])
+        default:
+            out = append(out, f[isolated])
+        }
+        prevJoins = joinsNext
+    }
+    return string(out)
+}
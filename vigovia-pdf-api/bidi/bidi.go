@@ -0,0 +1,381 @@
+// bidi/bidi.go
+package bidi
+
+import "unicode"
+
+// class is a simplified Unicode bidirectional character type
+type class int
+
+const (
+    classL   class = iota // left-to-right letters
+    classR                // Hebrew and other right-to-left letters
+    classAL               // Arabic letters
+    classEN               // European digits
+    classAN               // Arabic-Indic digits
+    classES               // plus and minus between numbers
+    classET               // currency, percent and similar number suffixes
+    classCS               // separators inside numbers such as , . : /
+    classNSM              // combining marks
+    classWS               // whitespace
+    classON               // other neutrals
+)
+
+// Visual returns a single line of text in display order, following the
+// Unicode Bidirectional Algorithm (UAX #9) without explicit embeddings, which
+// itinerary text doesn't use. rtl sets the paragraph direction. Arabic text
+// should be shaped before it is reordered.
+func Visual(s string, rtl bool) string {
+    runes := []rune(s)
+    if !rtl && !hasRTL(runes) {
+        return s
+    }
+    para := 0
+    if rtl {
+        para = 1
+    }
+    classes := resolveWeak(runes, para)
+    resolveBrackets(runes, classes, para)
+    resolveNeutral(classes, para)
+    levels := resolveLevels(runes, classes, para)
+    return string(reorder(runes, levels))
+}
+
+func hasRTL(runes []rune) bool {
+    for _, r := range runes {
+        if c := classify(r); c == classR || c == classAL || c == classAN {
+            return true
+        }
+    }
+    return false
+}
+
+func classify(r rune) class {
+    switch {
+    case r >= '0' && r <= '9', r >= 0x06F0 && r <= 0x06F9:
+        return classEN
+    case r >= 0x0660 && r <= 0x0669, r == 0x066B, r == 0x066C:
+        return classAN
+    case r == '+' || r == '-' || r == 0x2212:
+        return classES
+    case r == '#' || r == '%' || r == 0x00B0 || r == 0x2030 || r == 0x066A || unicode.Is(unicode.Sc, r):
+        return classET
+    case r == ',' || r == '.' || r == '/' || r == ':' || r == 0x00A0 || r == 0x060C:
+        return classCS
+    case r == 0x200E:
+        return classL
+    case r == 0x200F:
+        return classR
+    case r == 0x061C:
+        return classAL
+    case unicode.Is(unicode.Mn, r):
+        return classNSM
+    case unicode.IsSpace(r):
+        return classWS
+    case r >= 0x0590 && r <= 0x05FF, r >= 0x07C0 && r <= 0x085F, r >= 0xFB1D && r <= 0xFB4F:
+        return classR
+    case r >= 0x0600 && r <= 0x07BF, r >= 0x0860 && r <= 0x08FF, r >= 0xFB50 && r <= 0xFDFF, r >= 0xFE70 && r <= 0xFEFF:
+        if unicode.IsLetter(r) {
+            return classAL
+        }
+        return classON
+    case unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.IsMark(r):
+        return classL
+    default:
+        return classON
+    }
+}
+
+// resolveWeak applies rules W1 to W7 to the characters' classes
+func resolveWeak(runes []rune, para int) []class {
+    sos := classL
+    if para == 1 {
+        sos = classR
+    }
+    classes := make([]class, len(runes))
+    for i, r := range runes {
+        classes[i] = classify(r)
+    }
+
+    // W1: marks take the class of the character they combine with
+    prev := sos
+    for i, c := range classes {
+        if c == classNSM {
+            classes[i] = prev
+        }
+        prev = classes[i]
+    }
+    // W2 and W3: numbers after Arabic letters are Arabic numbers, and
+    // Arabic letters are then ordinary right-to-left letters
+    strong := sos
+    for i, c := range classes {
+        switch c {
+        case classL, classR, classAL:
+            strong = c
+        case classEN:
+            if strong == classAL {
+                classes[i] = classAN
+            }
+        }
+    }
+    for i, c := range classes {
+        if c == classAL {
+            classes[i] = classR
+        }
+    }
+    // W4: one separator between two numbers of the same kind joins them
+    for i := 1; i+1 < len(classes); i++ {
+        before, after := classes[i-1], classes[i+1]
+        switch {
+        case classes[i] == classES && before == classEN && after == classEN:
+            classes[i] = classEN
+        case classes[i] == classCS && before == after && (before == classEN || before == classAN):
+            classes[i] = before
+        }
+    }
+    // W5: currency and percent signs next to a number belong to it. Unlike
+    // UAX #9 this includes Arabic numbers, so "$1,200" in Arabic text keeps
+    // its sign in front.
+    for i := 0; i < len(classes); {
+        if classes[i] != classET {
+            i++
+            continue
+        }
+        end := i
+        for end < len(classes) && classes[end] == classET {
+            end++
+        }
+        number := classON
+        if end < len(classes) && (classes[end] == classEN || classes[end] == classAN) {
+            number = classes[end]
+        }
+        if i > 0 && (classes[i-1] == classEN || classes[i-1] == classAN) {
+            number = classes[i-1]
+        }
+        if number != classON {
+            for j := i; j < end; j++ {
+                classes[j] = number
+            }
+        }
+        i = end
+    }
+    // W6: any separators left are neutral
+    for i, c := range classes {
+        if c == classES || c == classET || c == classCS {
+            classes[i] = classON
+        }
+    }
+    // W7: numbers in left-to-right text are left-to-right
+    strong = sos
+    for i, c := range classes {
+        switch c {
+        case classL, classR:
+            strong = c
+        case classEN:
+            if strong == classL {
+                classes[i] = classL
+            }
+        }
+    }
+    return classes
+}
+
+// resolveBrackets applies rule N0: a pair of brackets takes the direction of
+// the text it encloses, so "(5)" after a Hebrew name stays together
+func resolveBrackets(runes []rune, classes []class, para int) {
+    embedding, opposite := classL, classR
+    if para == 1 {
+        embedding, opposite = classR, classL
+    }
+    strong := func(c class) class {
+        switch c {
+        case classL:
+            return classL
+        case classR, classEN, classAN:
+            return classR
+        }
+        return classON
+    }
+
+    var openers []int
+    for i, r := range runes {
+        if classes[i] != classON {
+            continue
+        }
+        if _, ok := closing[r]; ok {
+            openers = append(openers, i)
+            continue
+        }
+        for k := len(openers) - 1; k >= 0; k-- {
+            open := openers[k]
+            if closing[runes[open]] != r {
+                continue
+            }
+            openers = openers[:k]
+
+            resolved := classON
+            for j := open + 1; j < i; j++ {
+                switch strong(classes[j]) {
+                case embedding:
+                    resolved = embedding
+                case opposite:
+                    if resolved == classON {
+                        resolved = opposite
+                    }
+                }
+                if resolved == embedding {
+                    break
+                }
+            }
+            if resolved == opposite {
+                // Opposite text keeps its brackets only if it also
+                // precedes them
+                before := embedding
+                for j := open - 1; j >= 0; j-- {
+                    if c := strong(classes[j]); c != classON {
+                        before = c
+                        break
+                    }
+                }
+                if before != opposite {
+                    resolved = embedding
+                }
+            }
+            if resolved != classON {
+                classes[open], classes[i] = resolved, resolved
+            }
+            break
+        }
+    }
+}
+
+// closing pairs each opening bracket with its closing bracket
+var closing = map[rune]rune{'(': ')', '[': ']', '{': '}'}
+
+// resolveNeutral applies rules N1 and N2: neutrals between text of one
+// direction take that direction, others take the paragraph's
+func resolveNeutral(classes []class, para int) {
+    dir := func(c class) class {
+        if c == classEN || c == classAN {
+            return classR
+        }
+        return c
+    }
+    embedding := classL
+    if para == 1 {
+        embedding = classR
+    }
+    for i := 0; i < len(classes); {
+        if classes[i] != classWS && classes[i] != classON {
+            i++
+            continue
+        }
+        end := i
+        for end < len(classes) && (classes[end] == classWS || classes[end] == classON) {
+            end++
+        }
+        before, after := embedding, embedding
+        if i > 0 {
+            before = dir(classes[i-1])
+        }
+        if end < len(classes) {
+            after = dir(classes[end])
+        }
+        resolved := embedding
+        if before == after {
+            resolved = before
+        }
+        for j := i; j < end; j++ {
+            classes[j] = resolved
+        }
+        i = end
+    }
+}
+
+// resolveLevels applies rules I1, I2 and L1, giving each character its
+// embedding level; odd levels are right-to-left
+func resolveLevels(runes []rune, classes []class, para int) []int {
+    levels := make([]int, len(classes))
+    for i, c := range classes {
+        switch {
+        case para == 0 && c == classR:
+            levels[i] = 1
+        case para == 0 && (c == classEN || c == classAN):
+            levels[i] = 2
+        case para == 1 && (c == classL || c == classEN || c == classAN):
+            levels[i] = 2
+        default:
+            levels[i] = para
+        }
+    }
+    // L1: trailing whitespace stays at the paragraph level
+    for i := len(runes) - 1; i >= 0 && unicode.IsSpace(runes[i]); i-- {
+        levels[i] = para
+    }
+    return levels
+}
+
+// reorder applies rules L2 to L4: runs are reversed from the highest level
+// down to the lowest odd level, vowel marks are kept after the letter they
+// sit on, and brackets in right-to-left runs are mirrored
+func reorder(runes []rune, levels []int) []rune {
+    out := append([]rune(nil), runes...)
+    highest, lowestOdd := 0, 1<<30
+    for i, level := range levels {
+        if level > highest {
+            highest = level
+        }
+        if level%2 == 1 && level < lowestOdd {
+            lowestOdd = level
+        }
+        if level%2 == 1 {
+            if m, ok := mirrors[out[i]]; ok {
+                out[i] = m
+            }
+        }
+    }
+    order := append([]int(nil), levels...)
+    for level := highest; level >= lowestOdd; level-- {
+        for i := 0; i < len(out); {
+            if order[i] < level {
+                i++
+                continue
+            }
+            end := i
+            for end < len(out) && order[end] >= level {
+                end++
+            }
+            for a, b := i, end-1; a < b; a, b = a+1, b-1 {
+                out[a], out[b] = out[b], out[a]
+                order[a], order[b] = order[b], order[a]
+            }
+            i = end
+        }
+    }
+    // L3: reversing put marks in front of their letter; move the letter back
+    // in front so the font draws the marks over it
+    for i := 0; i < len(out); i++ {
+        if order[i]%2 == 0 || !unicode.Is(unicode.Mn, out[i]) {
+            continue
+        }
+        end := i
+        for end < len(out) && unicode.Is(unicode.Mn, out[end]) {
+            end++
+        }
+        if end < len(out) && order[end]%2 == 1 {
+            base := out[end]
+            copy(out[i+1:end+1], out[i:end])
+            out[i] = base
+        }
+        i = end
+    }
+    return out
+}
+
+// mirrors pairs the brackets that are drawn reversed in right-to-left text
+var mirrors = map[rune]rune{
+    '(': ')', ')': '(',
+    '[': ']', ']': '[',
+    '{': '}', '}': '{',
+    '<': '>', '>': '<',
+    '«': '»', '»': '«',
+}
@@ -0,0 +1,58 @@
+package bidi
+
+import "testing"
+
+func TestVisual(t *testing.T) {
+    tests := []struct {
+        in   string
+        rtl  bool
+        want string
+    }{
+        {"Day 1: Arrival", false, "Day 1: Arrival"},
+        {"שלום", false, "םולש"},
+        {"שלום", true, "םולש"},
+        // Numbers keep their order inside right-to-left text
+        {"שלום 123", false, "123 םולש"},
+        {"שלום 123", true, "123 םולש"},
+        {"מחיר 1,250.50 ₹", false, "1,250.50 ריחמ ₹"},
+        {"מחיר 1,250.50 ₹", true, "₹ 1,250.50 ריחמ"},
+        // Brackets are mirrored in right-to-left runs
+        {"(שלום)", false, "(םולש)"},
+        {"(שלום)", true, "(םולש)"},
+        {"abc שלום def", false, "abc םולש def"},
+        {"abc שלום def", true, "def םולש abc"},
+    }
+    for _, tt := range tests {
+        if got := Visual(tt.in, tt.rtl); got != tt.want {
+            t.Errorf("Visual(%q, %t) = %q, want %q", tt.in, tt.rtl, got, tt.want)
+        }
+    }
+}
+
+func TestShape(t *testing.T) {
+    tests := []struct {
+        in   string
+        want []rune
+    }{
+        {"ب", []rune{0xFE8F}},
+        {"ببب", []rune{0xFE91, 0xFE92, 0xFE90}},
+        // Alef doesn't join to the letter after it
+        {"اب", []rune{0xFE8D, 0xFE8F}},
+        {"لا", []rune{0xFEFB}},
+        {"سلام", []rune{0xFEB3, 0xFEFC, 0xFEE1}},
+        // The short i sign moves before its consonant cluster
+        {"कि", []rune("िक")},
+        {"लिखित", []rune("िलिखत")},
+        {"स्थिति", []rune("िस्थित")},
+        {"क़िला", []rune("िक़ला")},
+        {"ि", []rune("ि")},
+    }
+    for _, tt := range tests {
+        if got := Shape(tt.in); got != string(tt.want) {
+            t.Errorf("Shape(%q) = %U, want %U", tt.in, []rune(got), tt.want)
+        }
+    }
+    if got := Shape("Hello"); got != "Hello" {
+        t.Errorf("Shape(%q) = %q", "Hello", got)
+    }
+}
@@ -0,0 +1,64 @@
+// bidi/devanagari.go
+package bidi
+
+// Devanagari is drawn glyph by glyph without the font's shaping tables.
+// Conjuncts then keep their visible virama, which still reads correctly, but
+// the short i sign is typed after the consonants it is written before, so it
+// is moved in front of them.
+
+const (
+    signI  = 0x093F
+    nukta  = 0x093C
+    virama = 0x094D
+    zwnj   = 0x200C
+    zwj    = 0x200D
+)
+
+func devanagariConsonant(r rune) bool {
+    return r >= 0x0915 && r <= 0x0939 || r >= 0x0958 && r <= 0x095F || r >= 0x0978 && r <= 0x097F
+}
+
+// reorderDevanagari moves each short i sign before the consonant cluster it
+// follows, so कि is drawn ि then क and स्थि is drawn ि then स्थ. It reports
+// whether anything moved.
+func reorderDevanagari(runes []rune) bool {
+    moved := false
+    for i, r := range runes {
+        if r != signI {
+            continue
+        }
+        start := clusterStart(runes, i)
+        if start < 0 {
+            continue
+        }
+        copy(runes[start+1:i+1], runes[start:i])
+        runes[start] = signI
+        moved = true
+    }
+    return moved
+}
+
+// clusterStart finds the first consonant of the cluster ending before end:
+// consonants joined by a virama, each perhaps with a nukta. It returns -1
+// when no consonant comes before end.
+func clusterStart(runes []rune, end int) int {
+    start := -1
+    for j := end - 1; ; {
+        if j >= 0 && runes[j] == nukta {
+            j--
+        }
+        if j < 0 || !devanagariConsonant(runes[j]) {
+            return start
+        }
+        start = j
+        // A virama, perhaps followed by a joiner, ties the consonant before it on
+        k := j - 1
+        if k >= 0 && (runes[k] == zwj || runes[k] == zwnj) {
+            k--
+        }
+        if k < 0 || runes[k] != virama {
+            return start
+        }
+        j = k - 1
+    }
+}
@@ -0,0 +1,86 @@
+// cache/lru.go
+package cache
+
+import (
+    "container/list"
+    "sync"
+    "time"
+)
+
+type entry[V any] struct {
+    key     string
+    value   V
+    expires time.Time
+}
+
+// LRU is a fixed size, concurrency safe least-recently-used cache with a TTL
+type LRU[V any] struct {
+    mu       sync.Mutex
+    capacity int
+    ttl      time.Duration
+    order    *list.List
+    items    map[string]*list.Element
+    now      func() time.Time
+}
+
+// NewLRU creates a cache holding at most capacity entries, each valid for ttl.
+// A ttl of zero keeps entries until they are evicted.
+func NewLRU[V any](capacity int, ttl time.Duration) *LRU[V] {
+    return &LRU[V]{
+        capacity: capacity,
+        ttl:      ttl,
+        order:    list.New(),
+        items:    make(map[string]*list.Element),
+        now:      time.Now,
+    }
+}
+
+func (c *LRU[V]) Get(key string) (V, bool) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    var zero V
+    el, ok := c.items[key]
+    if !ok {
+        return zero, false
+    }
+    e := el.Value.(*entry[V])
+    if c.ttl > 0 && c.now().After(e.expires) {
+        c.order.Remove(el)
+        delete(c.items, key)
+        return zero, false
+    }
+    c.order.MoveToFront(el)
+    return e.value, true
+}
+
+func (c *LRU[V]) Add(key string, value V) {
+    if c.capacity <= 0 {
+        return
+    }
+
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    expires := c.now().Add(c.ttl)
+    if el, ok := c.items[key]; ok {
+        e := el.Value.(*entry[V])
+        e.value = value
+        e.expires = expires
+        c.order.MoveToFront(el)
+        return
+    }
+
+    c.items[key] = c.order.PushFront(&entry[V]{key: key, value: value, expires: expires})
+    for c.order.Len() > c.capacity {
+        oldest := c.order.Back()
+        c.order.Remove(oldest)
+        delete(c.items, oldest.Value.(*entry[V]).key)
+    }
+}
+
+func (c *LRU[V]) Len() int {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+    return c.order.Len()
+}
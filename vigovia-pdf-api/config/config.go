@@ -0,0 +1,553 @@
+// config/config.go
+package config
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "flag"
+    "fmt"
+    "net"
+    "net/url"
+    "os"
+    "regexp"
+    "strconv"
+    "strings"
+    "time"
+    "vigovia-pdf-api/auth"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/invoice"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/payments"
+    "vigovia-pdf-api/tracing"
+    "vigovia-pdf-api/types"
+)
+
+// Duration is a time.Duration that reads and writes as a Go duration string ("30s", "2m")
+type Duration struct {
+    time.Duration
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+    return json.Marshal(d.String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+    var s string
+    if err := json.Unmarshal(b, &s); err != nil {
+        return fmt.Errorf("duration must be a string such as \"30s\": %w", err)
+    }
+    parsed, err := time.ParseDuration(s)
+    if err != nil {
+        return err
+    }
+    d.Duration = parsed
+    return nil
+}
+
+type ServerConfig struct {
+    ListenAddr        string   `json:"listenAddr"`
+    TLSCertFile       string   `json:"tlsCertFile"`
+    TLSKeyFile        string   `json:"tlsKeyFile"`
+    ReadTimeout       Duration `json:"readTimeout"`
+    ReadHeaderTimeout Duration `json:"readHeaderTimeout"`
+    WriteTimeout      Duration `json:"writeTimeout"`
+    IdleTimeout       Duration `json:"idleTimeout"`
+    ShutdownDelay     Duration `json:"shutdownDelay"`   // time between failing readiness and draining
+    ShutdownTimeout   Duration `json:"shutdownTimeout"` // upper bound for draining in-flight requests
+    MaxHeaderBytes    int      `json:"maxHeaderBytes"`
+    MaxBodyBytes      int64    `json:"maxBodyBytes"`
+}
+
+type CORSConfig struct {
+    AllowedOrigins []string `json:"allowedOrigins"`
+}
+
+// AnyOrigin reports whether "*" lets every origin call the API
+func (c CORSConfig) AnyOrigin() bool {
+    for _, origin := range c.AllowedOrigins {
+        if origin == "*" {
+            return true
+        }
+    }
+    return false
+}
+
+type AuthConfig struct {
+    KeysFile string     `json:"keysFile"`
+    Keys     []auth.Key `json:"keys"`
+    // UsageFile stores each key's requests this month so quotas survive
+    // restarts; empty keeps them in memory. Replicas need one file each, so
+    // behind several replicas a quota is enforced per replica.
+    UsageFile string `json:"usageFile"`
+}
+
+// CacheConfig sizes the in-memory cache of rendered PDFs; zero entries disables it
+type CacheConfig struct {
+    Entries int      `json:"entries"`
+    TTL     Duration `json:"ttl"`
+}
+
+// CurrencyConfig sets the default secondary display currency and the rate
+// table used to convert into it; requests may supply their own rates.
+type CurrencyConfig struct {
+    Secondary string          `json:"secondary"`
+    Rates     money.RateTable `json:"rates"`
+}
+
+// InvoicingConfig holds the supplier printed on tax invoices and receipts and
+// how they are numbered. Invoices can't be issued without a supplier GSTIN.
+type InvoicingConfig struct {
+    Seller        types.Party `json:"seller"`
+    SAC           string      `json:"sac"`
+    InvoicePrefix string      `json:"invoicePrefix"`
+    ReceiptPrefix string      `json:"receiptPrefix"`
+    // CounterFile stores the last number issued per series; empty keeps
+    // numbering in memory, which restarts it with the server
+    CounterFile string `json:"counterFile"`
+    // InvoicesFile stores the invoices issued so asking again reprints them;
+    // empty keeps them in memory only
+    InvoicesFile string `json:"invoicesFile"`
+}
+
+// StorageConfig sets where stored itineraries and their payments are kept;
+// an empty file keeps them in memory only
+type StorageConfig struct {
+    ItinerariesFile string `json:"itinerariesFile"`
+}
+
+// LocaleConfig sets the language documents are printed in when a request
+// doesn't choose one, and the fonts for scripts the embedded font lacks
+type LocaleConfig struct {
+    Default string `json:"default"`
+    // Fonts maps a script such as "Devanagari" to a TrueType font file
+    Fonts map[string]string `json:"fonts"`
+}
+
+// QRConfig sets where the QR codes printed on itineraries lead. Each code is
+// left out while its setting is empty.
+type QRConfig struct {
+    // ItineraryURL is a stored itinerary's page online, with {id} standing
+    // for its ID, e.g. "https://vigovia.com/trips/{id}"
+    ItineraryURL string `json:"itineraryUrl"`
+    // UPI is who installments are paid to; without a name the company's is shown
+    UPI payments.UPIPayee `json:"upi"`
+}
+
+type Config struct {
+    Server   ServerConfig   `json:"server"`
+    CORS     CORSConfig     `json:"cors"`
+    Auth     AuthConfig     `json:"auth"`
+    Cache    CacheConfig    `json:"cache"`
+    Tracing  tracing.Config `json:"tracing"`
+    Currency CurrencyConfig `json:"currency"`
+    Branding  types.Branding  `json:"branding"`
+    Invoicing InvoicingConfig `json:"invoicing"`
+    Storage   StorageConfig   `json:"storage"`
+    Locale    LocaleConfig    `json:"locale"`
+    QR        QRConfig        `json:"qr"`
+    LogLevel  string          `json:"logLevel"`
+}
+
+// TLSEnabled reports whether the server should terminate TLS itself
+func (c *Config) TLSEnabled() bool {
+    return c.Server.TLSCertFile != "" && c.Server.TLSKeyFile != ""
+}
+
+// UPIPayee is who installments are paid to over UPI, named after the
+// company unless configured otherwise
+func (c *Config) UPIPayee() payments.UPIPayee {
+    payee := c.QR.UPI
+    if payee.ID != "" && payee.Name == "" {
+        payee.Name = c.Branding.CompanyName
+    }
+    return payee
+}
+
+// Default returns the configuration used when no file, env or flags override it
+func Default() Config {
+    return Config{
+        Server: ServerConfig{
+            ListenAddr:        ":5000",
+            ReadTimeout:       Duration{30 * time.Second},
+            ReadHeaderTimeout: Duration{10 * time.Second},
+            WriteTimeout:      Duration{60 * time.Second},
+            IdleTimeout:       Duration{120 * time.Second},
+            ShutdownDelay:     Duration{5 * time.Second},
+            ShutdownTimeout:   Duration{30 * time.Second},
+            MaxHeaderBytes:    64 << 10,
+            MaxBodyBytes:      1 << 20,
+        },
+        CORS: CORSConfig{
+            AllowedOrigins: []string{"http://localhost:5173"},
+        },
+        Cache: CacheConfig{
+            Entries: 64,
+            TTL:     Duration{10 * time.Minute},
+        },
+        Tracing: tracing.Config{
+            ServiceName: "vigovia-pdf-api",
+            SampleRatio: 1,
+        },
+        Currency: CurrencyConfig{
+            Rates: money.RateTable{Base: money.DefaultCurrency},
+        },
+        Branding: types.DefaultBranding(),
+        Invoicing: InvoicingConfig{
+            Seller: types.Party{
+                Name:         types.DefaultBranding().CompanyName,
+                AddressLines: types.DefaultBranding().AddressLines,
+                State:        "KA",
+            },
+            SAC:           invoice.SACTourOperator,
+            InvoicePrefix: "INV",
+            ReceiptPrefix: "RCT",
+            CounterFile:   "invoice-counters.json",
+            InvoicesFile:  "invoices.json",
+        },
+        Storage: StorageConfig{
+            ItinerariesFile: "itineraries.json",
+        },
+        Auth: AuthConfig{
+            UsageFile: "api-usage.json",
+        },
+        Locale: LocaleConfig{
+            Default: i18n.DefaultLocale,
+        },
+        LogLevel: "info",
+    }
+}
+
+// Load builds the configuration from defaults, then the config file, then
+// environment variables and finally command line flags, and validates it.
+func Load(args []string) (Config, error) {
+    cfg := Default()
+
+    fs := flag.NewFlagSet("vigovia-pdf-api", flag.ContinueOnError)
+    configPath := fs.String("config", os.Getenv("VIGOVIA_CONFIG"), "path to a JSON config file")
+    listenAddr := fs.String("listen", "", "listen address, e.g. :5000")
+    tlsCert := fs.String("tls-cert", "", "TLS certificate file")
+    tlsKey := fs.String("tls-key", "", "TLS private key file")
+    origins := fs.String("allowed-origins", "", "comma separated list of allowed CORS origins")
+    logLevel := fs.String("log-level", "", "log level: debug, info, warn or error")
+    keysFile := fs.String("api-keys-file", "", "path to a JSON API keys file")
+    if err := fs.Parse(args); err != nil {
+        return cfg, err
+    }
+
+    if *configPath != "" {
+        if err := loadFile(*configPath, &cfg); err != nil {
+            return cfg, err
+        }
+    }
+
+    if err := applyEnv(&cfg); err != nil {
+        return cfg, err
+    }
+
+    // Flags win over everything else
+    fs.Visit(func(f *flag.Flag) {
+        switch f.Name {
+        case "listen":
+            cfg.Server.ListenAddr = *listenAddr
+        case "tls-cert":
+            cfg.Server.TLSCertFile = *tlsCert
+        case "tls-key":
+            cfg.Server.TLSKeyFile = *tlsKey
+        case "allowed-origins":
+            cfg.CORS.AllowedOrigins = splitList(*origins)
+        case "log-level":
+            cfg.LogLevel = *logLevel
+        case "api-keys-file":
+            cfg.Auth.KeysFile = *keysFile
+        }
+    })
+
+    if cfg.Auth.KeysFile != "" {
+        keys, err := auth.LoadKeys(cfg.Auth.KeysFile)
+        if err != nil {
+            return cfg, err
+        }
+        cfg.Auth.Keys = append(cfg.Auth.Keys, keys...)
+    }
+
+    if err := cfg.Validate(); err != nil {
+        return cfg, err
+    }
+    return cfg, nil
+}
+
+func loadFile(path string, cfg *Config) error {
+    raw, err := os.ReadFile(path)
+    if err != nil {
+        return fmt.Errorf("reading config: %w", err)
+    }
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.DisallowUnknownFields()
+    if err := dec.Decode(cfg); err != nil {
+        return fmt.Errorf("parsing config %s: %w", path, err)
+    }
+    return nil
+}
+
+func applyEnv(cfg *Config) error {
+    // PORT is kept for backwards compatibility with existing deployments
+    if port := os.Getenv("PORT"); port != "" {
+        cfg.Server.ListenAddr = ":" + port
+    }
+    if v := os.Getenv("VIGOVIA_LISTEN_ADDR"); v != "" {
+        cfg.Server.ListenAddr = v
+    }
+    if v := os.Getenv("VIGOVIA_TLS_CERT"); v != "" {
+        cfg.Server.TLSCertFile = v
+    }
+    if v := os.Getenv("VIGOVIA_TLS_KEY"); v != "" {
+        cfg.Server.TLSKeyFile = v
+    }
+    if v := os.Getenv("VIGOVIA_ALLOWED_ORIGINS"); v != "" {
+        cfg.CORS.AllowedOrigins = splitList(v)
+    }
+    if v := os.Getenv("VIGOVIA_LOG_LEVEL"); v != "" {
+        cfg.LogLevel = v
+    }
+    if v := os.Getenv("API_KEYS_FILE"); v != "" {
+        cfg.Auth.KeysFile = v
+    }
+    if v := os.Getenv("VIGOVIA_USAGE_FILE"); v != "" {
+        cfg.Auth.UsageFile = v
+    }
+    if v := os.Getenv("VIGOVIA_MAX_BODY_BYTES"); v != "" {
+        n, err := strconv.ParseInt(v, 10, 64)
+        if err != nil {
+            return fmt.Errorf("VIGOVIA_MAX_BODY_BYTES: %w", err)
+        }
+        cfg.Server.MaxBodyBytes = n
+    }
+
+    durations := map[string]*Duration{
+        "VIGOVIA_READ_TIMEOUT":        &cfg.Server.ReadTimeout,
+        "VIGOVIA_READ_HEADER_TIMEOUT": &cfg.Server.ReadHeaderTimeout,
+        "VIGOVIA_WRITE_TIMEOUT":       &cfg.Server.WriteTimeout,
+        "VIGOVIA_IDLE_TIMEOUT":        &cfg.Server.IdleTimeout,
+        "VIGOVIA_SHUTDOWN_DELAY":      &cfg.Server.ShutdownDelay,
+        "VIGOVIA_SHUTDOWN_TIMEOUT":    &cfg.Server.ShutdownTimeout,
+        "VIGOVIA_CACHE_TTL":           &cfg.Cache.TTL,
+    }
+    for name, target := range durations {
+        v := os.Getenv(name)
+        if v == "" {
+            continue
+        }
+        d, err := time.ParseDuration(v)
+        if err != nil {
+            return fmt.Errorf("%s: %w", name, err)
+        }
+        target.Duration = d
+    }
+
+    if v := os.Getenv("VIGOVIA_CACHE_ENTRIES"); v != "" {
+        n, err := strconv.Atoi(v)
+        if err != nil {
+            return fmt.Errorf("VIGOVIA_CACHE_ENTRIES: %w", err)
+        }
+        cfg.Cache.Entries = n
+    }
+
+    if v := os.Getenv("VIGOVIA_TRACING_ENABLED"); v != "" {
+        enabled, err := strconv.ParseBool(v)
+        if err != nil {
+            return fmt.Errorf("VIGOVIA_TRACING_ENABLED: %w", err)
+        }
+        cfg.Tracing.Enabled = enabled
+    }
+    if v := os.Getenv("VIGOVIA_OTLP_ENDPOINT"); v != "" {
+        cfg.Tracing.Endpoint = v
+    }
+
+    if v := os.Getenv("VIGOVIA_SECONDARY_CURRENCY"); v != "" {
+        cfg.Currency.Secondary = v
+    }
+
+    if v := os.Getenv("VIGOVIA_COMPANY_NAME"); v != "" {
+        cfg.Branding.CompanyName = v
+    }
+    if v := os.Getenv("VIGOVIA_COMPANY_PHONE"); v != "" {
+        cfg.Branding.Phone = v
+    }
+    if v := os.Getenv("VIGOVIA_COMPANY_EMAIL"); v != "" {
+        cfg.Branding.Email = v
+    }
+
+    if v := os.Getenv("VIGOVIA_GSTIN"); v != "" {
+        cfg.Invoicing.Seller.GSTIN = v
+    }
+    if v := os.Getenv("VIGOVIA_INVOICE_COUNTER_FILE"); v != "" {
+        cfg.Invoicing.CounterFile = v
+    }
+    if v := os.Getenv("VIGOVIA_INVOICES_FILE"); v != "" {
+        cfg.Invoicing.InvoicesFile = v
+    }
+    if v := os.Getenv("VIGOVIA_ITINERARIES_FILE"); v != "" {
+        cfg.Storage.ItinerariesFile = v
+    }
+    if v := os.Getenv("VIGOVIA_DEFAULT_LOCALE"); v != "" {
+        cfg.Locale.Default = v
+    }
+    if v := os.Getenv("VIGOVIA_DEVANAGARI_FONT"); v != "" {
+        if cfg.Locale.Fonts == nil {
+            cfg.Locale.Fonts = make(map[string]string)
+        }
+        cfg.Locale.Fonts["Devanagari"] = v
+    }
+    if v := os.Getenv("VIGOVIA_ITINERARY_URL"); v != "" {
+        cfg.QR.ItineraryURL = v
+    }
+    if v := os.Getenv("VIGOVIA_UPI_ID"); v != "" {
+        cfg.QR.UPI.ID = v
+    }
+    return nil
+}
+
+var (
+    gstinPattern = regexp.MustCompile(`^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$`)
+    upiPattern   = regexp.MustCompile(`^[A-Za-z0-9._-]{2,256}@[A-Za-z][A-Za-z0-9]{1,63}$`)
+)
+
+// Validate checks the configuration for values the server can't start with
+func (c *Config) Validate() error {
+    var errs []error
+
+    if _, _, err := net.SplitHostPort(c.Server.ListenAddr); err != nil {
+        errs = append(errs, fmt.Errorf("server.listenAddr %q: %w", c.Server.ListenAddr, err))
+    }
+    if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+        errs = append(errs, errors.New("server.tlsCertFile and server.tlsKeyFile must be set together"))
+    }
+    for _, f := range []string{c.Server.TLSCertFile, c.Server.TLSKeyFile} {
+        if f == "" {
+            continue
+        }
+        if _, err := os.Stat(f); err != nil {
+            errs = append(errs, fmt.Errorf("TLS file: %w", err))
+        }
+    }
+    timeouts := map[string]Duration{
+        "server.readTimeout":       c.Server.ReadTimeout,
+        "server.readHeaderTimeout": c.Server.ReadHeaderTimeout,
+        "server.writeTimeout":      c.Server.WriteTimeout,
+        "server.idleTimeout":       c.Server.IdleTimeout,
+        "server.shutdownTimeout":   c.Server.ShutdownTimeout,
+    }
+    for name, d := range timeouts {
+        if d.Duration <= 0 {
+            errs = append(errs, fmt.Errorf("%s must be positive", name))
+        }
+    }
+    if c.Server.ShutdownDelay.Duration < 0 {
+        errs = append(errs, errors.New("server.shutdownDelay must not be negative"))
+    }
+    if c.Server.MaxHeaderBytes <= 0 {
+        errs = append(errs, errors.New("server.maxHeaderBytes must be positive"))
+    }
+    if c.Server.MaxBodyBytes <= 0 {
+        errs = append(errs, errors.New("server.maxBodyBytes must be positive"))
+    }
+
+    if c.Cache.Entries < 0 || c.Cache.TTL.Duration < 0 {
+        errs = append(errs, errors.New("cache.entries and cache.ttl must not be negative"))
+    }
+
+    if c.Tracing.SampleRatio < 0 || c.Tracing.SampleRatio > 1 {
+        errs = append(errs, errors.New("tracing.sampleRatio must be between 0 and 1"))
+    }
+
+    if c.Currency.Secondary != "" {
+        if _, ok := money.Lookup(c.Currency.Secondary); !ok {
+            errs = append(errs, fmt.Errorf("currency.secondary %q is not a supported currency", c.Currency.Secondary))
+        }
+    }
+    if _, ok := money.Lookup(c.Currency.Rates.Base); !ok {
+        errs = append(errs, fmt.Errorf("currency.rates.base %q is not a supported currency", c.Currency.Rates.Base))
+    }
+    for code, rate := range c.Currency.Rates.Rates {
+        if _, ok := money.Lookup(code); !ok || rate <= 0 {
+            errs = append(errs, fmt.Errorf("currency.rates.rates: %q must be a supported currency with a positive rate", code))
+        }
+    }
+
+    if len(c.CORS.AllowedOrigins) == 0 {
+        errs = append(errs, errors.New("cors.allowedOrigins must list at least one origin"))
+    }
+    for _, origin := range c.CORS.AllowedOrigins {
+        if origin == "*" {
+            continue
+        }
+        u, err := url.Parse(origin)
+        if err != nil || u.Scheme == "" || u.Host == "" || (u.Path != "" && u.Path != "/") {
+            errs = append(errs, fmt.Errorf("cors.allowedOrigins: %q is not a valid origin", origin))
+        }
+    }
+
+    switch strings.ToLower(c.LogLevel) {
+    case "debug", "info", "warn", "error":
+    default:
+        errs = append(errs, fmt.Errorf("logLevel %q must be one of debug, info, warn, error", c.LogLevel))
+    }
+
+    if c.Branding.CompanyName == "" {
+        errs = append(errs, errors.New("branding.companyName is required"))
+    }
+
+    // Longer prefixes would take document numbers past the 16 characters GST allows
+    prefix := regexp.MustCompile(fmt.Sprintf(`^[A-Z0-9]{1,%d}$`, invoice.MaxPrefix))
+    for name, value := range map[string]string{"invoicing.invoicePrefix": c.Invoicing.InvoicePrefix, "invoicing.receiptPrefix": c.Invoicing.ReceiptPrefix} {
+        if !prefix.MatchString(value) {
+            errs = append(errs, fmt.Errorf("%s %q must be 1 to %d capital letters or digits", name, value, invoice.MaxPrefix))
+        }
+    }
+    if c.Invoicing.InvoicePrefix == c.Invoicing.ReceiptPrefix {
+        errs = append(errs, errors.New("invoicing.invoicePrefix and invoicing.receiptPrefix must differ"))
+    }
+    if gstin := c.Invoicing.Seller.GSTIN; gstin != "" && !gstinPattern.MatchString(gstin) {
+        errs = append(errs, fmt.Errorf("invoicing.seller.gstin %q is not a valid GSTIN", gstin))
+    }
+    if c.Invoicing.Seller.Name == "" {
+        errs = append(errs, errors.New("invoicing.seller.name is required"))
+    }
+
+    if _, ok := i18n.Lookup(c.Locale.Default); !ok {
+        errs = append(errs, fmt.Errorf("locale.default %q must be one of %s", c.Locale.Default, strings.Join(i18n.Tags(), ", ")))
+    }
+    for script, path := range c.Locale.Fonts {
+        if _, err := os.Stat(path); err != nil {
+            errs = append(errs, fmt.Errorf("locale.fonts.%s: %w", script, err))
+        }
+    }
+
+    if link := c.QR.ItineraryURL; link != "" {
+        u, err := url.Parse(strings.ReplaceAll(link, "{id}", "id"))
+        if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || !strings.Contains(link, "{id}") {
+            errs = append(errs, fmt.Errorf("qr.itineraryUrl %q must be an http or https URL containing {id}", link))
+        }
+    }
+    if id := c.QR.UPI.ID; id != "" && !upiPattern.MatchString(id) {
+        errs = append(errs, fmt.Errorf("qr.upi.id %q is not a valid UPI ID such as name@bank", id))
+    }
+
+    if _, err := auth.NewAuthenticator(c.Auth.Keys, ""); err != nil {
+        errs = append(errs, err)
+    }
+
+    return errors.Join(errs...)
+}
+
+// Helper function to split a comma separated list, dropping blanks
+func splitList(s string) []string {
+    var items []string
+    for _, item := range strings.Split(s, ",") {
+        if item = strings.TrimSpace(item); item != "" {
+            items = append(items, item)
+        }
+    }
+    return items
+}
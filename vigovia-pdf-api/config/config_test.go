@@ -0,0 +1,193 @@
+package config
+
+import (
+    "os"
+    "path/filepath"
+    "strings"
+    "testing"
+    "time"
+)
+
+// clearEnv blanks every variable Load reads so the host environment can't leak in
+func clearEnv(t *testing.T) {
+    for _, kv := range os.Environ() {
+        name, _, _ := strings.Cut(kv, "=")
+        if name == "PORT" || name == "API_KEYS_FILE" || strings.HasPrefix(name, "VIGOVIA_") {
+            t.Setenv(name, "")
+        }
+    }
+}
+
+func writeFile(t *testing.T, name, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestLoadPrecedence(t *testing.T) {
+    file := `{
+        "server": {"listenAddr": ":7000", "readTimeout": "15s"},
+        "cors": {"allowedOrigins": ["https://file.example"]},
+        "logLevel": "warn"
+    }`
+
+    tests := []struct {
+        name    string
+        env     map[string]string
+        args    []string
+        listen  string
+        origins string
+        level   string
+    }{
+        {"defaults", nil, nil, ":5000", "http://localhost:5173", "info"},
+        {"file", nil, []string{"-config", "FILE"}, ":7000", "https://file.example", "warn"},
+        {"file from env", map[string]string{"VIGOVIA_CONFIG": "FILE"}, nil, ":7000", "https://file.example", "warn"},
+        {"env over file", map[string]string{"VIGOVIA_LISTEN_ADDR": ":8000", "VIGOVIA_LOG_LEVEL": "debug"}, []string{"-config", "FILE"},
+            ":8000", "https://file.example", "debug"},
+        {"PORT", map[string]string{"PORT": "8080"}, []string{"-config", "FILE"}, ":8080", "https://file.example", "warn"},
+        {"listen address over PORT", map[string]string{"PORT": "8080", "VIGOVIA_LISTEN_ADDR": "127.0.0.1:8000"}, nil,
+            "127.0.0.1:8000", "http://localhost:5173", "info"},
+        {"flags over env", map[string]string{"VIGOVIA_LISTEN_ADDR": ":8000", "VIGOVIA_ALLOWED_ORIGINS": "https://env.example"},
+            []string{"-config", "FILE", "-listen", ":9000", "-allowed-origins", "https://a.example, https://b.example"},
+            ":9000", "https://a.example,https://b.example", "warn"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            clearEnv(t)
+            path := writeFile(t, "config.json", file)
+            for name, value := range tt.env {
+                t.Setenv(name, strings.ReplaceAll(value, "FILE", path))
+            }
+            var args []string
+            for _, arg := range tt.args {
+                args = append(args, strings.ReplaceAll(arg, "FILE", path))
+            }
+
+            cfg, err := Load(args)
+            if err != nil {
+                t.Fatal(err)
+            }
+            if cfg.Server.ListenAddr != tt.listen {
+                t.Errorf("listenAddr %q, want %q", cfg.Server.ListenAddr, tt.listen)
+            }
+            if got := strings.Join(cfg.CORS.AllowedOrigins, ","); got != tt.origins {
+                t.Errorf("allowedOrigins %q, want %q", got, tt.origins)
+            }
+            if cfg.LogLevel != tt.level {
+                t.Errorf("logLevel %q, want %q", cfg.LogLevel, tt.level)
+            }
+        })
+    }
+}
+
+func TestLoadKeepsDefaultsAndDurations(t *testing.T) {
+    clearEnv(t)
+    path := writeFile(t, "config.json", `{"server": {"readTimeout": "15s"}}`)
+    t.Setenv("VIGOVIA_WRITE_TIMEOUT", "2m")
+
+    cfg, err := Load([]string{"-config", path})
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := Default().Server
+    want.ReadTimeout = Duration{15 * time.Second}
+    want.WriteTimeout = Duration{2 * time.Minute}
+    if cfg.Server.ListenAddr != want.ListenAddr || cfg.Server.ReadTimeout != want.ReadTimeout ||
+        cfg.Server.WriteTimeout != want.WriteTimeout || cfg.Server.IdleTimeout != want.IdleTimeout {
+        t.Errorf("server %+v, want %+v", cfg.Server, want)
+    }
+}
+
+func TestLoadKeysFile(t *testing.T) {
+    clearEnv(t)
+    keys := writeFile(t, "keys.json", `{"keys": [{"id": "web", "hash": "`+strings.Repeat("ab", 32)+`", "scopes": ["generate"]}]}`)
+    t.Setenv("API_KEYS_FILE", filepath.Join(t.TempDir(), "missing.json"))
+
+    if _, err := Load(nil); err == nil {
+        t.Error("no error for a missing keys file")
+    }
+    cfg, err := Load([]string{"-api-keys-file", keys})
+    if err != nil {
+        t.Fatal(err)
+    }
+    if len(cfg.Auth.Keys) != 1 || cfg.Auth.Keys[0].ID != "web" {
+        t.Errorf("keys %+v, want the one from the file", cfg.Auth.Keys)
+    }
+}
+
+func TestLoadErrors(t *testing.T) {
+    tests := []struct {
+        name string
+        file string
+        env  map[string]string
+        args []string
+        want string
+    }{
+        {"unknown field", `{"server": {"port": 5000}}`, nil, nil, "unknown field"},
+        {"duration without units", `{"server": {"readTimeout": 30}}`, nil, nil, "duration must be a string"},
+        {"bad env duration", `{}`, map[string]string{"VIGOVIA_IDLE_TIMEOUT": "soon"}, nil, "VIGOVIA_IDLE_TIMEOUT"},
+        {"bad env number", `{}`, map[string]string{"VIGOVIA_MAX_BODY_BYTES": "1MB"}, nil, "VIGOVIA_MAX_BODY_BYTES"},
+        {"invalid after flags", `{}`, nil, []string{"-log-level", "verbose"}, "logLevel"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            clearEnv(t)
+            t.Setenv("VIGOVIA_CONFIG", writeFile(t, "config.json", tt.file))
+            for name, value := range tt.env {
+                t.Setenv(name, value)
+            }
+            _, err := Load(tt.args)
+            if err == nil || !strings.Contains(err.Error(), tt.want) {
+                t.Errorf("error %v, want one mentioning %q", err, tt.want)
+            }
+        })
+    }
+}
+
+func TestValidate(t *testing.T) {
+    cert := writeFile(t, "cert.pem", "")
+    tests := []struct {
+        name   string
+        change func(c *Config)
+        want   string
+    }{
+        {"defaults", func(c *Config) {}, ""},
+        {"listen address without a port", func(c *Config) { c.Server.ListenAddr = "5000" }, "server.listenAddr"},
+        {"TLS certificate without a key", func(c *Config) { c.Server.TLSCertFile = cert }, "must be set together"},
+        {"missing TLS files", func(c *Config) {
+            c.Server.TLSCertFile, c.Server.TLSKeyFile = cert, cert+".missing"
+        }, "TLS file"},
+        {"TLS", func(c *Config) { c.Server.TLSCertFile, c.Server.TLSKeyFile = cert, cert }, ""},
+        {"zero timeout", func(c *Config) { c.Server.WriteTimeout = Duration{} }, "server.writeTimeout must be positive"},
+        {"zero body size", func(c *Config) { c.Server.MaxBodyBytes = 0 }, "server.maxBodyBytes"},
+        {"no origins", func(c *Config) { c.CORS.AllowedOrigins = nil }, "at least one origin"},
+        {"origin with a path", func(c *Config) { c.CORS.AllowedOrigins = []string{"https://app.example/login"} }, "not a valid origin"},
+        {"any origin", func(c *Config) { c.CORS.AllowedOrigins = []string{"*"} }, ""},
+        {"log level", func(c *Config) { c.LogLevel = "trace" }, "logLevel"},
+        {"log level in capitals", func(c *Config) { c.LogLevel = "WARN" }, ""},
+        {"no company name", func(c *Config) { c.Branding.CompanyName = "" }, "branding.companyName"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            cfg := Default()
+            tt.change(&cfg)
+            err := cfg.Validate()
+            switch {
+            case tt.want == "" && err != nil:
+                t.Errorf("unexpected error: %v", err)
+            case tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want)):
+                t.Errorf("error %v, want one mentioning %q", err, tt.want)
+            }
+        })
+    }
+
+    // Every problem is reported, not just the first
+    cfg := Default()
+    cfg.LogLevel, cfg.CORS.AllowedOrigins = "trace", nil
+    if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "logLevel") || !strings.Contains(err.Error(), "origin") {
+        t.Errorf("error %v, want both problems", err)
+    }
+}
@@ -0,0 +1,216 @@
+// datetime/date.go
+package datetime
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "regexp"
+    "strings"
+    "time"
+    "vigovia-pdf-api/schema"
+)
+
+// DateLayout is how dates are written in JSON and APIs
+const DateLayout = "2006-01-02"
+
+var ErrInvalidDate = errors.New("datetime: not a recognised date")
+
+// Date is a calendar day without a time of day or zone, such as a check-in
+// or due date. The zero Date means no date was given.
+type Date struct {
+    // t is midnight UTC of the day, so dates compare and subtract exactly
+    t time.Time
+}
+
+// NewDate returns the given day; out of range values are normalised as
+// time.Date does
+func NewDate(year int, month time.Month, day int) Date {
+    return Date{t: time.Date(year, month, day, 0, 0, 0, 0, time.UTC)}
+}
+
+// DateOf returns the calendar day of t in t's own location
+func DateOf(t time.Time) Date {
+    return NewDate(t.Date())
+}
+
+// Today returns the current day in loc
+func Today(loc *time.Location) Date {
+    return DateOf(time.Now().In(loc))
+}
+
+// dateLayouts are the formats ParseDate accepts. Numeric dates are read day
+// first, as they are written in India and Europe, so 02/03/2026 is 2 March.
+var dateLayouts = []string{
+    DateLayout,
+    "2/1/2006",
+    "2-1-2006",
+    "2.1.2006",
+    "2 Jan 2006",
+    "2 January 2006",
+    "2-Jan-2006",
+    "2Jan2006",
+    "2 Jan'06",
+    "Jan 2, 2006",
+    "January 2, 2006",
+    "Jan 2 2006",
+    "January 2 2006",
+}
+
+// ordinal matches day suffixes such as the "th" in "27th November 2026"
+var ordinal = regexp.MustCompile(`(\d)(st|nd|rd|th)\b`)
+
+// weekday matches a leading day name such as "Thu " or "Monday, "
+var weekday = regexp.MustCompile(`^(?i)(mon|tue|wed|thu|fri|sat|sun)[a-z]*,?\s+`)
+
+// normalise strips the parts of a written date that don't change its value
+func normalise(s string) string {
+    s = strings.Join(strings.Fields(s), " ")
+    s = ordinal.ReplaceAllString(s, "$1")
+    return weekday.ReplaceAllString(s, "")
+}
+
+// ParseDate reads a date written as 2026-11-10, 10/11/2026, 10-11-2026,
+// 10 Nov 2026, 10th November 2026, Nov 10, 2026, Tue 10 Nov'26 or
+// 10NOV2026. A date-time such as 2026-11-10T09:30:00+05:30 gives its day.
+func ParseDate(s string) (Date, error) {
+    s = normalise(s)
+    for _, layout := range dateLayouts {
+        if t, err := time.Parse(layout, s); err == nil {
+            return DateOf(t), nil
+        }
+    }
+    if dt, err := ParseDateTime(s); err == nil {
+        return dt.Date(), nil
+    }
+    return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+}
+
+// yearlessLayouts are dates written without a year, e.g. "27th November"
+var yearlessLayouts = []string{"2 Jan", "2 January", "Jan 2", "January 2", "2Jan"}
+
+// ParseDateNear reads a date like ParseDate, and also accepts dates without a
+// year such as "27th November", which are taken to be the first such day on
+// or after ref; 29 February is the next one in a leap year
+func ParseDateNear(s string, ref Date) (Date, error) {
+    if d, err := ParseDate(s); err == nil || ref.IsZero() {
+        return d, err
+    }
+    s = normalise(s)
+    for _, layout := range yearlessLayouts {
+        t, err := time.Parse(layout, s)
+        if err != nil {
+            continue
+        }
+        for year := ref.Year(); ; year++ {
+            // NewDate would roll a missing 29 February into March
+            d := NewDate(year, t.Month(), t.Day())
+            if d.Day() == t.Day() && !d.Before(ref) {
+                return d, nil
+            }
+        }
+    }
+    return Date{}, fmt.Errorf("%w: %q", ErrInvalidDate, s)
+}
+
+func (d Date) IsZero() bool {
+    return d.t.IsZero()
+}
+
+// Time returns midnight at the start of the day in loc
+func (d Date) Time(loc *time.Location) time.Time {
+    return time.Date(d.t.Year(), d.t.Month(), d.t.Day(), 0, 0, 0, 0, loc)
+}
+
+func (d Date) Year() int {
+    return d.t.Year()
+}
+
+func (d Date) Month() time.Month {
+    return d.t.Month()
+}
+
+func (d Date) Day() int {
+    return d.t.Day()
+}
+
+func (d Date) Weekday() time.Weekday {
+    return d.t.Weekday()
+}
+
+func (d Date) Before(o Date) bool {
+    return d.t.Before(o.t)
+}
+
+func (d Date) After(o Date) bool {
+    return d.t.After(o.t)
+}
+
+func (d Date) Equal(o Date) bool {
+    return d.t.Equal(o.t)
+}
+
+// AddDays returns the date n days later, or earlier for negative n
+func (d Date) AddDays(n int) Date {
+    return Date{t: d.t.AddDate(0, 0, n)}
+}
+
+// AddMonths returns the date n months later; days past the end of the
+// month roll over into the next, as with time.AddDate
+func (d Date) AddMonths(n int) Date {
+    return Date{t: d.t.AddDate(0, n, 0)}
+}
+
+// Format formats the date with a time package layout
+func (d Date) Format(layout string) string {
+    return d.t.Format(layout)
+}
+
+// DaysUntil returns the number of days from d to o, negative if o is earlier
+func (d Date) DaysUntil(o Date) int {
+    return int(o.t.Sub(d.t).Hours() / 24)
+}
+
+// String returns the date as YYYY-MM-DD, or "" for the zero Date
+func (d Date) String() string {
+    if d.IsZero() {
+        return ""
+    }
+    return d.t.Format(DateLayout)
+}
+
+// MarshalJSON writes YYYY-MM-DD, or null when no date is set
+func (d Date) MarshalJSON() ([]byte, error) {
+    if d.IsZero() {
+        return []byte("null"), nil
+    }
+    return json.Marshal(d.String())
+}
+
+// UnmarshalJSON accepts any format ParseDate does; null and "" leave the
+// date unset
+func (d *Date) UnmarshalJSON(b []byte) error {
+    var s *string
+    if err := json.Unmarshal(b, &s); err != nil {
+        return err
+    }
+    if s == nil || strings.TrimSpace(*s) == "" {
+        *d = Date{}
+        return nil
+    }
+    parsed, err := ParseDate(*s)
+    if err != nil {
+        return err
+    }
+    *d = parsed
+    return nil
+}
+
+func (Date) JSONSchema() *schema.Schema {
+    return &schema.Schema{
+        Type:        "string",
+        Format:      "date",
+        Nullable:    true,
+        Description: "A calendar day, returned as YYYY-MM-DD. Also accepts 10/11/2026 (day first), 10 Nov 2026, 10th November 2026 and Nov 10, 2026.",
+    }
+}
@@ -0,0 +1,84 @@
+package datetime
+
+import (
+    "errors"
+    "testing"
+)
+
+func TestParseDate(t *testing.T) {
+    tests := []struct {
+        in   string
+        want string
+    }{
+        {"2026-11-10", "2026-11-10"},
+        {"10/11/2026", "2026-11-10"},
+        {"02/03/2026", "2026-03-02"},
+        {"10 Nov 2026", "2026-11-10"},
+        {"27th November 2026", "2026-11-27"},
+        {"Nov 10, 2026", "2026-11-10"},
+        {"Tue 10 Nov'26", "2026-11-10"},
+        {"10NOV2026", "2026-11-10"},
+        {"2026-11-10T23:30:00+05:30", "2026-11-10"},
+    }
+    for _, tt := range tests {
+        d, err := ParseDate(tt.in)
+        if err != nil {
+            t.Errorf("ParseDate(%q): %v", tt.in, err)
+            continue
+        }
+        if d.String() != tt.want {
+            t.Errorf("ParseDate(%q) = %s, want %s", tt.in, d, tt.want)
+        }
+    }
+}
+
+func TestParseDateNear(t *testing.T) {
+    tests := []struct {
+        in, ref string
+        want    string
+    }{
+        {"5 Jan", "2026-12-20", "2027-01-05"},
+        {"05JAN", "2026-12-20", "2027-01-05"},
+        {"20 Dec", "2026-12-20", "2026-12-20"},
+        {"19 Dec", "2026-12-20", "2027-12-19"},
+        {"31DEC", "2026-01-01", "2026-12-31"},
+        {"27th November", "2026-10-19", "2026-11-27"},
+        {"January 2", "2026-12-31", "2027-01-02"},
+        {"29 Feb", "2027-03-01", "2028-02-29"},
+        {"29 Feb", "2028-01-15", "2028-02-29"},
+        // A year in the text wins over ref
+        {"5 Jan 2026", "2026-12-20", "2026-01-05"},
+    }
+    for _, tt := range tests {
+        ref, err := ParseDate(tt.ref)
+        if err != nil {
+            t.Fatal(err)
+        }
+        d, err := ParseDateNear(tt.in, ref)
+        if err != nil {
+            t.Errorf("ParseDateNear(%q, %s): %v", tt.in, tt.ref, err)
+            continue
+        }
+        if d.String() != tt.want {
+            t.Errorf("ParseDateNear(%q, %s) = %s, want %s", tt.in, tt.ref, d, tt.want)
+        }
+    }
+}
+
+func TestParseDateNearErrors(t *testing.T) {
+    ref := NewDate(2026, 12, 20)
+    tests := []struct {
+        in  string
+        ref Date
+    }{
+        {"31 Feb", ref},
+        {"someday", ref},
+        // Without a reference a date needs its year
+        {"5 Jan", Date{}},
+    }
+    for _, tt := range tests {
+        if d, err := ParseDateNear(tt.in, tt.ref); !errors.Is(err, ErrInvalidDate) {
+            t.Errorf("ParseDateNear(%q, %s) = %s, %v, want ErrInvalidDate", tt.in, tt.ref, d, err)
+        }
+    }
+}
@@ -0,0 +1,265 @@
+// datetime/datetime.go
+package datetime
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "strings"
+    "time"
+    // Zone rules are embedded so IANA names resolve on hosts without tzdata
+    _ "time/tzdata"
+    "vigovia-pdf-api/schema"
+)
+
+var (
+    ErrInvalidDateTime = errors.New("datetime: not a recognised date and time")
+    ErrUnknownZone     = errors.New("datetime: unknown time zone")
+    // ErrFloating is returned when a calculation needs to know where a time
+    // without a zone or offset was read
+    ErrFloating = errors.New("datetime: time has no zone")
+)
+
+// DateTime is a wall clock time at a place, such as a flight departure. It
+// is usually tied to an IANA zone like "Asia/Kolkata", which keeps the local
+// reading and daylight saving correct; a bare UTC offset is also accepted.
+// A time given without either is floating: it can be formatted and compared
+// with times in the same place, but durations need a zone first (WithZone).
+type DateTime struct {
+    t        time.Time
+    zone     string
+    floating bool
+}
+
+// timeLayouts are the times of day ParseDateTime accepts after a date
+var timeLayouts = []string{"15:04:05", "15:04", "3:04 PM", "3:04PM", "3PM", "1504"}
+
+// LoadZone resolves an IANA zone name such as "Europe/Paris"
+func LoadZone(name string) (*time.Location, error) {
+    if name == "" || strings.EqualFold(name, "local") {
+        return nil, fmt.Errorf("%w: %q", ErrUnknownZone, name)
+    }
+    loc, err := time.LoadLocation(name)
+    if err != nil {
+        return nil, fmt.Errorf("%w: %q", ErrUnknownZone, name)
+    }
+    return loc, nil
+}
+
+// In returns the moment t in the IANA zone loc, e.g. a departure time
+// read from a system clock
+func In(t time.Time, loc *time.Location) DateTime {
+    return DateTime{t: t.In(loc), zone: loc.String()}
+}
+
+// ParseDateTime reads a date and time in any of these forms:
+//
+//  2026-11-10T09:30:00+05:30               RFC 3339 with an offset
+//  2026-11-10T09:30[Asia/Kolkata]          local time in an IANA zone (RFC 9557)
+//  2026-11-10T09:30:00+05:30[Asia/Kolkata] both, which must agree
+//  2026-11-10 09:30 Asia/Kolkata           zone after a space
+//  10/11/2026 9:30 PM                      any ParseDate format and a time
+//
+// A time without a zone or offset is floating.
+func ParseDateTime(s string) (DateTime, error) {
+    s = strings.Join(strings.Fields(s), " ")
+    zone := ""
+    if open := strings.LastIndex(s, "["); open >= 0 && strings.HasSuffix(s, "]") {
+        zone, s = s[open+1:len(s)-1], s[:open]
+    } else if i := strings.LastIndex(s, " "); i >= 0 && strings.Contains(s[i+1:], "/") && !strings.ContainsAny(s[i+1:], "0123456789") {
+        zone, s = s[i+1:], s[:i]
+    }
+    if zone == "" && strings.HasSuffix(s, " UTC") {
+        zone, s = "UTC", strings.TrimSuffix(s, " UTC")
+    }
+
+    var loc *time.Location
+    if zone != "" {
+        var err error
+        if loc, err = LoadZone(zone); err != nil {
+            return DateTime{}, err
+        }
+    }
+
+    // A time with an offset is an exact moment
+    for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00"} {
+        t, err := time.Parse(layout, s)
+        if err != nil {
+            continue
+        }
+        if loc == nil {
+            return DateTime{t: t}, nil
+        }
+        in := t.In(loc)
+        if _, offset := in.Zone(); offset != offsetOf(t) {
+            return DateTime{}, fmt.Errorf("%w: offset of %q doesn't match %s", ErrInvalidDateTime, s, zone)
+        }
+        return DateTime{t: in, zone: loc.String()}, nil
+    }
+
+    // Otherwise it's a local reading, in the zone if one was given
+    parseIn := loc
+    if parseIn == nil {
+        parseIn = time.UTC
+    }
+    local := normalise(s)
+    for _, dateLayout := range dateLayouts {
+        for _, timeLayout := range timeLayouts {
+            for _, sep := range []string{"T", " ", ", "} {
+                t, err := time.ParseInLocation(dateLayout+sep+timeLayout, local, parseIn)
+                if err != nil {
+                    continue
+                }
+                if loc == nil {
+                    return DateTime{t: t, floating: true}, nil
+                }
+                return DateTime{t: t, zone: loc.String()}, nil
+            }
+        }
+    }
+    return DateTime{}, fmt.Errorf("%w: %q", ErrInvalidDateTime, s)
+}
+
+func offsetOf(t time.Time) int {
+    _, offset := t.Zone()
+    return offset
+}
+
+func (d DateTime) IsZero() bool {
+    return d.t.IsZero()
+}
+
+// Floating reports whether the time was given without a zone or offset
+func (d DateTime) Floating() bool {
+    return d.floating
+}
+
+// Zone returns the IANA zone name, or "" for offsets and floating times
+func (d DateTime) Zone() string {
+    return d.zone
+}
+
+// Time returns the moment; floating times read as if they were UTC
+func (d DateTime) Time() time.Time {
+    return d.t
+}
+
+// Date returns the local calendar day
+func (d DateTime) Date() Date {
+    return DateOf(d.t)
+}
+
+// Clock returns the local time of day as 15:04
+func (d DateTime) Clock() string {
+    return d.t.Format("15:04")
+}
+
+// WithZone places a floating time in loc, keeping its wall clock reading.
+// Times that already have a zone or offset, and any time when loc is nil,
+// are returned unchanged.
+func (d DateTime) WithZone(loc *time.Location) DateTime {
+    if !d.floating || d.IsZero() || loc == nil {
+        return d
+    }
+    year, month, day := d.t.Date()
+    hour, min, sec := d.t.Clock()
+    return DateTime{t: time.Date(year, month, day, hour, min, sec, d.t.Nanosecond(), loc), zone: loc.String()}
+}
+
+// Sub returns the duration from o to d. Both need a zone or offset unless
+// both are floating, in which case they are taken to be in the same place.
+func (d DateTime) Sub(o DateTime) (time.Duration, error) {
+    if d.floating != o.floating {
+        return 0, ErrFloating
+    }
+    return d.t.Sub(o.t), nil
+}
+
+// Before reports whether d is earlier than o, comparing floating times by
+// their wall clock reading
+func (d DateTime) Before(o DateTime) bool {
+    return d.t.Before(o.t)
+}
+
+// String writes the time in RFC 9557 form, e.g.
+// 2026-11-10T09:30:00+05:30[Asia/Kolkata]; floating times have no offset
+func (d DateTime) String() string {
+    switch {
+    case d.IsZero():
+        return ""
+    case d.floating:
+        return d.t.Format("2006-01-02T15:04:05")
+    case d.zone != "":
+        return d.t.Format(time.RFC3339) + "[" + d.zone + "]"
+    default:
+        return d.t.Format(time.RFC3339)
+    }
+}
+
+func (d DateTime) MarshalJSON() ([]byte, error) {
+    if d.IsZero() {
+        return []byte("null"), nil
+    }
+    return json.Marshal(d.String())
+}
+
+func (d *DateTime) UnmarshalJSON(b []byte) error {
+    var s *string
+    if err := json.Unmarshal(b, &s); err != nil {
+        return err
+    }
+    if s == nil || strings.TrimSpace(*s) == "" {
+        *d = DateTime{}
+        return nil
+    }
+    parsed, err := ParseDateTime(*s)
+    if err != nil {
+        return err
+    }
+    *d = parsed
+    return nil
+}
+
+func (DateTime) JSONSchema() *schema.Schema {
+    return &schema.Schema{
+        Type:        "string",
+        Format:      "date-time",
+        Nullable:    true,
+        Description: "A local date and time, returned as 2026-11-10T09:30:00+05:30[Asia/Kolkata]. Also accepts RFC 3339 offsets, 2026-11-10 09:30 Asia/Kolkata and any accepted date followed by a time such as 9:30 PM. Times without a zone or offset are floating.",
+    }
+}
+
+// FormatDuration writes a duration as hours and minutes, e.g. "2h 05m"
+func FormatDuration(d time.Duration) string {
+    d = d.Round(time.Minute)
+    sign := ""
+    if d < 0 {
+        sign, d = "-", -d
+    }
+    return fmt.Sprintf("%s%dh %02dm", sign, int(d.Hours()), int(d.Minutes())%60)
+}
+
+func init() {
+    schema.RegisterFormat("date", func(s string) error {
+        if _, err := ParseDate(s); err != nil {
+            return fmt.Errorf("%q is not a date, use YYYY-MM-DD", s)
+        }
+        return nil
+    })
+    schema.RegisterFormat("date-time", func(s string) error {
+        _, err := ParseDateTime(s)
+        switch {
+        case errors.Is(err, ErrUnknownZone):
+            return fmt.Errorf("%q names an unknown IANA time zone", s)
+        case err != nil:
+            return fmt.Errorf("%q is not a date and time, use e.g. 2026-11-10T09:30[Asia/Kolkata]", s)
+        }
+        return nil
+    })
+    schema.RegisterFormat("time-zone", func(s string) error {
+        if _, err := LoadZone(s); err != nil {
+            return fmt.Errorf("%q is not an IANA time zone such as Asia/Kolkata", s)
+        }
+        return nil
+    })
+}
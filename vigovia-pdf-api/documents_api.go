@@ -0,0 +1,268 @@
+package main
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "net/http"
+    "strings"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/invoice"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/metrics"
+    "vigovia-pdf-api/migrate"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/schema"
+    "vigovia-pdf-api/tracing"
+    "vigovia-pdf-api/types"
+    "vigovia-pdf-api/utils"
+    "vigovia-pdf-api/voucher"
+)
+
+var (
+    // documentCounters numbers invoices and receipts
+    documentCounters *invoice.Counters
+    // issuedInvoices keeps the invoices issued, to reprint them
+    issuedInvoices *invoice.Ledger
+)
+
+type invoiceRequest struct {
+    Itinerary   types.ItineraryData `json:"itinerary" schema:"required"`
+    Buyer       types.Party         `json:"buyer" schema:"required"`
+    InvoiceDate datetime.Date       `json:"invoiceDate,omitempty" doc:"Defaults to today"`
+}
+
+type receiptRequest struct {
+    Itinerary     types.ItineraryData `json:"itinerary" schema:"required"`
+    InstallmentID string              `json:"installmentId" schema:"required"`
+    InvoiceNumber string              `json:"invoiceNumber,omitempty"`
+    ReceiptDate   datetime.Date       `json:"receiptDate,omitempty" doc:"Defaults to today"`
+    Amount        *money.Money        `json:"amount,omitempty" doc:"Defaults to the installment amount, and can't exceed it"`
+    Method        string              `json:"method,omitempty" schema:"enum=upi|card|netBanking|bankTransfer|cash|cheque"`
+    Reference     string              `json:"reference,omitempty"`
+}
+
+// upgradeNested upgrades an itinerary embedded under field in a request body,
+// reporting version errors at their path inside the request
+func upgradeNested(field string) schema.Transform {
+    return func(doc interface{}) error {
+        obj, ok := doc.(map[string]interface{})
+        if !ok {
+            return nil
+        }
+        nested, ok := obj[field]
+        if !ok {
+            return nil
+        }
+        _, err := migrate.Upgrade(nested)
+        var validationErr *schema.ValidationError
+        if errors.As(err, &validationErr) {
+            for i := range validationErr.Errors {
+                validationErr.Errors[i].Path = "$." + field + strings.TrimPrefix(validationErr.Errors[i].Path, "$")
+            }
+        }
+        return err
+    }
+}
+
+// documentDate returns the date a document is issued on, defaulting to today
+func documentDate(d datetime.Date) datetime.Date {
+    if d.IsZero() {
+        return datetime.Today(time.Local)
+    }
+    return d
+}
+
+// invoiceHandler issues a numbered tax invoice for an itinerary's payment plan
+func invoiceHandler(w http.ResponseWriter, r *http.Request) {
+    logger := logging.FromContext(r.Context())
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    if cfg.Invoicing.Seller.GSTIN == "" {
+        writeError(w, r, http.StatusServiceUnavailable, "Invoicing not configured", "Set invoicing.seller.gstin to issue tax invoices")
+        return
+    }
+    done, ok := startJob(w, r)
+    if !ok {
+        return
+    }
+    defer done()
+
+    var req invoiceRequest
+    if !decodeBody(w, r, &req, upgradeNested("itinerary")) {
+        return
+    }
+    date := documentDate(req.InvoiceDate)
+
+    inv, err := invoice.NewInvoice(req.Itinerary, cfg.Invoicing.Seller, req.Buyer, cfg.Invoicing.SAC)
+    if err != nil {
+        writeError(w, r, http.StatusUnprocessableEntity, "Invoice can't be issued", err.Error())
+        return
+    }
+
+    // The number is only taken once the invoice is known to be valid and has
+    // rendered, so neither rejected requests nor failed renders leave gaps
+    var pdfBytes []byte
+    var renderErr error
+    issued, reprint, err := issuedInvoices.Issue(inv, cfg.Invoicing.InvoicePrefix, date, func(inv *invoice.Invoice) error {
+        pdfBytes, renderErr = renderDocument(r, "invoice", inv.Number, func(ctx context.Context, opts utils.Options) ([]byte, error) {
+            return utils.GenerateInvoice(ctx, inv, opts)
+        })
+        return renderErr
+    })
+    if renderErr != nil {
+        writeError(w, r, http.StatusInternalServerError, "PDF generation failed", renderErr.Error())
+        return
+    }
+    if err != nil {
+        logger.Error("Failed to number or store the invoice", "error", err)
+        writeError(w, r, http.StatusInternalServerError, "Invoice numbering failed", err.Error())
+        return
+    }
+    if reprint {
+        logger.Info("Reprinting invoice", "invoice_number", issued.Number)
+    } else {
+        logger.Info("Issued invoice", "invoice_number", issued.Number)
+    }
+    sendDocument(w, issued.Number, pdfBytes)
+}
+
+// receiptHandler issues a numbered receipt for a payment against an installment
+func receiptHandler(w http.ResponseWriter, r *http.Request) {
+    logger := logging.FromContext(r.Context())
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    done, ok := startJob(w, r)
+    if !ok {
+        return
+    }
+    defer done()
+
+    var req receiptRequest
+    if !decodeBody(w, r, &req, upgradeNested("itinerary")) {
+        return
+    }
+    date := documentDate(req.ReceiptDate)
+
+    var amount money.Money
+    if req.Amount != nil {
+        amount = *req.Amount
+    }
+    receipt, err := invoice.NewReceipt(req.Itinerary, cfg.Invoicing.Seller, req.InstallmentID, amount)
+    if err != nil {
+        writeError(w, r, http.StatusUnprocessableEntity, "Receipt can't be issued", err.Error())
+        return
+    }
+
+    receipt.Date = date
+    receipt.InvoiceNumber = req.InvoiceNumber
+    receipt.Method = req.Method
+    receipt.Reference = req.Reference
+
+    // As with invoices, the number is only taken once the receipt has rendered
+    var pdfBytes []byte
+    var renderErr error
+    number, err := documentCounters.Issue(cfg.Invoicing.ReceiptPrefix, date, func(number string) error {
+        receipt.Number = number
+        pdfBytes, renderErr = renderDocument(r, "receipt", number, func(ctx context.Context, opts utils.Options) ([]byte, error) {
+            return utils.GenerateReceipt(ctx, receipt, opts)
+        })
+        return renderErr
+    })
+    if renderErr != nil {
+        writeError(w, r, http.StatusInternalServerError, "PDF generation failed", renderErr.Error())
+        return
+    }
+    if err != nil {
+        logger.Error("Failed to allocate a receipt number", "error", err)
+        writeError(w, r, http.StatusInternalServerError, "Receipt numbering failed", err.Error())
+        return
+    }
+    logger.Info("Issued receipt", "receipt_number", number, "installment", req.InstallmentID)
+    sendDocument(w, number, pdfBytes)
+}
+
+// vouchersHandler renders the vouchers for an itinerary's bookings as their
+// own PDF, to send to travellers apart from the itinerary
+func vouchersHandler(w http.ResponseWriter, r *http.Request) {
+    logger := logging.FromContext(r.Context())
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    done, ok := startJob(w, r)
+    if !ok {
+        return
+    }
+    defer done()
+
+    itineraryData, ok := decodeItinerary(w, r)
+    if !ok {
+        return
+    }
+    locale, ok := documentLocale(w, r, &itineraryData)
+    if !ok {
+        return
+    }
+    if len(voucher.Build(itineraryData)) == 0 {
+        writeError(w, r, http.StatusUnprocessableEntity, "Nothing to voucher", "No hotel, transfer or activity has a confirmation number or supplier")
+        return
+    }
+
+    renderDone := metrics.RenderStarted()
+    renderCtx, renderSpan := tracing.StartSpan(r.Context(), "generate_vouchers")
+    pdfBytes, err := utils.GenerateVouchers(renderCtx, itineraryData, utils.Options{
+        Branding: cfg.Branding,
+        Locale:   locale,
+        Fonts:    scriptFonts,
+        Observer: utils.MultiObserver{metrics.RenderObserver{}, tracing.NewRenderObserver(renderCtx)},
+    })
+    tracing.RecordError(renderSpan, err)
+    renderSpan.End()
+    renderDone(err)
+    if err != nil {
+        logger.Error("Voucher generation failed", "error", err, "destination", itineraryData.TripDetails.Destination)
+        writeRenderError(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/pdf")
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_Vouchers.pdf"`, itineraryData.TripDetails.Destination))
+    w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+    w.Write(pdfBytes)
+}
+
+// renderDocument renders a numbered document, recording metrics and a span
+func renderDocument(r *http.Request, kind, number string, render func(context.Context, utils.Options) ([]byte, error)) ([]byte, error) {
+    renderDone := metrics.RenderStarted()
+    renderCtx, renderSpan := tracing.StartSpan(r.Context(), "generate_"+kind)
+    pdfBytes, err := render(renderCtx, utils.Options{
+        Branding: cfg.Branding,
+        Observer: utils.MultiObserver{metrics.RenderObserver{}, tracing.NewRenderObserver(renderCtx)},
+    })
+    tracing.RecordError(renderSpan, err)
+    renderSpan.End()
+    renderDone(err)
+    if err != nil {
+        logging.FromContext(r.Context()).Error("Document generation failed", "kind", kind, "number", number, "error", err)
+    }
+    return pdfBytes, err
+}
+
+// sendDocument sends a rendered document as a download named after its number
+func sendDocument(w http.ResponseWriter, number string, pdfBytes []byte) {
+    filename := strings.ReplaceAll(number, "/", "-")
+    w.Header().Set("Content-Type", "application/pdf")
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.pdf"`, filename))
+    w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+    w.Header().Set("X-Document-Number", number)
+    w.Write(pdfBytes)
+}
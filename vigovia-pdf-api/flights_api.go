@@ -0,0 +1,35 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/pnr"
+)
+
+type parseFlightsRequest struct {
+    Text string `json:"text" schema:"required"`
+    ReferenceDate datetime.Date `json:"referenceDate,omitempty" doc:"Dates without a year are read as the first such day on or after this date. Defaults to today"`
+}
+
+// parseFlightsHandler reads flight segments from a pasted PNR display or
+// e-ticket, returning the lines it couldn't read alongside them
+func parseFlightsHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    var req parseFlightsRequest
+    if !decodeBody(w, r, &req) {
+        return
+    }
+    ref := req.ReferenceDate
+    if ref.IsZero() {
+        ref = datetime.Today(time.Local)
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(pnr.Parse(req.Text, ref))
+}
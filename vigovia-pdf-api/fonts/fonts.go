@@ -0,0 +1,27 @@
+// fonts/fonts.go
+package fonts
+
+import _ "embed"
+
+// Arial covers Latin, Greek, Cyrillic, Arabic, Hebrew and currency symbols
+// such as ₹ and €, which the PDF core fonts can't print.
+//
+//go:embed ARIAL.TTF
+var Arial []byte
+
+//go:embed ARIALNBI.TTF
+var ArialNarrowBoldItalic []byte
+
+// arialScripts are the writing systems Arial has glyphs for
+var arialScripts = map[string]bool{
+    "Latin":    true,
+    "Greek":    true,
+    "Cyrillic": true,
+    "Arabic":   true,
+    "Hebrew":   true,
+}
+
+// Covers reports whether the embedded font can print script
+func Covers(script string) bool {
+    return script == "" || arialScripts[script]
+}
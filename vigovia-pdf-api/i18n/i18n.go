@@ -0,0 +1,190 @@
+// i18n/i18n.go
+package i18n
+
+import (
+    "embed"
+    "encoding/json"
+    "fmt"
+    "path"
+    "sort"
+    "strconv"
+    "strings"
+    "time"
+    "vigovia-pdf-api/datetime"
+)
+
+// DefaultLocale is used when a request names no supported locale, and is the
+// fallback for messages a catalogue doesn't translate
+const DefaultLocale = "en"
+
+// Text directions
+const (
+    LTR = "ltr"
+    RTL = "rtl"
+)
+
+//go:embed locales/*.json
+var catalogueFiles embed.FS
+
+// Locale is a message catalogue with the language's plural rule and date format
+type Locale struct {
+    Tag  string
+    Name string
+    // Dir is the text direction, ltr or rtl
+    Dir string
+    // Script names the writing system, which decides the font documents need
+    Script string
+
+    messages map[string]string
+    plural   func(n int) string
+    fallback *Locale
+}
+
+type catalogue struct {
+    Name     string            `json:"name"`
+    Dir      string            `json:"dir"`
+    Script   string            `json:"script"`
+    Messages map[string]string `json:"messages"`
+}
+
+var locales = make(map[string]*Locale)
+
+func init() {
+    entries, err := catalogueFiles.ReadDir("locales")
+    if err != nil {
+        panic(err)
+    }
+    for _, entry := range entries {
+        raw, err := catalogueFiles.ReadFile(path.Join("locales", entry.Name()))
+        if err != nil {
+            panic(err)
+        }
+        var c catalogue
+        if err := json.Unmarshal(raw, &c); err != nil {
+            panic(fmt.Sprintf("i18n: %s: %v", entry.Name(), err))
+        }
+        tag := strings.TrimSuffix(entry.Name(), ".json")
+        rule, ok := pluralRules[tag]
+        if !ok {
+            rule = pluralRules[DefaultLocale]
+        }
+        dir := c.Dir
+        if dir == "" {
+            dir = LTR
+        }
+        locales[tag] = &Locale{Tag: tag, Name: c.Name, Dir: dir, Script: c.Script, messages: c.Messages, plural: rule}
+    }
+    for tag, l := range locales {
+        if tag != DefaultLocale {
+            l.fallback = locales[DefaultLocale]
+        }
+    }
+}
+
+// Lookup returns the catalogue for a language tag such as "fr" or "fr-CA"
+func Lookup(tag string) (*Locale, bool) {
+    tag = strings.ToLower(strings.TrimSpace(tag))
+    if l, ok := locales[tag]; ok {
+        return l, true
+    }
+    base, _, _ := strings.Cut(tag, "-")
+    base, _, _ = strings.Cut(base, "_")
+    l, ok := locales[base]
+    return l, ok
+}
+
+// Default returns the English catalogue
+func Default() *Locale {
+    return locales[DefaultLocale]
+}
+
+// Tags lists the supported locales
+func Tags() []string {
+    tags := make([]string, 0, len(locales))
+    for tag := range locales {
+        tags = append(tags, tag)
+    }
+    sort.Strings(tags)
+    return tags
+}
+
+// Negotiate picks the best supported locale from an Accept-Language header,
+// honouring q-values, and falls back to def
+func Negotiate(acceptLanguage string, def *Locale) *Locale {
+    best, bestQ := def, 0.0
+    for _, part := range strings.Split(acceptLanguage, ",") {
+        tag, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+        q := 1.0
+        if v, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+            parsed, err := strconv.ParseFloat(v, 64)
+            if err != nil {
+                continue
+            }
+            q = parsed
+        }
+        if l, ok := Lookup(tag); ok && q > bestQ {
+            best, bestQ = l, q
+        }
+    }
+    return best
+}
+
+// T returns the message for key, formatted with args like fmt.Sprintf.
+// Messages missing from the catalogue come from English, and unknown keys
+// are returned as they are so a gap is visible rather than blank.
+func (l *Locale) T(key string, args ...interface{}) string {
+    msg, ok := l.lookup(key)
+    if !ok {
+        return key
+    }
+    if len(args) == 0 {
+        return msg
+    }
+    return fmt.Sprintf(msg, args...)
+}
+
+// N returns the plural form of key for n, e.g. N("trip.days", 3) reads
+// "trip.days.other" in English. Extra args follow n in the format. Forms
+// that spell the number out, like Arabic "يومان" for two days, have no verbs
+// and are returned as they are.
+func (l *Locale) N(key string, n int, args ...interface{}) string {
+    msg, ok := l.lookup(key + "." + l.plural(n))
+    if !ok {
+        msg, ok = l.lookup(key + ".other")
+    }
+    if !ok {
+        return key
+    }
+    if !strings.Contains(msg, "%") {
+        return msg
+    }
+    return fmt.Sprintf(msg, append([]interface{}{n}, args...)...)
+}
+
+func (l *Locale) lookup(key string) (string, bool) {
+    for c := l; c != nil; c = c.fallback {
+        if msg, ok := c.messages[key]; ok {
+            return msg, true
+        }
+    }
+    return "", false
+}
+
+// FormatDate prints a date the way the locale writes it, e.g. "10 Jan 2026"
+// or "10 janv. 2026"; an unset date prints as ""
+func (l *Locale) FormatDate(d datetime.Date) string {
+    if d.IsZero() {
+        return ""
+    }
+    return l.Date(d.Time(time.UTC))
+}
+
+// Date formats t with the locale's date pattern and month names
+func (l *Locale) Date(t time.Time) string {
+    month := l.T(fmt.Sprintf("month.%d", int(t.Month())))
+    return strings.NewReplacer(
+        "{day}", strconv.Itoa(t.Day()),
+        "{month}", month,
+        "{year}", strconv.Itoa(t.Year()),
+    ).Replace(l.T("date.format"))
+}
@@ -0,0 +1,63 @@
+package i18n
+
+import (
+    "testing"
+    "vigovia-pdf-api/money"
+)
+
+func TestLookup(t *testing.T) {
+    tests := []struct {
+        tag  string
+        want string
+    }{
+        {"fr", "fr"},
+        {" FR-ca ", "fr"},
+        {"hi_IN", "hi"},
+        {"de", ""},
+    }
+    for _, tt := range tests {
+        got := ""
+        if l, ok := Lookup(tt.tag); ok {
+            got = l.Tag
+        }
+        if got != tt.want {
+            t.Errorf("Lookup(%q) = %q, want %q", tt.tag, got, tt.want)
+        }
+    }
+}
+
+func TestNegotiate(t *testing.T) {
+    tests := []struct {
+        header string
+        want   string
+    }{
+        {"", "en"},
+        {"de-DE, fr;q=0.8, en;q=0.5", "fr"},
+        {"en;q=0.3, hi-IN;q=0.9", "hi"},
+        {"ar;q=bad, he", "he"},
+        {"de, es", "en"},
+    }
+    for _, tt := range tests {
+        if got := Negotiate(tt.header, Default()).Tag; got != tt.want {
+            t.Errorf("Negotiate(%q) = %s, want %s", tt.header, got, tt.want)
+        }
+    }
+}
+
+func TestM(t *testing.T) {
+    fr, _ := Lookup("fr")
+    // Text that isn't a key is printed as it is
+    if got := fr.M(Text("Goa")); got != "Goa" {
+        t.Errorf("text %q", got)
+    }
+    if got := fr.T("month.1"); got != "janv." {
+        t.Errorf("month.1 in French %q", got)
+    }
+    msg := Msg("payment.taxLine", Text("TCS"), money.New(123456, "EUR"))
+    if got := msg.String(); got != "TCS on €1,234.56" {
+        t.Errorf("English %q", got)
+    }
+    if got := fr.M(msg); got != "TCS sur 1\u00a0234,56\u00a0€" {
+        t.Errorf("French %q", got)
+    }
+}
@@ -0,0 +1,67 @@
+// i18n/message.go
+package i18n
+
+import (
+    "encoding/json"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/schema"
+)
+
+// Message is text worked out away from the renderer, such as a tax label or a
+// pricing warning: a catalogue key and its arguments, so each document prints
+// it in its own language. Arguments that are messages are translated too, and
+// amounts are formatted the language's way. In JSON a message is its English
+// text, as API responses have always carried.
+type Message struct {
+    Key  string
+    Args []interface{}
+}
+
+// Msg builds a message from a catalogue key and its arguments
+func Msg(key string, args ...interface{}) Message {
+    return Message{Key: key, Args: args}
+}
+
+// Text is a message printed as it is in every language, such as a name the
+// user gave. Text isn't a key in the catalogue, so T returns it unchanged.
+func Text(s string) Message {
+    return Message{Key: s}
+}
+
+// String is the message in English
+func (m Message) String() string {
+    return Default().M(m)
+}
+
+// M returns a message in the locale's language
+func (l *Locale) M(m Message) string {
+    args := make([]interface{}, len(m.Args))
+    for i, arg := range m.Args {
+        switch a := arg.(type) {
+        case Message:
+            arg = l.M(a)
+        case money.Money:
+            arg = money.Format(a, l.Tag)
+        }
+        args[i] = arg
+    }
+    return l.T(m.Key, args...)
+}
+
+func (m Message) MarshalJSON() ([]byte, error) {
+    return json.Marshal(m.String())
+}
+
+// UnmarshalJSON reads back the English text of a message as Text
+func (m *Message) UnmarshalJSON(raw []byte) error {
+    var text string
+    if err := json.Unmarshal(raw, &text); err != nil {
+        return err
+    }
+    *m = Text(text)
+    return nil
+}
+
+func (Message) JSONSchema() *schema.Schema {
+    return &schema.Schema{Type: "string"}
+}
@@ -0,0 +1,57 @@
+// i18n/plural.go
+package i18n
+
+// Plural categories from the Unicode CLDR plural rules
+const (
+    Zero  = "zero"
+    One   = "one"
+    Two   = "two"
+    Few   = "few"
+    Many  = "many"
+    Other = "other"
+)
+
+// pluralRules map a language to its CLDR cardinal rule for whole numbers
+var pluralRules = map[string]func(n int) string{
+    "en": func(n int) string {
+        if n == 1 {
+            return One
+        }
+        return Other
+    },
+    // French and Hindi treat zero as singular
+    "fr": zeroOrOne,
+    "hi": zeroOrOne,
+    "he": func(n int) string {
+        switch n {
+        case 1:
+            return One
+        case 2:
+            return Two
+        }
+        return Other
+    },
+    "ar": func(n int) string {
+        switch mod := n % 100; {
+        case n == 0:
+            return Zero
+        case n == 1:
+            return One
+        case n == 2:
+            return Two
+        case mod >= 3 && mod <= 10:
+            return Few
+        case mod >= 11 && mod <= 99:
+            return Many
+        default:
+            return Other
+        }
+    },
+}
+
+func zeroOrOne(n int) string {
+    if n == 0 || n == 1 {
+        return One
+    }
+    return Other
+}
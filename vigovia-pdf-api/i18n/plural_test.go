@@ -0,0 +1,61 @@
+package i18n
+
+import (
+    "testing"
+)
+
+func TestPluralRules(t *testing.T) {
+    tests := []struct {
+        lang string
+        want map[int]string
+    }{
+        {"en", map[int]string{0: Other, 1: One, 2: Other, 21: Other}},
+        {"fr", map[int]string{0: One, 1: One, 2: Other, 100: Other}},
+        {"hi", map[int]string{0: One, 1: One, 2: Other, 5: Other}},
+        {"he", map[int]string{0: Other, 1: One, 2: Two, 3: Other, 20: Other}},
+        {"ar", map[int]string{0: Zero, 1: One, 2: Two, 3: Few, 10: Few, 11: Many, 99: Many, 100: Other, 102: Other, 103: Few, 111: Many}},
+    }
+    for _, tt := range tests {
+        rule := pluralRules[tt.lang]
+        if rule == nil {
+            t.Errorf("no plural rule for %s", tt.lang)
+            continue
+        }
+        for n, want := range tt.want {
+            if got := rule(n); got != want {
+                t.Errorf("%s: %d is %q, want %q", tt.lang, n, got, want)
+            }
+        }
+    }
+}
+
+func TestN(t *testing.T) {
+    tests := []struct {
+        lang string
+        n    int
+        want string
+    }{
+        {"en", 1, "1 Day"},
+        {"en", 0, "0 Days"},
+        {"fr", 0, "0 jour"},
+        {"fr", 3, "3 jours"},
+        {"hi", 1, "1 दिन"},
+        {"he", 2, "יומיים"},
+        {"he", 5, "5 ימים"},
+        {"ar", 2, "يومان"},
+        {"ar", 4, "4 أيام"},
+        {"ar", 11, "11 يوماً"},
+    }
+    for _, tt := range tests {
+        l, ok := Lookup(tt.lang)
+        if !ok {
+            t.Fatalf("no %s catalogue", tt.lang)
+        }
+        if got := l.N("trip.days", tt.n); got != tt.want {
+            t.Errorf("%s N(trip.days, %d) = %q, want %q", tt.lang, tt.n, got, tt.want)
+        }
+    }
+    if got := Default().N("no.such.key", 2); got != "no.such.key" {
+        t.Errorf("unknown key gives %q", got)
+    }
+}
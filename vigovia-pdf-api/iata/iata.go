@@ -0,0 +1,259 @@
+// iata/iata.go
+package iata
+
+import (
+    "embed"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "regexp"
+    "strings"
+    "time"
+    "vigovia-pdf-api/datetime"
+)
+
+//go:embed data/*.csv
+var dataFiles embed.FS
+
+// Airport is an airport from the embedded reference data
+type Airport struct {
+    Code    string `json:"code"`
+    Name    string `json:"name"`
+    City    string `json:"city"`
+    Country string `json:"country" doc:"ISO 3166-1 alpha-2 code"`
+    Zone    string `json:"timeZone" doc:"IANA zone the airport's local times are in"`
+
+    location *time.Location
+}
+
+// Display is the airport as itineraries print it, e.g. Bengaluru (BLR)
+func (a Airport) Display() string {
+    return fmt.Sprintf("%s (%s)", a.City, a.Code)
+}
+
+// Location is the airport's time zone
+func (a Airport) Location() *time.Location {
+    return a.location
+}
+
+// Airline is an airline from the embedded reference data
+type Airline struct {
+    Code    string `json:"code"`
+    Name    string `json:"name"`
+    Country string `json:"country" doc:"ISO 3166-1 alpha-2 code"`
+}
+
+var (
+    airports     []Airport
+    airportCodes = map[string]int{}
+    airportIndex = newIndex("airport", "airfield", "aerodrome", "international", "intl")
+
+    airlines     []Airline
+    airlineCodes = map[string]int{}
+    airlineIndex = newIndex("airlines", "airline", "air lines", "airways")
+)
+
+func init() {
+    for _, row := range readData("airports.csv", 6) {
+        a := Airport{Code: row[0], Name: row[1], City: row[2], Country: row[3], Zone: row[4]}
+        loc, err := datetime.LoadZone(a.Zone)
+        if err != nil {
+            panic(fmt.Sprintf("iata: airport %s: %v", a.Code, err))
+        }
+        a.location = loc
+        i := len(airports)
+        airports = append(airports, a)
+        airportCodes[a.Code] = i
+        airportIndex.add(i, append([]string{a.Code, a.Name, a.City}, aliases(row[5])...)...)
+    }
+    for _, row := range readData("airlines.csv", 4) {
+        a := Airline{Code: row[0], Name: row[1], Country: row[2]}
+        i := len(airlines)
+        airlines = append(airlines, a)
+        airlineCodes[a.Code] = i
+        airlineIndex.add(i, append([]string{a.Code, a.Name}, aliases(row[3])...)...)
+    }
+}
+
+// readData reads an embedded CSV file, skipping its header row
+func readData(name string, fields int) [][]string {
+    f, err := dataFiles.Open("data/" + name)
+    if err != nil {
+        panic(err)
+    }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.Comment = '#'
+    r.FieldsPerRecord = fields
+    var rows [][]string
+    for header := true; ; header = false {
+        row, err := r.Read()
+        if err == io.EOF {
+            return rows
+        }
+        if err != nil {
+            panic(fmt.Sprintf("iata: %s: %v", name, err))
+        }
+        if !header {
+            rows = append(rows, row)
+        }
+    }
+}
+
+func aliases(field string) []string {
+    if field == "" {
+        return nil
+    }
+    return strings.Split(field, "|")
+}
+
+var (
+    airportCode   = regexp.MustCompile(`^[A-Za-z]{3}$`)
+    bracketedCode = regexp.MustCompile(`\(\s*([A-Za-z]{3})\s*\)`)
+    capitalCode   = regexp.MustCompile(`\b[A-Z]{3}\b`)
+    airlineCode   = regexp.MustCompile(`^[A-Za-z0-9]{2}$`)
+)
+
+// LookupAirport finds an airport by its IATA code, in any case
+func LookupAirport(code string) (Airport, bool) {
+    i, ok := airportCodes[strings.ToUpper(strings.TrimSpace(code))]
+    if !ok {
+        return Airport{}, false
+    }
+    return airports[i], true
+}
+
+// FindAirport reads an airport from free text: a code such as BLR, a city
+// such as Bangalore, an airport name such as Kempegowda, or any of those
+// with the code in brackets, e.g. "Bengaluru (BLR)". It fails when the text
+// names no airport or a city with several, such as London.
+func FindAirport(text string) (Airport, bool) {
+    if m := bracketedCode.FindStringSubmatch(text); m != nil {
+        if a, ok := LookupAirport(m[1]); ok {
+            return a, true
+        }
+    }
+    matches := findAirports(text)
+    if len(matches) != 1 {
+        return Airport{}, false
+    }
+    return airports[matches[0]], true
+}
+
+// findAirports returns every airport the text could name
+func findAirports(text string) []int {
+    text = strings.TrimSpace(text)
+    // A code in capitals is taken as a code before a city of the same
+    // letters; in other cases Goa is the city, not an airport code
+    if airportCode.MatchString(text) && text == strings.ToUpper(text) {
+        if i, ok := airportCodes[text]; ok {
+            return []int{i}
+        }
+    }
+    if matches := airportIndex.find(text); matches != nil {
+        return matches
+    }
+    // A code in capitals beside other words, e.g. "Paris CDG" or "DEL T3"
+    var found []int
+    for _, code := range capitalCode.FindAllString(text, -1) {
+        if i, ok := airportCodes[code]; ok && !containsInt(found, i) {
+            found = append(found, i)
+        }
+    }
+    return found
+}
+
+// AirportZone is the time zone of the airport the text names, or nil. A city
+// with several airports has a zone when they all share it.
+func AirportZone(text string) *time.Location {
+    if a, ok := FindAirport(text); ok {
+        return a.location
+    }
+    var zone *time.Location
+    for _, i := range findAirports(text) {
+        if zone != nil && airports[i].location != zone {
+            return nil
+        }
+        zone = airports[i].location
+    }
+    return zone
+}
+
+// AirportCountry is the country of the airport the text names, or "". A city
+// with several airports has a country when they are all in it.
+func AirportCountry(text string) string {
+    if a, ok := FindAirport(text); ok {
+        return a.Country
+    }
+    country := ""
+    for _, i := range findAirports(text) {
+        if country != "" && airports[i].Country != country {
+            return ""
+        }
+        country = airports[i].Country
+    }
+    return country
+}
+
+// AirportName is the text as documents print it: "Bengaluru (BLR)" for a
+// known airport, otherwise the text unchanged
+func AirportName(text string) string {
+    if a, ok := FindAirport(text); ok {
+        return a.Display()
+    }
+    return strings.TrimSpace(text)
+}
+
+// SuggestAirports returns up to n airports the text may be a misspelling or
+// the start of, closest first
+func SuggestAirports(text string, n int) []Airport {
+    var out []Airport
+    for _, i := range airportIndex.suggest(text, n) {
+        out = append(out, airports[i])
+    }
+    return out
+}
+
+// LookupAirline finds an airline by its two character IATA code
+func LookupAirline(code string) (Airline, bool) {
+    i, ok := airlineCodes[strings.ToUpper(strings.TrimSpace(code))]
+    if !ok {
+        return Airline{}, false
+    }
+    return airlines[i], true
+}
+
+// FindAirline reads an airline from a code such as EK or a name such as
+// Emirates or "Fly Emirates"
+func FindAirline(text string) (Airline, bool) {
+    text = strings.TrimSpace(text)
+    if airlineCode.MatchString(text) {
+        if a, ok := LookupAirline(text); ok {
+            return a, true
+        }
+    }
+    matches := airlineIndex.find(text)
+    if len(matches) != 1 {
+        return Airline{}, false
+    }
+    return airlines[matches[0]], true
+}
+
+// AirlineName is the airline's name for a known code or name, otherwise the
+// text unchanged
+func AirlineName(text string) string {
+    if a, ok := FindAirline(text); ok {
+        return a.Name
+    }
+    return strings.TrimSpace(text)
+}
+
+// SuggestAirlines returns up to n airlines the text may be a misspelling or
+// the start of, closest first
+func SuggestAirlines(text string, n int) []Airline {
+    var out []Airline
+    for _, i := range airlineIndex.suggest(text, n) {
+        out = append(out, airlines[i])
+    }
+    return out
+}
@@ -0,0 +1,215 @@
+// iata/search.go
+package iata
+
+import (
+    "sort"
+    "strings"
+    "unicode"
+)
+
+// index maps normalised names to the entries they name. Names are indexed
+// as written and without generic words such as "International Airport", so
+// "Dubai International" finds DXB while "Dubai" finds both of its airports.
+// Generic words are dropped in the order given, most generic first.
+type index struct {
+    generic []string
+    keys    map[string][]int
+    // order lists keys as added, so suggestions are stable
+    order []string
+}
+
+func newIndex(generic ...string) *index {
+    return &index{generic: generic, keys: map[string][]int{}}
+}
+
+func (x *index) add(entry int, names ...string) {
+    for _, name := range names {
+        for _, key := range x.variants(name) {
+            if containsInt(x.keys[key], entry) {
+                continue
+            }
+            if _, ok := x.keys[key]; !ok {
+                x.order = append(x.order, key)
+            }
+            x.keys[key] = append(x.keys[key], entry)
+        }
+    }
+}
+
+// find returns the entries the text names, trying the whole text, then each
+// part of it between commas, e.g. "Kempegowda Airport, Bangalore", then the
+// entries every name among its words agrees on, e.g. "Bengaluru Kempegowda"
+func (x *index) find(text string) []int {
+    parts := append([]string{text}, strings.Split(text, ",")...)
+    for _, part := range parts {
+        for _, key := range x.variants(part) {
+            if entries, ok := x.keys[key]; ok {
+                return entries
+            }
+        }
+    }
+    var common []int
+    matched := false
+    for _, word := range strings.Fields(normalise(text)) {
+        entries, ok := x.keys[word]
+        if !ok {
+            continue
+        }
+        if !matched {
+            common, matched = entries, true
+            continue
+        }
+        var both []int
+        for _, e := range common {
+            if containsInt(entries, e) {
+                both = append(both, e)
+            }
+        }
+        common = both
+    }
+    return common
+}
+
+// suggest ranks entries by how close their names are to the text: names the
+// text starts, then names within a few typos, closest first
+func (x *index) suggest(text string, n int) []int {
+    variants := x.variants(text)
+    if len(variants) == 0 || n <= 0 {
+        return nil
+    }
+    query := variants[len(variants)-1]
+    limit := 1 + len([]rune(query))/4
+    if limit > 3 {
+        limit = 3
+    }
+
+    type candidate struct{ entry, score, rank int }
+    best := map[int]candidate{}
+    for rank, key := range x.order {
+        score := -1
+        switch {
+        case key == query:
+            score = 0
+        case len(query) >= 2 && strings.HasPrefix(key, query):
+            score = 1
+        default:
+            if d := distance(query, key, limit); d <= limit {
+                score = 1 + d
+            }
+        }
+        if score < 0 {
+            continue
+        }
+        for _, entry := range x.keys[key] {
+            if c, ok := best[entry]; !ok || score < c.score {
+                best[entry] = candidate{entry, score, rank}
+            }
+        }
+    }
+    ranked := make([]candidate, 0, len(best))
+    for _, c := range best {
+        ranked = append(ranked, c)
+    }
+    sort.Slice(ranked, func(i, j int) bool {
+        if ranked[i].score != ranked[j].score {
+            return ranked[i].score < ranked[j].score
+        }
+        return ranked[i].rank < ranked[j].rank
+    })
+    if len(ranked) > n {
+        ranked = ranked[:n]
+    }
+    out := make([]int, len(ranked))
+    for i, c := range ranked {
+        out[i] = c.entry
+    }
+    return out
+}
+
+// variants are the keys a name is indexed and looked up by: as written, then
+// with each generic word dropped in turn, so "Dubai International Airport"
+// is also "dubai international" and "dubai"
+func (x *index) variants(name string) []string {
+    full := normalise(name)
+    if full == "" {
+        return nil
+    }
+    out := []string{full}
+    words := " " + full + " "
+    for _, g := range x.generic {
+        next := strings.ReplaceAll(words, " "+g+" ", " ")
+        if next == words {
+            continue
+        }
+        words = next
+        if key := strings.Join(strings.Fields(words), " "); key != "" {
+            out = append(out, key)
+        }
+    }
+    return out
+}
+
+// folds maps accented letters to the ones people type
+var folds = strings.NewReplacer(
+    "á", "a", "à", "a", "â", "a", "ä", "a", "ã", "a", "å", "a", "ā", "a",
+    "é", "e", "è", "e", "ê", "e", "ë", "e", "ē", "e",
+    "í", "i", "ì", "i", "î", "i", "ï", "i", "ī", "i",
+    "ó", "o", "ò", "o", "ô", "o", "ö", "o", "õ", "o", "ø", "o", "ō", "o",
+    "ú", "u", "ù", "u", "û", "u", "ü", "u", "ū", "u",
+    "ç", "c", "č", "c", "ñ", "n", "ş", "s", "š", "s", "ğ", "g", "ž", "z", "ß", "ss",
+    "'", "", "’", "", ".", "",
+)
+
+// normalise lowercases a name, folds accents and reduces punctuation to
+// single spaces
+func normalise(s string) string {
+    s = folds.Replace(strings.ToLower(s))
+    return strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+        return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+    }), " ")
+}
+
+// distance is the optimal string alignment distance between a and b: the
+// edits, counting swapped neighbours as one, that turn one into the other.
+// It stops counting once every alignment exceeds limit.
+func distance(a, b string, limit int) int {
+    ra, rb := []rune(a), []rune(b)
+    if d := len(ra) - len(rb); d > limit || -d > limit {
+        return limit + 1
+    }
+    prev2 := make([]int, len(rb)+1)
+    prev := make([]int, len(rb)+1)
+    cur := make([]int, len(rb)+1)
+    for j := range prev {
+        prev[j] = j
+    }
+    for i := 1; i <= len(ra); i++ {
+        cur[0] = i
+        rowMin := cur[0]
+        for j := 1; j <= len(rb); j++ {
+            cost := 1
+            if ra[i-1] == rb[j-1] {
+                cost = 0
+            }
+            cur[j] = min(prev[j]+1, cur[j-1]+1, prev[j-1]+cost)
+            if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+                cur[j] = min(cur[j], prev2[j-2]+1)
+            }
+            rowMin = min(rowMin, cur[j])
+        }
+        if rowMin > limit {
+            return limit + 1
+        }
+        prev2, prev, cur = prev, cur, prev2
+    }
+    return prev[len(rb)]
+}
+
+func containsInt(list []int, v int) bool {
+    for _, x := range list {
+        if x == v {
+            return true
+        }
+    }
+    return false
+}
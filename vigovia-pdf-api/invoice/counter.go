@@ -0,0 +1,178 @@
+// invoice/counter.go
+package invoice
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+    "vigovia-pdf-api/datetime"
+)
+
+// Counters hands out sequential document numbers per series. With a path the
+// counters are written to a JSON file before a number is returned, so
+// numbering carries on where it left off after a restart.
+type Counters struct {
+    mu     sync.Mutex
+    path   string
+    values map[string]int64
+
+    // issuing serialises Issue, so a peeked number is still free once the
+    // document has rendered
+    issuing sync.Mutex
+}
+
+// OpenCounters loads the counters stored at path; a missing file starts every
+// series at zero and an empty path keeps the counters in memory only
+func OpenCounters(path string) (*Counters, error) {
+    c := &Counters{path: path, values: make(map[string]int64)}
+    if path == "" {
+        return c, nil
+    }
+
+    raw, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return c, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("reading invoice counters: %w", err)
+    }
+    if err := json.Unmarshal(raw, &c.values); err != nil {
+        return nil, fmt.Errorf("parsing invoice counters %s: %w", path, err)
+    }
+    return c, nil
+}
+
+// Next returns the next number in a series, or ErrSeriesExhausted once the
+// series has handed out max
+func (c *Counters) Next(series string, max int64) (int64, error) {
+    c.mu.Lock()
+    defer c.mu.Unlock()
+
+    next := c.values[series] + 1
+    if next > max {
+        return 0, ErrSeriesExhausted
+    }
+    c.values[series] = next
+    if err := c.save(); err != nil {
+        c.values[series] = next - 1
+        return 0, err
+    }
+    return next, nil
+}
+
+// save writes the counters, when they are kept in a file
+func (c *Counters) save() error {
+    if c.path == "" {
+        return nil
+    }
+    if err := writeJSON(c.path, c.values); err != nil {
+        return fmt.Errorf("saving invoice counters: %w", err)
+    }
+    return nil
+}
+
+// writeJSON writes v to a temporary file and renames it over path so a crash
+// never leaves a half written file behind
+func writeJSON(path string, v interface{}) error {
+    raw, err := json.MarshalIndent(v, "", "  ")
+    if err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*")
+    if err != nil {
+        return err
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(raw); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Sync(); err != nil {
+        tmp.Close()
+        return err
+    }
+    if err := tmp.Close(); err != nil {
+        return err
+    }
+    return os.Rename(tmp.Name(), path)
+}
+
+// FinancialYear returns the Indian financial year (April to March) a date
+// falls in, written the way invoice series use it, e.g. "26-27"
+func FinancialYear(d datetime.Date) string {
+    start := d.Year()
+    if d.Month() < time.April {
+        start--
+    }
+    return fmt.Sprintf("%02d-%02d", start%100, (start+1)%100)
+}
+
+// MaxPrefix and MaxSequence bound a document number: GST rules allow at most
+// 16 characters, unique within the financial year, and PPPP/YY-YY/NNNNN is 16
+const (
+    MaxPrefix   = 4
+    MaxSequence = 99999
+)
+
+// ErrSeriesExhausted is returned once a series has used every number of its
+// financial year
+var ErrSeriesExhausted = errors.New("invoice: the series has no numbers left this financial year")
+
+// Number formats a document number such as INV/26-27/00042
+func Number(prefix, financialYear string, n int64) (string, error) {
+    if len(prefix) > MaxPrefix {
+        return "", fmt.Errorf("invoice: prefix %q is longer than %d characters", prefix, MaxPrefix)
+    }
+    if n > MaxSequence {
+        return "", ErrSeriesExhausted
+    }
+    return fmt.Sprintf("%s/%s/%05d", prefix, financialYear, n), nil
+}
+
+// PeekNumber returns the number NextNumber would allocate, without taking it
+func (c *Counters) PeekNumber(prefix string, date datetime.Date) (string, error) {
+    fy := FinancialYear(date)
+    c.mu.Lock()
+    n := c.values[prefix+"/"+fy] + 1
+    c.mu.Unlock()
+    return Number(prefix, fy, n)
+}
+
+// NextNumber allocates the next number of a prefix in the financial year of date
+func (c *Counters) NextNumber(prefix string, date datetime.Date) (string, error) {
+    fy := FinancialYear(date)
+    n, err := c.Next(prefix+"/"+fy, MaxSequence)
+    if err != nil {
+        return "", err
+    }
+    return Number(prefix, fy, n)
+}
+
+// Issue numbers a document rendered by render. The number is only taken once
+// the render succeeds, so a failed render leaves no gap; documents are issued
+// one at a time so nothing else takes it meanwhile.
+func (c *Counters) Issue(prefix string, date datetime.Date, render func(number string) error) (string, error) {
+    c.issuing.Lock()
+    defer c.issuing.Unlock()
+
+    number, err := c.PeekNumber(prefix, date)
+    if err != nil {
+        return "", err
+    }
+    if err := render(number); err != nil {
+        return "", err
+    }
+    taken, err := c.NextNumber(prefix, date)
+    if err != nil {
+        return "", err
+    }
+    if taken != number {
+        return "", fmt.Errorf("invoice: number %s was taken while %s was rendered", taken, number)
+    }
+    return number, nil
+}
@@ -0,0 +1,124 @@
+package invoice
+
+import (
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+    "vigovia-pdf-api/datetime"
+)
+
+func TestFinancialYear(t *testing.T) {
+    tests := []struct {
+        date datetime.Date
+        want string
+    }{
+        {datetime.NewDate(2026, time.March, 31), "25-26"},
+        {datetime.NewDate(2026, time.April, 1), "26-27"},
+        {datetime.NewDate(2026, time.December, 31), "26-27"},
+        {datetime.NewDate(2099, time.May, 1), "99-00"},
+    }
+    for _, tt := range tests {
+        if got := FinancialYear(tt.date); got != tt.want {
+            t.Errorf("FinancialYear(%s) = %q, want %q", tt.date, got, tt.want)
+        }
+    }
+}
+
+func TestNumber(t *testing.T) {
+    if got, err := Number("INV", "26-27", 42); err != nil || got != "INV/26-27/00042" {
+        t.Errorf("Number = %q, %v", got, err)
+    }
+    if _, err := Number("INVOI", "26-27", 1); err == nil {
+        t.Error("no error for a five character prefix")
+    }
+    if _, err := Number("INV", "26-27", MaxSequence+1); !errors.Is(err, ErrSeriesExhausted) {
+        t.Errorf("error %v past the last number", err)
+    }
+}
+
+func TestCounters(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "counters.json")
+    c, err := OpenCounters(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    march, april := datetime.NewDate(2027, time.March, 31), datetime.NewDate(2027, time.April, 1)
+
+    // Peeking doesn't take the number
+    for i := 0; i < 2; i++ {
+        if got, err := c.PeekNumber("INV", march); err != nil || got != "INV/26-27/00001" {
+            t.Fatalf("PeekNumber = %q, %v", got, err)
+        }
+    }
+    steps := []struct {
+        prefix string
+        date   datetime.Date
+        want   string
+    }{
+        {"INV", march, "INV/26-27/00001"},
+        {"INV", march, "INV/26-27/00002"},
+        {"RCT", march, "RCT/26-27/00001"},
+        // A new financial year starts the series again
+        {"INV", april, "INV/27-28/00001"},
+    }
+    for _, step := range steps {
+        if got, err := c.NextNumber(step.prefix, step.date); err != nil || got != step.want {
+            t.Fatalf("NextNumber(%s, %s) = %q, %v; want %q", step.prefix, step.date, got, err, step.want)
+        }
+    }
+
+    // Numbering carries on after a restart
+    reopened, err := OpenCounters(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if got, err := reopened.NextNumber("INV", march); err != nil || got != "INV/26-27/00003" {
+        t.Errorf("after reopening NextNumber = %q, %v", got, err)
+    }
+}
+
+func TestCountersExhausted(t *testing.T) {
+    c, _ := OpenCounters("")
+    for i := 0; i < 2; i++ {
+        if _, err := c.Next("small", 2); err != nil {
+            t.Fatal(err)
+        }
+    }
+    if _, err := c.Next("small", 2); !errors.Is(err, ErrSeriesExhausted) {
+        t.Errorf("error %v once the series is used up", err)
+    }
+}
+
+func TestCountersUnreadable(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "counters.json")
+    if err := os.WriteFile(path, []byte("[1, 2]"), 0o644); err != nil {
+        t.Fatal(err)
+    }
+    if _, err := OpenCounters(path); err == nil {
+        t.Error("no error for a corrupt counters file")
+    }
+}
+
+func TestCountersIssue(t *testing.T) {
+    c, _ := OpenCounters("")
+    date := datetime.NewDate(2026, time.October, 19)
+
+    // A failed render leaves no gap
+    failed := errors.New("render failed")
+    if _, err := c.Issue("RCT", date, func(number string) error { return failed }); !errors.Is(err, failed) {
+        t.Fatalf("error %v, want the render's", err)
+    }
+    rendered := ""
+    number, err := c.Issue("RCT", date, func(number string) error {
+        rendered = number
+        return nil
+    })
+    if err != nil || number != "RCT/26-27/00001" || rendered != number {
+        t.Errorf("Issue = %q, %v; rendered %q", number, err, rendered)
+    }
+    if next, _ := c.PeekNumber("RCT", date); next != "RCT/26-27/00002" {
+        t.Errorf("next number %q", next)
+    }
+}
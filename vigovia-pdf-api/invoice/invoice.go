@@ -0,0 +1,139 @@
+// invoice/invoice.go
+package invoice
+
+import (
+    "errors"
+    "fmt"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/tax"
+    "vigovia-pdf-api/types"
+)
+
+// SACTourOperator is the services accounting code for tour operator services
+const SACTourOperator = "998555"
+
+// Item is one line of an invoice
+type Item struct {
+    Description string      `json:"description"`
+    SAC         string      `json:"sac"`
+    Quantity    int         `json:"quantity"`
+    Taxable     money.Money `json:"taxable"`
+}
+
+// Invoice is a tax invoice for a package, ready to be rendered
+type Invoice struct {
+    Number        string                     `json:"number"`
+    Date          datetime.Date              `json:"date"`
+    Seller        types.Party                `json:"seller"`
+    Buyer         types.Party                `json:"buyer"`
+    PlaceOfSupply string                     `json:"placeOfSupply"`
+    Items         []Item                     `json:"items"`
+    Tax           *tax.Result                `json:"tax"`
+    Installments  []types.PaymentInstallment `json:"installments,omitempty"`
+}
+
+// Receipt acknowledges a payment against one installment of a payment plan
+type Receipt struct {
+    Number        string                   `json:"number"`
+    Date          datetime.Date            `json:"date"`
+    InvoiceNumber string                   `json:"invoiceNumber,omitempty"`
+    Seller        types.Party              `json:"seller"`
+    ReceivedFrom  string                   `json:"receivedFrom"`
+    Trip          string                   `json:"trip"`
+    Installment   types.PaymentInstallment `json:"installment"`
+    Position      int                      `json:"position"`
+    Count         int                      `json:"count"`
+    Amount        money.Money              `json:"amount"`
+    Method        string                   `json:"method,omitempty"`
+    Reference     string                   `json:"reference,omitempty"`
+}
+
+// NewInvoice builds the invoice for an itinerary's payment plan. Only a plan
+// with tax inputs can be invoiced, as its total is then the taxable value (see
+// tax.TotalIsPreTax); the supplier's and recipient's states fill in the tax
+// inputs the plan leaves empty.
+func NewInvoice(data types.ItineraryData, seller, buyer types.Party, sac string) (*Invoice, error) {
+    if seller.GSTIN == "" {
+        return nil, errors.New("invoice: the supplier has no GSTIN")
+    }
+    if data.PaymentPlan.TotalAmount.IsZero() {
+        return nil, errors.New("invoice: the payment plan has no total amount")
+    }
+    if sac == "" {
+        sac = SACTourOperator
+    }
+
+    if !tax.TotalIsPreTax(data.PaymentPlan) {
+        return nil, errors.New("invoice: the payment plan has no tax inputs, so its total amount already includes GST and can't be split into taxable value and tax; add paymentPlan.tax")
+    }
+    in := *data.PaymentPlan.Tax
+    if in.SupplierState == "" {
+        in.SupplierState = seller.State
+    }
+    if in.PlaceOfSupply == "" {
+        in.PlaceOfSupply = buyer.State
+    }
+    if in.PlaceOfSupply == "" {
+        in.PlaceOfSupply = seller.State
+    }
+    if in.PAN == "" {
+        in.PAN = buyer.PAN
+    }
+
+    result, err := tax.Compute(data.PaymentPlan.TotalAmount, in, tax.DefaultRules())
+    if err != nil {
+        return nil, err
+    }
+
+    trip := data.TripDetails
+    return &Invoice{
+        Seller:        seller,
+        Buyer:         buyer,
+        PlaceOfSupply: in.PlaceOfSupply,
+        Items: []Item{{
+            Description: fmt.Sprintf("Tour package: %s, %d Days %d Nights", trip.Destination, trip.Days, trip.Nights),
+            SAC:         sac,
+            Quantity:    trip.NumberOfTravelers,
+            Taxable:     data.PaymentPlan.TotalAmount,
+        }},
+        Tax:          result,
+        Installments: data.PaymentPlan.Installments,
+    }, nil
+}
+
+// NewReceipt builds a receipt for the installment with the given ID. A zero
+// amount means the installment was paid in full; more than the installment
+// is refused.
+func NewReceipt(data types.ItineraryData, seller types.Party, installmentID string, amount money.Money) (*Receipt, error) {
+    installments := data.PaymentPlan.Installments
+    for i, installment := range installments {
+        if installment.ID != installmentID {
+            continue
+        }
+        if amount.IsZero() {
+            amount = installment.Amount
+        }
+        if amount.MinorUnits < 0 {
+            return nil, errors.New("invoice: the amount received must be positive")
+        }
+        if amount.Currency != installment.Amount.Currency {
+            return nil, fmt.Errorf("invoice: amount received is in %s but the installment is in %s", amount.Currency, installment.Amount.Currency)
+        }
+        if amount.MinorUnits > installment.Amount.MinorUnits {
+            return nil, fmt.Errorf("invoice: amount received %s is more than the installment's %s", amount, installment.Amount)
+        }
+
+        trip := data.TripDetails
+        return &Receipt{
+            Seller:       seller,
+            ReceivedFrom: trip.CustomerName,
+            Trip:         fmt.Sprintf("%s, %d Days %d Nights", trip.Destination, trip.Days, trip.Nights),
+            Installment:  installment,
+            Position:     i + 1,
+            Count:        len(installments),
+            Amount:       amount,
+        }, nil
+    }
+    return nil, fmt.Errorf("invoice: the payment plan has no installment %q", installmentID)
+}
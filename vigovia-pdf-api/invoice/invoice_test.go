@@ -0,0 +1,50 @@
+package invoice
+
+import (
+    "strings"
+    "testing"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+func TestNewReceipt(t *testing.T) {
+    data := types.ItineraryData{
+        TripDetails: types.TripDetails{CustomerName: "Asha Rao", Destination: "Goa", Days: 3, Nights: 2},
+        PaymentPlan: types.PaymentPlan{Installments: []types.PaymentInstallment{
+            {ID: "deposit", Amount: money.FromMajor(20000, "INR")},
+            {ID: "balance", Amount: money.FromMajor(80000, "INR")},
+        }},
+    }
+    tests := []struct {
+        name        string
+        installment string
+        amount      money.Money
+        want        money.Money
+        err         string
+    }{
+        {"paid in full", "balance", money.Money{}, money.FromMajor(80000, "INR"), ""},
+        {"part payment", "balance", money.FromMajor(30000, "INR"), money.FromMajor(30000, "INR"), ""},
+        {"more than the installment", "deposit", money.FromMajor(20001, "INR"), money.Money{}, "more than the installment's ₹20,000"},
+        {"negative", "deposit", money.FromMajor(-1, "INR"), money.Money{}, "must be positive"},
+        {"other currency", "deposit", money.FromMajor(200, "USD"), money.Money{}, "in USD"},
+        {"unknown installment", "final", money.Money{}, money.Money{}, `no installment "final"`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            receipt, err := NewReceipt(data, types.Party{Name: "Vigovia"}, tt.installment, tt.amount)
+            if tt.err != "" {
+                if err == nil || !strings.Contains(err.Error(), tt.err) {
+                    t.Fatalf("error %v, want one mentioning %q", err, tt.err)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            if receipt.Amount != tt.want || receipt.Position != 2 || receipt.Count != 2 ||
+                receipt.ReceivedFrom != "Asha Rao" || receipt.Trip != "Goa, 3 Days 2 Nights" {
+                t.Errorf("receipt %+v", receipt)
+            }
+        })
+    }
+}
@@ -0,0 +1,115 @@
+// invoice/ledger.go
+package invoice
+
+import (
+    "crypto/sha256"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+    "vigovia-pdf-api/datetime"
+)
+
+// Issued is an invoice in the ledger, with the key of what it was issued for
+type Issued struct {
+    Key     string   `json:"key"`
+    Invoice *Invoice `json:"invoice"`
+}
+
+// Ledger keeps the invoices issued with their numbers. An invoice asked for
+// again, the same supply to the same buyer, is reprinted under its number
+// rather than issued twice. With a path the ledger is kept in a JSON file.
+type Ledger struct {
+    mu       sync.Mutex
+    path     string
+    counters *Counters
+    issued   []Issued
+    byKey    map[string]*Invoice
+}
+
+// OpenLedger loads the invoices stored at path, numbering new ones from
+// counters; a missing file starts an empty ledger and an empty path keeps it
+// in memory only
+func OpenLedger(path string, counters *Counters) (*Ledger, error) {
+    l := &Ledger{path: path, counters: counters, byKey: make(map[string]*Invoice)}
+    if path == "" {
+        return l, nil
+    }
+
+    raw, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return l, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("reading issued invoices: %w", err)
+    }
+    if err := json.Unmarshal(raw, &l.issued); err != nil {
+        return nil, fmt.Errorf("parsing issued invoices %s: %w", path, err)
+    }
+    for _, issued := range l.issued {
+        l.byKey[issued.Key] = issued.Invoice
+    }
+    return l, nil
+}
+
+// Issue numbers and dates an invoice, renders it and records it. The number
+// is only taken once the render succeeds, so a failed render leaves no gap;
+// see Counters.Issue. An
+// invoice issued before is rendered as stored, under its number and date,
+// and reprint is true.
+func (l *Ledger) Issue(inv *Invoice, prefix string, date datetime.Date, render func(*Invoice) error) (issued *Invoice, reprint bool, err error) {
+    key, err := contentKey(inv)
+    if err != nil {
+        return nil, false, err
+    }
+
+    l.mu.Lock()
+    defer l.mu.Unlock()
+
+    if stored, ok := l.byKey[key]; ok {
+        return stored, true, render(stored)
+    }
+
+    // The number is taken before the invoice is stored: should storing fail
+    // the number is lost, leaving a gap rather than a duplicate
+    numbered := *inv
+    if _, err := l.counters.Issue(prefix, date, func(number string) error {
+        numbered.Number, numbered.Date = number, date
+        return render(&numbered)
+    }); err != nil {
+        return nil, false, err
+    }
+    l.issued = append(l.issued, Issued{Key: key, Invoice: &numbered})
+    if err := l.save(); err != nil {
+        l.issued = l.issued[:len(l.issued)-1]
+        return nil, false, err
+    }
+    l.byKey[key] = &numbered
+    return &numbered, false, nil
+}
+
+// save writes the ledger, when it is kept in a file
+func (l *Ledger) save() error {
+    if l.path == "" {
+        return nil
+    }
+    if err := writeJSON(l.path, l.issued); err != nil {
+        return fmt.Errorf("saving issued invoices: %w", err)
+    }
+    return nil
+}
+
+// contentKey identifies what an invoice is for: everything on it but its
+// number and date
+func contentKey(inv *Invoice) (string, error) {
+    content := *inv
+    content.Number, content.Date = "", datetime.Date{}
+    raw, err := json.Marshal(content)
+    if err != nil {
+        return "", err
+    }
+    sum := sha256.Sum256(raw)
+    return hex.EncodeToString(sum[:]), nil
+}
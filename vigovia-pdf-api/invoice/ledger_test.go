@@ -0,0 +1,74 @@
+package invoice
+
+import (
+    "errors"
+    "path/filepath"
+    "testing"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+func testInvoice(buyer string) *Invoice {
+    return &Invoice{
+        Seller: types.Party{Name: "Vigovia", GSTIN: "29ABCDE1234F1Z5", State: "KA"},
+        Buyer:  types.Party{Name: buyer, State: "KA"},
+        Items:  []Item{{Description: "Tour package: Goa, 3 Days 2 Nights", SAC: SACTourOperator, Quantity: 2, Taxable: money.FromMajor(50000, "INR")}},
+    }
+}
+
+func TestLedgerIssue(t *testing.T) {
+    dir := t.TempDir()
+    counters, _ := OpenCounters(filepath.Join(dir, "counters.json"))
+    ledger, err := OpenLedger(filepath.Join(dir, "invoices.json"), counters)
+    if err != nil {
+        t.Fatal(err)
+    }
+    october, november := datetime.NewDate(2026, time.October, 19), datetime.NewDate(2026, time.November, 2)
+    render := func(*Invoice) error { return nil }
+
+    first, reprint, err := ledger.Issue(testInvoice("Asha Rao"), "INV", october, render)
+    if err != nil || reprint || first.Number != "INV/26-27/00001" || !first.Date.Equal(october) {
+        t.Fatalf("first invoice %s of %s, reprint %v, %v", first.Number, first.Date, reprint, err)
+    }
+    second, _, err := ledger.Issue(testInvoice("Ravi Menon"), "INV", october, render)
+    if err != nil || second.Number != "INV/26-27/00002" {
+        t.Fatalf("second invoice %s, %v", second.Number, err)
+    }
+
+    // The same supply to the same buyer is reprinted with its number and date
+    var rendered *Invoice
+    again, reprint, err := ledger.Issue(testInvoice("Asha Rao"), "INV", november, func(inv *Invoice) error {
+        rendered = inv
+        return nil
+    })
+    if err != nil || !reprint || again.Number != first.Number || !again.Date.Equal(october) || rendered != again {
+        t.Errorf("reissued %s of %s, reprint %v, %v", again.Number, again.Date, reprint, err)
+    }
+
+    // A failed render takes no number
+    failed := errors.New("render failed")
+    if _, _, err := ledger.Issue(testInvoice("Meera Iyer"), "INV", october, func(*Invoice) error { return failed }); !errors.Is(err, failed) {
+        t.Fatalf("error %v, want the render's", err)
+    }
+    third, _, err := ledger.Issue(testInvoice("Meera Iyer"), "INV", october, render)
+    if err != nil || third.Number != "INV/26-27/00003" {
+        t.Errorf("after a failed render %s, %v", third.Number, err)
+    }
+
+    // Both the ledger and the counters survive a restart
+    counters, _ = OpenCounters(filepath.Join(dir, "counters.json"))
+    reopened, err := OpenLedger(filepath.Join(dir, "invoices.json"), counters)
+    if err != nil {
+        t.Fatal(err)
+    }
+    stored, reprint, err := reopened.Issue(testInvoice("Ravi Menon"), "INV", november, render)
+    if err != nil || !reprint || stored.Number != second.Number {
+        t.Errorf("after reopening %s, reprint %v, %v", stored.Number, reprint, err)
+    }
+    fourth, _, err := reopened.Issue(testInvoice("Kiran Das"), "INV", november, render)
+    if err != nil || fourth.Number != "INV/26-27/00004" {
+        t.Errorf("after reopening a new invoice is %s, %v", fourth.Number, err)
+    }
+}
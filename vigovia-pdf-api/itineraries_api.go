@@ -0,0 +1,263 @@
+package main
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "net/http"
+    "net/url"
+    "strings"
+    "time"
+    "vigovia-pdf-api/auth"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/metrics"
+    "vigovia-pdf-api/payments"
+    "vigovia-pdf-api/store"
+    "vigovia-pdf-api/tracing"
+    "vigovia-pdf-api/types"
+    "vigovia-pdf-api/utils"
+    "github.com/gorilla/mux"
+)
+
+// itineraries holds stored itineraries and the payments made against them
+var itineraries *store.Store
+
+// paymentsBody is a stored itinerary's payments and where they leave the plan
+type paymentsBody struct {
+    Payments []types.Payment  `json:"payments"`
+    Status   payments.Summary `json:"status"`
+}
+
+// owner scopes stored itineraries to the API key that created them; admin
+// keys and deployments without keys see every itinerary
+func owner(r *http.Request) string {
+    key, ok := auth.KeyFromContext(r.Context())
+    if !ok || key.HasScope(auth.ScopeAdmin) {
+        return ""
+    }
+    return key.ID
+}
+
+// asOf reads the optional asOf query parameter payment status is evaluated on
+func asOf(r *http.Request) (datetime.Date, error) {
+    value := r.URL.Query().Get("asOf")
+    if value == "" {
+        return datetime.Today(time.Local), nil
+    }
+    return datetime.ParseDate(value)
+}
+
+// loadRecord fetches the itinerary named in the URL, writing a 404 if it doesn't exist
+func loadRecord(w http.ResponseWriter, r *http.Request) (store.Record, bool) {
+    rec, err := itineraries.Get(owner(r), mux.Vars(r)["id"])
+    if err != nil {
+        writeError(w, r, http.StatusNotFound, "Not found", "No itinerary with this ID")
+        return rec, false
+    }
+    return rec, true
+}
+
+func writeStoreError(w http.ResponseWriter, r *http.Request, err error) {
+    if errors.Is(err, store.ErrNotFound) {
+        writeError(w, r, http.StatusNotFound, "Not found", "No itinerary with this ID")
+        return
+    }
+    logging.FromContext(r.Context()).Error("Itinerary store failed", "error", err)
+    writeError(w, r, http.StatusInternalServerError, "Storage failed", err.Error())
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+    w.Header().Set("Content-Type", "application/json")
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(v)
+}
+
+// createItineraryHandler stores an itinerary so payments can be recorded against it
+func createItineraryHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    itineraryData, ok := decodeItinerary(w, r)
+    if !ok {
+        return
+    }
+    rec, err := itineraries.Create(owner(r), itineraryData)
+    if err != nil {
+        writeStoreError(w, r, err)
+        return
+    }
+    w.Header().Set("Location", r.URL.Path+"/"+rec.ID)
+    writeJSON(w, http.StatusCreated, rec)
+}
+
+func getItineraryHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    rec, ok := loadRecord(w, r)
+    if !ok {
+        return
+    }
+    writeJSON(w, http.StatusOK, rec)
+}
+
+// updateItineraryHandler replaces a stored itinerary, keeping its payments.
+// Payments against installments that no longer exist are rejected.
+func updateItineraryHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    itineraryData, ok := decodeItinerary(w, r)
+    if !ok {
+        return
+    }
+    var conflict error
+    rec, err := itineraries.Update(owner(r), mux.Vars(r)["id"], func(rec *store.Record) error {
+        for _, p := range rec.Payments {
+            if err := payments.Validate(itineraryData.PaymentPlan, p); err != nil {
+                conflict = fmt.Errorf("payment %s no longer fits the payment plan: %w", p.ID, err)
+                return conflict
+            }
+        }
+        rec.Itinerary = itineraryData
+        return nil
+    })
+    if conflict != nil {
+        writeError(w, r, http.StatusConflict, "Itinerary not updated", conflict.Error())
+        return
+    }
+    if err != nil {
+        writeStoreError(w, r, err)
+        return
+    }
+    writeJSON(w, http.StatusOK, rec)
+}
+
+// listPaymentsHandler returns the payments recorded for an itinerary with
+// the outstanding and overdue amounts
+func listPaymentsHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    day, err := asOf(r)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "Invalid data", "asOf must be a date such as 2026-11-10")
+        return
+    }
+    rec, ok := loadRecord(w, r)
+    if !ok {
+        return
+    }
+    status := payments.Compute(rec.Itinerary.PaymentPlan, rec.Payments, day)
+    writeJSON(w, http.StatusOK, paymentsBody{Payments: rec.Payments, Status: *status})
+}
+
+// recordPaymentHandler records a payment against a stored itinerary
+func recordPaymentHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    var payment types.Payment
+    if !decodeBody(w, r, &payment) {
+        return
+    }
+    id, err := store.NewID()
+    if err != nil {
+        writeStoreError(w, r, err)
+        return
+    }
+    payment.ID = id
+
+    var invalid error
+    rec, err := itineraries.Update(owner(r), mux.Vars(r)["id"], func(rec *store.Record) error {
+        invalid = payments.Validate(rec.Itinerary.PaymentPlan, payment)
+        if invalid != nil {
+            return invalid
+        }
+        rec.Payments = append(rec.Payments, payment)
+        return nil
+    })
+    if invalid != nil {
+        writeError(w, r, http.StatusUnprocessableEntity, "Payment not recorded", invalid.Error())
+        return
+    }
+    if err != nil {
+        writeStoreError(w, r, err)
+        return
+    }
+
+    logging.FromContext(r.Context()).Info("Payment recorded", "itinerary_id", rec.ID, "payment_id", payment.ID, "installment", payment.InstallmentID)
+    status := payments.Compute(rec.Itinerary.PaymentPlan, rec.Payments, datetime.Today(time.Local))
+    writeJSON(w, http.StatusCreated, paymentsBody{Payments: rec.Payments, Status: *status})
+}
+
+// itineraryPDFHandler renders a stored itinerary with the payment status of
+// each installment
+func itineraryPDFHandler(w http.ResponseWriter, r *http.Request) {
+    logger := logging.FromContext(r.Context())
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    done, ok := startJob(w, r)
+    if !ok {
+        return
+    }
+    defer done()
+
+    day, err := asOf(r)
+    if err != nil {
+        writeError(w, r, http.StatusBadRequest, "Invalid data", "asOf must be a date such as 2026-11-10")
+        return
+    }
+    rec, ok := loadRecord(w, r)
+    if !ok {
+        return
+    }
+    locale, ok := documentLocale(w, r, &rec.Itinerary)
+    if !ok {
+        return
+    }
+    // Stored itineraries link to their page online unless they carry a link
+    if rec.Itinerary.ItineraryURL == "" && cfg.QR.ItineraryURL != "" {
+        rec.Itinerary.ItineraryURL = strings.ReplaceAll(cfg.QR.ItineraryURL, "{id}", url.PathEscape(rec.ID))
+    }
+
+    renderDone := metrics.RenderStarted()
+    renderCtx, renderSpan := tracing.StartSpan(r.Context(), "generate_pdf")
+    pdfBytes, err := utils.GeneratePDF(renderCtx, rec.Itinerary, utils.Options{
+        Branding:          cfg.Branding,
+        SecondaryCurrency: cfg.Currency.Secondary,
+        Rates:             cfg.Currency.Rates,
+        PaymentStatus:     payments.Compute(rec.Itinerary.PaymentPlan, rec.Payments, day),
+        Locale:            locale,
+        Fonts:             scriptFonts,
+        UPI:               cfg.UPIPayee(),
+        Observer:          utils.MultiObserver{metrics.RenderObserver{}, tracing.NewRenderObserver(renderCtx)},
+    })
+    tracing.RecordError(renderSpan, err)
+    renderSpan.End()
+    renderDone(err)
+    if err != nil {
+        logger.Error("PDF generation failed", "error", err, "itinerary_id", rec.ID)
+        writeRenderError(w, r, err)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/pdf")
+    w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_Itinerary.pdf"`, rec.Itinerary.TripDetails.Destination))
+    w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+    w.Write(pdfBytes)
+}
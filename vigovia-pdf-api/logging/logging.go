@@ -0,0 +1,143 @@
+// logging/logging.go
+package logging
+
+import (
+    "context"
+    "io"
+    "log/slog"
+    "reflect"
+    "strings"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// piiKeys are attribute keys whose values identify a customer and must never
+// reach the log pipeline. Keys are compared case-insensitively.
+var piiKeys = map[string]bool{
+    "customername":   true,
+    "customer_name":  true,
+    "travellername":  true,
+    "travelername":   true,
+    "email":          true,
+    "phone":          true,
+    "passportnumber": true,
+    "passport":       true,
+    "pan":            true,
+    "address":        true,
+}
+
+const redacted = "[REDACTED]"
+
+// ParseLevel converts a config log level to a slog level, defaulting to info
+func ParseLevel(level string) slog.Level {
+    switch strings.ToLower(level) {
+    case "debug":
+        return slog.LevelDebug
+    case "warn":
+        return slog.LevelWarn
+    case "error":
+        return slog.LevelError
+    default:
+        return slog.LevelInfo
+    }
+}
+
+// New creates a JSON logger that redacts customer PII and installs it as the
+// slog default so package level slog calls and the standard log package use it.
+func New(w io.Writer, level string) *slog.Logger {
+    handler := slog.NewJSONHandler(w, &slog.HandlerOptions{
+        Level:       ParseLevel(level),
+        ReplaceAttr: redactPII,
+    })
+    logger := slog.New(handler)
+    slog.SetDefault(logger)
+    return logger
+}
+
+// redactPII replaces PII attributes with a placeholder. Everything inside a
+// group named after PII, such as an address group, is redacted too, and so are
+// PII keys of maps logged with slog.Any.
+func redactPII(groups []string, a slog.Attr) slog.Attr {
+    if piiKeys[strings.ToLower(a.Key)] {
+        return slog.String(a.Key, redacted)
+    }
+    for _, group := range groups {
+        if piiKeys[strings.ToLower(group)] {
+            return slog.String(a.Key, redacted)
+        }
+    }
+    a.Value = a.Value.Resolve()
+    switch a.Value.Kind() {
+    case slog.KindGroup:
+        attrs := a.Value.Group()
+        inner := make([]slog.Attr, len(attrs))
+        // The full slice expression stops append writing into the caller's array
+        path := append(groups[:len(groups):len(groups)], a.Key)
+        for i, attr := range attrs {
+            inner[i] = redactPII(path, attr)
+        }
+        return slog.Attr{Key: a.Key, Value: slog.GroupValue(inner...)}
+    case slog.KindAny:
+        if v, changed := redactValue(a.Value.Any()); changed {
+            return slog.Any(a.Key, v)
+        }
+    }
+    return a
+}
+
+// redactValue copies maps with string keys, and slices of them, with PII
+// entries replaced. The value is returned as is when nothing was redacted.
+func redactValue(v any) (any, bool) {
+    rv := reflect.ValueOf(v)
+    switch rv.Kind() {
+    case reflect.Map:
+        if rv.Type().Key().Kind() != reflect.String {
+            return v, false
+        }
+        out := make(map[string]any, rv.Len())
+        changed := false
+        iter := rv.MapRange()
+        for iter.Next() {
+            key := iter.Key().String()
+            if piiKeys[strings.ToLower(key)] {
+                out[key] = redacted
+                changed = true
+                continue
+            }
+            value, c := redactValue(iter.Value().Interface())
+            out[key] = value
+            changed = changed || c
+        }
+        if changed {
+            return out, true
+        }
+    case reflect.Slice, reflect.Array:
+        // Maps can only hide in slices of interfaces or maps
+        if elem := rv.Type().Elem().Kind(); elem != reflect.Interface && elem != reflect.Map {
+            return v, false
+        }
+        out := make([]any, rv.Len())
+        changed := false
+        for i := range out {
+            value, c := redactValue(rv.Index(i).Interface())
+            out[i] = value
+            changed = changed || c
+        }
+        if changed {
+            return out, true
+        }
+    }
+    return v, false
+}
+
+// FromContext returns the default logger annotated with the request ID and,
+// when a span is active, the trace ID so logs and traces can be correlated
+func FromContext(ctx context.Context) *slog.Logger {
+    logger := slog.Default()
+    if id := RequestIDFromContext(ctx); id != "" {
+        logger = logger.With(slog.String("request_id", id))
+    }
+    if sc := trace.SpanContextFromContext(ctx); sc.IsValid() {
+        logger = logger.With(slog.String("trace_id", sc.TraceID().String()))
+    }
+    return logger
+}
@@ -0,0 +1,77 @@
+package logging
+
+import (
+    "bytes"
+    "context"
+    "encoding/json"
+    "log/slog"
+    "strings"
+    "testing"
+)
+
+type customer struct{ name, email string }
+
+func (c customer) LogValue() slog.Value {
+    return slog.GroupValue(slog.String("name", c.name), slog.String("email", c.email))
+}
+
+func TestRedactPII(t *testing.T) {
+    tests := []struct {
+        name string
+        attr slog.Attr
+        want string
+    }{
+        {"top level", slog.String("email", "a@b.in"), `{"email":"[REDACTED]"}`},
+        {"any case", slog.String("PassportNumber", "K1234567"), `{"PassportNumber":"[REDACTED]"}`},
+        {"other keys", slog.Int("nights", 4), `{"nights":4}`},
+        {"group", slog.Group("booking", slog.String("id", "b1"), slog.String("phone", "+91 98450 00000")),
+            `{"booking":{"id":"b1","phone":"[REDACTED]"}}`},
+        {"nested group", slog.Group("booking", slog.Group("lead", slog.String("pan", "ABCDE1234F"), slog.Int("age", 40))),
+            `{"booking":{"lead":{"age":40,"pan":"[REDACTED]"}}}`},
+        {"group named after PII", slog.Group("Address", slog.String("city", "Pune")), `{"Address":{"city":"[REDACTED]"}}`},
+        {"log valuer", slog.Any("lead", customer{"Asha", "asha@b.in"}), `{"lead":{"email":"[REDACTED]","name":"Asha"}}`},
+        {"map", slog.Any("payload", map[string]any{"customerName": "Asha", "days": 4}),
+            `{"payload":{"customerName":"[REDACTED]","days":4}}`},
+        {"nested map", slog.Any("payload", map[string]any{"travellers": []any{map[string]string{"Email": "a@b.in", "seat": "3A"}}}),
+            `{"payload":{"travellers":[{"Email":"[REDACTED]","seat":"3A"}]}}`},
+        {"slice of maps", slog.Any("rows", []map[string]any{{"phone": "1"}, {"id": 2}}),
+            `{"rows":[{"phone":"[REDACTED]"},{"id":2}]}`},
+        {"map without PII", slog.Any("counts", map[string]int{"days": 4}), `{"counts":{"days":4}}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            var buf bytes.Buffer
+            logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{
+                ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+                    // Drop time, level and msg so only the attribute is left
+                    if len(groups) == 0 && (a.Key == slog.TimeKey || a.Key == slog.LevelKey || a.Key == slog.MessageKey) {
+                        return slog.Attr{}
+                    }
+                    return redactPII(groups, a)
+                },
+            }))
+            logger.LogAttrs(context.Background(), slog.LevelInfo, "", tt.attr)
+
+            // Round trip through a map so key order doesn't matter
+            var got, want any
+            if err := json.Unmarshal(buf.Bytes(), &got); err != nil {
+                t.Fatal(err)
+            }
+            json.Unmarshal([]byte(tt.want), &want)
+            gotJSON, _ := json.Marshal(got)
+            wantJSON, _ := json.Marshal(want)
+            if string(gotJSON) != string(wantJSON) {
+                t.Errorf("logged %s, want %s", strings.TrimSpace(buf.String()), tt.want)
+            }
+        })
+    }
+}
+
+func TestRedactPIIInLoggerGroups(t *testing.T) {
+    defer slog.SetDefault(slog.Default())
+    var buf bytes.Buffer
+    New(&buf, "info").WithGroup("request").Info("created", "email", "a@b.in", "id", "r1")
+    if strings.Contains(buf.String(), "a@b.in") || !strings.Contains(buf.String(), `"request":{"email":"[REDACTED]","id":"r1"}`) {
+        t.Errorf("logged %s", buf.String())
+    }
+}
@@ -0,0 +1,77 @@
+// logging/request_id.go
+package logging
+
+import (
+    "context"
+    "crypto/rand"
+    "encoding/hex"
+    "log/slog"
+    "net/http"
+    "github.com/felixge/httpsnoop"
+)
+
+const HeaderRequestID = "X-Request-ID"
+
+// Incoming IDs longer than this are replaced so clients can't bloat log lines
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestIDFromContext returns the request ID stored by RequestID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+    id, _ := ctx.Value(requestIDKey{}).(string)
+    return id
+}
+
+// WithRequestID returns a copy of ctx carrying the request ID
+func WithRequestID(ctx context.Context, id string) context.Context {
+    return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID assigns every request an ID, honouring a well formed incoming
+// X-Request-ID, echoes it in the response and writes a structured access log.
+func RequestID(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        id := r.Header.Get(HeaderRequestID)
+        if !validRequestID(id) {
+            id = newRequestID()
+        }
+        w.Header().Set(HeaderRequestID, id)
+        r = r.WithContext(WithRequestID(r.Context(), id))
+
+        m := httpsnoop.CaptureMetrics(next, w, r)
+
+        level := slog.LevelInfo
+        if m.Code >= http.StatusInternalServerError {
+            level = slog.LevelError
+        }
+        slog.Default().LogAttrs(r.Context(), level, "http request",
+            slog.String("request_id", id),
+            slog.String("method", r.Method),
+            slog.String("path", r.URL.Path),
+            slog.Int("status", m.Code),
+            slog.Int64("bytes", m.Written),
+            slog.Duration("duration", m.Duration),
+            slog.String("remote_addr", r.RemoteAddr),
+            slog.String("origin", r.Header.Get("Origin")),
+        )
+    })
+}
+
+func validRequestID(id string) bool {
+    if id == "" || len(id) > maxRequestIDLength {
+        return false
+    }
+    for _, c := range id {
+        if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.') {
+            return false
+        }
+    }
+    return true
+}
+
+func newRequestID() string {
+    b := make([]byte, 16)
+    rand.Read(b)
+    return hex.EncodeToString(b)
+}
@@ -1,68 +1,255 @@
 package main
 
 import (
+    "context"
+    "crypto/sha256"
+    "encoding/hex"
     "encoding/json"
+    "errors"
     "fmt"
-    "log"
+    "log/slog"
     "net/http"
     "os"
+    "strconv"
+    "strings"
+    "time"
+    "vigovia-pdf-api/auth"
+    "vigovia-pdf-api/cache"
+    "vigovia-pdf-api/config"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/invoice"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/metrics"
+    "vigovia-pdf-api/migrate"
+    "vigovia-pdf-api/schema"
+    "vigovia-pdf-api/store"
+    "vigovia-pdf-api/tracing"
     "vigovia-pdf-api/types"
     "vigovia-pdf-api/utils"
     "github.com/gorilla/handlers"
     "github.com/gorilla/mux"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+var (
+    cfg           config.Config
+    authenticator *auth.Authenticator
+    pdfCache      *cache.LRU[[]byte]
+    // scriptFonts are the configured fonts for scripts Arial can't print
+    scriptFonts map[string][]byte
 )
 
 func main() {
+    if err := run(); err != nil {
+        os.Exit(1)
+    }
+}
+
+// run sets the server up and serves until it is stopped. Errors are returned
+// rather than exiting on the spot so deferred cleanup, such as flushing
+// traces, still runs.
+func run() error {
+    var err error
+    cfg, err = config.Load(os.Args[1:])
+    logging.New(os.Stdout, cfg.LogLevel)
+    if err != nil {
+        return fail("Invalid configuration", err)
+    }
+
+    // Tracing
+    shutdownTracing, err := tracing.Setup(context.Background(), cfg.Tracing)
+    if err != nil {
+        return fail("Failed to set up tracing", err)
+    }
+    defer func() {
+        ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+        defer cancel()
+        if err := shutdownTracing(ctx); err != nil {
+            slog.Error("Failed to flush traces", "error", err)
+        }
+    }()
+
+    // API keys
+    authenticator, err = auth.NewAuthenticator(cfg.Auth.Keys, cfg.Auth.UsageFile)
+    if err != nil {
+        return fail("Invalid API key configuration", err)
+    }
+    defer func() {
+        if err := authenticator.Close(); err != nil {
+            slog.Error("Failed to save API key usage", "error", err)
+        }
+    }()
+    if !authenticator.Enabled() {
+        slog.Warn("No API keys configured, API key authentication is disabled")
+    }
+
+    pdfCache = cache.NewLRU[[]byte](cfg.Cache.Entries, cfg.Cache.TTL.Duration)
+
+    // Fonts for locales such as Hindi
+    scriptFonts = make(map[string][]byte)
+    for script, path := range cfg.Locale.Fonts {
+        scriptFonts[script], err = os.ReadFile(path)
+        if err != nil {
+            return fail("Failed to load font", err)
+        }
+    }
+
+    // Invoice and receipt numbering
+    documentCounters, err = invoice.OpenCounters(cfg.Invoicing.CounterFile)
+    if err != nil {
+        return fail("Failed to load invoice counters", err)
+    }
+    issuedInvoices, err = invoice.OpenLedger(cfg.Invoicing.InvoicesFile, documentCounters)
+    if err != nil {
+        return fail("Failed to load issued invoices", err)
+    }
+    if cfg.Invoicing.Seller.GSTIN == "" {
+        slog.Warn("No supplier GSTIN configured, tax invoices are disabled")
+    }
+
+    // Stored itineraries and payments
+    itineraries, err = store.Open(cfg.Storage.ItinerariesFile)
+    if err != nil {
+        return fail("Failed to load stored itineraries", err)
+    }
+
     r := mux.NewRouter()
+    r.Use(metrics.Middleware, tracing.Middleware)
 
-    // Health check endpoint
-    r.HandleFunc("/api/health", healthCheckHandler).Methods("GET", "OPTIONS")
+    // Prometheus metrics
+    r.Handle("/metrics", metrics.Handler()).Methods("GET")
 
-    // PDF generation endpoint
-    r.HandleFunc("/api/generate-pdf", generatePDFHandler).Methods("POST", "OPTIONS")
+    // Versioned API; new clients should only use these routes
+    apiDoc := openAPIHandler(buildOpenAPI())
+    registerRoutes(r.PathPrefix("/api/v1").Subrouter(), apiDoc)
+
+    // Unversioned routes are kept for existing clients and marked deprecated
+    legacy := r.PathPrefix("/api").Subrouter()
+    legacy.Use(deprecated("/api/v1"))
+    registerRoutes(legacy, apiDoc)
 
     // 404 handler
-    r.NotFoundHandler = http.HandlerFunc(notFoundHandler)
+    r.NotFoundHandler = metrics.Middleware(http.HandlerFunc(notFoundHandler))
 
     // CORS middleware
-    corsHandler := handlers.CORS(
-        handlers.AllowedOrigins([]string{"http://localhost:5173"}),
-        handlers.AllowedMethods([]string{"GET", "POST", "OPTIONS"}),
-        handlers.AllowedHeaders([]string{"Content-Type", "Accept"}),
-        handlers.AllowCredentials(),
+    corsOptions := []handlers.CORSOption{
+        handlers.AllowedOrigins(cfg.CORS.AllowedOrigins),
+        handlers.AllowedMethods([]string{"GET", "POST", "PUT", "OPTIONS"}),
+        handlers.AllowedHeaders([]string{"Content-Type", "Accept", "Authorization", auth.HeaderAPIKey, logging.HeaderRequestID, "traceparent", "tracestate"}),
+        handlers.ExposedHeaders([]string{"Content-Disposition", "X-Document-Number", "X-Schema-Version"}),
         handlers.MaxAge(300),
         handlers.OptionStatusCode(http.StatusOK),
-    )(r)
+    }
+    // Browsers refuse credentials from a wildcard origin; keys travel in
+    // headers, so only listed origins need them
+    if !cfg.CORS.AnyOrigin() {
+        corsOptions = append(corsOptions, handlers.AllowCredentials())
+    }
+    corsHandler := handlers.CORS(corsOptions...)(r)
 
-    // Start server
-    port := os.Getenv("PORT")
-    if port == "" {
-        port = "5000"
+    // Assign request IDs and write structured access logs
+    loggedRouter := logging.RequestID(corsHandler)
+
+    server := &http.Server{
+        Addr:              cfg.Server.ListenAddr,
+        Handler:           loggedRouter,
+        ReadTimeout:       cfg.Server.ReadTimeout.Duration,
+        ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout.Duration,
+        WriteTimeout:      cfg.Server.WriteTimeout.Duration,
+        IdleTimeout:       cfg.Server.IdleTimeout.Duration,
+        MaxHeaderBytes:    cfg.Server.MaxHeaderBytes,
+    }
+
+    baseURL := "http://" + displayHost(cfg.Server.ListenAddr)
+    if cfg.TLSEnabled() {
+        baseURL = "https://" + displayHost(cfg.Server.ListenAddr)
+    }
+    slog.Info("Vigovia PDF API server listening",
+        "addr", cfg.Server.ListenAddr,
+        "health", baseURL+"/api/v1/health",
+        "pdf_endpoint", baseURL+"/api/v1/generate-pdf",
+        "allowed_origins", cfg.CORS.AllowedOrigins,
+    )
+
+    if err := runServer(server); err != nil {
+        return fail("Server error", err)
     }
+    return nil
+}
 
-    log.Printf("Vigovia PDF API server running on port %s", port)
-    log.Printf("Health check: http://localhost:%s/api/health", port)
-    log.Printf("PDF endpoint: http://localhost:%s/api/generate-pdf", port)
+// registerRoutes adds the API endpoints to a router mounted at an API prefix
+func registerRoutes(r *mux.Router, apiDoc http.HandlerFunc) {
+    // OpenAPI document
+    r.HandleFunc("/openapi.json", apiDoc).Methods("GET", "OPTIONS")
 
-    // Wrap the router with a logging middleware for debugging
-    loggedRouter := handlers.LoggingHandler(os.Stdout, corsHandler)
+    // Health check endpoint
+    r.HandleFunc("/health", healthCheckHandler).Methods("GET", "OPTIONS")
 
-    err := http.ListenAndServe(":"+port, loggedRouter)
-    if err != nil {
-        log.Fatalf("Server failed to start: %v", err)
+    // Readiness endpoint, fails as soon as a shutdown starts
+    r.HandleFunc("/ready", readinessHandler).Methods("GET", "OPTIONS")
+
+    // PDF generation endpoint
+    r.HandleFunc("/generate-pdf", authenticator.Require(auth.ScopeGenerate, generatePDFHandler)).Methods("POST", "OPTIONS")
+
+    // Cost breakdown for an itinerary
+    r.HandleFunc("/pricing/breakdown", authenticator.Require(auth.ScopeGenerate, costBreakdownHandler)).Methods("POST", "OPTIONS")
+
+    // Dated items in order with date problems
+    r.HandleFunc("/itinerary/timeline", authenticator.Require(auth.ScopeGenerate, timelineHandler)).Methods("POST", "OPTIONS")
+
+    // Flight segments from pasted PNR displays and e-tickets
+    r.HandleFunc("/flights/parse", authenticator.Require(auth.ScopeGenerate, parseFlightsHandler)).Methods("POST", "OPTIONS")
+
+    // Airport, airline and visa reference data
+    r.HandleFunc("/reference/airports", authenticator.Require(auth.ScopeRead, airportsHandler)).Methods("GET", "OPTIONS")
+    r.HandleFunc("/reference/airlines", authenticator.Require(auth.ScopeRead, airlinesHandler)).Methods("GET", "OPTIONS")
+    r.HandleFunc("/reference/visa-checklist", authenticator.Require(auth.ScopeRead, visaChecklistHandler)).Methods("GET", "OPTIONS")
+
+    // GST and TCS for a package amount
+    r.HandleFunc("/tax/calculate", authenticator.Require(auth.ScopeGenerate, taxHandler)).Methods("POST", "OPTIONS")
+
+    // Installment schedules from payment policies
+    r.HandleFunc("/payment-plan/schedule", authenticator.Require(auth.ScopeGenerate, scheduleHandler)).Methods("POST", "OPTIONS")
+    r.HandleFunc("/payment-plan/policies", authenticator.Require(auth.ScopeRead, policiesHandler)).Methods("GET", "OPTIONS")
+
+    // Tax invoices, payment receipts and booking vouchers
+    r.HandleFunc("/documents/invoice", authenticator.Require(auth.ScopeGenerate, invoiceHandler)).Methods("POST", "OPTIONS")
+    r.HandleFunc("/documents/receipt", authenticator.Require(auth.ScopeGenerate, receiptHandler)).Methods("POST", "OPTIONS")
+    r.HandleFunc("/documents/vouchers", authenticator.Require(auth.ScopeGenerate, vouchersHandler)).Methods("POST", "OPTIONS")
+
+    // Stored itineraries and payment tracking
+    r.HandleFunc("/itineraries", authenticator.Require(auth.ScopeGenerate, createItineraryHandler)).Methods("POST", "OPTIONS")
+    r.HandleFunc("/itineraries/{id}", authenticator.Require(auth.ScopeRead, getItineraryHandler)).Methods("GET", "OPTIONS")
+    r.HandleFunc("/itineraries/{id}", authenticator.Require(auth.ScopeGenerate, updateItineraryHandler)).Methods("PUT")
+    r.HandleFunc("/itineraries/{id}/payments", authenticator.Require(auth.ScopeRead, listPaymentsHandler)).Methods("GET", "OPTIONS")
+    r.HandleFunc("/itineraries/{id}/payments", authenticator.Require(auth.ScopeGenerate, recordPaymentHandler)).Methods("POST")
+    r.HandleFunc("/itineraries/{id}/pdf", authenticator.Require(auth.ScopeRead, itineraryPDFHandler)).Methods("GET", "OPTIONS")
+
+    // API key usage endpoints
+    r.HandleFunc("/usage", authenticator.Require(auth.ScopeRead, usageHandler)).Methods("GET", "OPTIONS")
+    r.HandleFunc("/admin/usage", authenticator.Require(auth.ScopeAdmin, adminUsageHandler)).Methods("GET", "OPTIONS")
+}
+
+// deprecated marks responses from legacy routes and points at their successor
+func deprecated(successorPrefix string) mux.MiddlewareFunc {
+    return func(next http.Handler) http.Handler {
+        return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+            successor := successorPrefix + strings.TrimPrefix(r.URL.Path, "/api")
+            w.Header().Set("Deprecation", "true")
+            w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="successor-version"`, successor))
+            next.ServeHTTP(w, r)
+        })
     }
 }
 
 func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
-    log.Printf("Health check request: Method=%s, Origin=%s", r.Method, r.Header.Get("Origin"))
     if r.Method == http.MethodOptions {
-        log.Println("Handling OPTIONS request for health check")
         w.WriteHeader(http.StatusOK)
         return
     }
 
     w.Header().Set("Content-Type", "application/json")
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
     w.WriteHeader(http.StatusOK)
     json.NewEncoder(w).Encode(map[string]string{
         "status":  "OK",
@@ -71,35 +258,58 @@ func healthCheckHandler(w http.ResponseWriter, r *http.Request) {
 }
 
 func generatePDFHandler(w http.ResponseWriter, r *http.Request) {
-    log.Printf("PDF generation request: Method=%s, Origin=%s", r.Method, r.Header.Get("Origin"))
+    logger := logging.FromContext(r.Context())
     if r.Method == http.MethodOptions {
-        log.Println("Handling OPTIONS request for generate-pdf")
         w.WriteHeader(http.StatusOK)
         return
     }
 
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-
-    var itineraryData types.ItineraryData
-    if err := json.NewDecoder(r.Body).Decode(&itineraryData); err != nil {
-        http.Error(w, `{"error":"Invalid JSON","message":"Failed to parse request body"}`, http.StatusBadRequest)
+    done, ok := startJob(w, r)
+    if !ok {
         return
     }
+    defer done()
 
-    // Validate required data
-    if itineraryData.TripDetails.CustomerName == "" || itineraryData.TripDetails.Destination == "" {
-        http.Error(w, `{"error":"Invalid data","message":"Trip details are required"}`, http.StatusBadRequest)
+    itineraryData, ok := decodeItinerary(w, r)
+    if !ok {
         return
     }
-
-    // Generate PDF
-    pdfBytes, err := utils.GeneratePDF(itineraryData)
-    if err != nil {
-        log.Printf("PDF generation error: %v", err)
-        http.Error(w, `{"error":"PDF generation failed","message":"`+err.Error()+`"}`, http.StatusInternalServerError)
+    locale, ok := documentLocale(w, r, &itineraryData)
+    if !ok {
         return
     }
 
+    // Identical payloads render identical documents, so serve repeats from the cache
+    cacheKey := renderCacheKey(itineraryData)
+    pdfBytes, hit := pdfCache.Get(cacheKey)
+    metrics.CacheLookup("pdf", hit)
+    trace.SpanFromContext(r.Context()).SetAttributes(attribute.Bool("pdf.cache_hit", hit))
+    logger.Debug("Rendering itinerary", "destination", itineraryData.TripDetails.Destination, "cache_hit", hit)
+    if !hit {
+        // Generate PDF
+        renderDone := metrics.RenderStarted()
+        renderCtx, renderSpan := tracing.StartSpan(r.Context(), "generate_pdf")
+        var err error
+        pdfBytes, err = utils.GeneratePDF(renderCtx, itineraryData, utils.Options{
+            Branding:          cfg.Branding,
+            SecondaryCurrency: cfg.Currency.Secondary,
+            Rates:             cfg.Currency.Rates,
+            Locale:            locale,
+            Fonts:             scriptFonts,
+            UPI:               cfg.UPIPayee(),
+            Observer:          utils.MultiObserver{metrics.RenderObserver{}, tracing.NewRenderObserver(renderCtx)},
+        })
+        tracing.RecordError(renderSpan, err)
+        renderSpan.End()
+        renderDone(err)
+        if err != nil {
+            logger.Error("PDF generation failed", "error", err, "destination", itineraryData.TripDetails.Destination)
+            writeRenderError(w, r, err)
+            return
+        }
+        pdfCache.Add(cacheKey, pdfBytes)
+    }
+
     // Set response headers for PDF download
     w.Header().Set("Content-Type", "application/pdf")
     w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s_Itinerary.pdf"`, itineraryData.TripDetails.Destination))
@@ -109,12 +319,190 @@ func generatePDFHandler(w http.ResponseWriter, r *http.Request) {
     w.Write(pdfBytes)
 }
 
+// decodeItinerary reads, upgrades and validates an itinerary request body.
+// On failure it has already written the error response and returns false.
+func decodeItinerary(w http.ResponseWriter, r *http.Request) (types.ItineraryData, bool) {
+    logger := logging.FromContext(r.Context())
+
+    var itineraryData types.ItineraryData
+    sentVersion := 0
+    upgrade := func(doc interface{}) error {
+        var err error
+        sentVersion, err = migrate.Upgrade(doc)
+        return err
+    }
+    if !decodeBody(w, r, &itineraryData, upgrade) {
+        return itineraryData, false
+    }
+
+    if sentVersion < types.CurrentSchemaVersion {
+        logger.Debug("Upgraded itinerary payload", "from_version", sentVersion, "to_version", types.CurrentSchemaVersion)
+    }
+    w.Header().Set("X-Schema-Version", strconv.Itoa(types.CurrentSchemaVersion))
+
+    // Validate required data
+    _, validateSpan := tracing.StartSpan(r.Context(), "validate_request")
+    valid := itineraryData.TripDetails.CustomerName != "" && itineraryData.TripDetails.Destination != ""
+    validateSpan.SetAttributes(attribute.Bool("valid", valid))
+    validateSpan.End()
+    if !valid {
+        writeError(w, r, http.StatusBadRequest, "Invalid data", "Trip details are required")
+        return itineraryData, false
+    }
+
+    var stays []schema.FieldError
+    for i := range itineraryData.Hotels {
+        if err := itineraryData.Hotels[i].CheckNights(); err != nil {
+            stays = append(stays, schema.FieldError{Path: fmt.Sprintf("$.hotels[%d].nights", i), Message: err.Error()})
+        }
+    }
+    if len(stays) > 0 {
+        writeErrorDetails(w, r, http.StatusBadRequest, "Invalid data", "Hotel nights don't match their check-in and check-out dates", stays)
+        return itineraryData, false
+    }
+    if err := itineraryData.CheckTravellers(); err != nil {
+        writeErrorDetails(w, r, http.StatusBadRequest, "Invalid data", "The travellers don't match the number of travellers",
+            []schema.FieldError{{Path: "$.travellers", Message: err.Error()}})
+        return itineraryData, false
+    }
+
+    return itineraryData, true
+}
+
+// writeRenderError reports a failed render, blaming the request when its
+// locale needs a font the server doesn't have
+func writeRenderError(w http.ResponseWriter, r *http.Request, err error) {
+    w.Header().Del("Content-Language")
+    if errors.Is(err, utils.ErrUnsupportedScript) {
+        writeError(w, r, http.StatusUnprocessableEntity, "Unsupported locale", err.Error())
+        return
+    }
+    writeError(w, r, http.StatusInternalServerError, "PDF generation failed", err.Error())
+}
+
+// documentLocale picks the language an itinerary is printed in: the payload's
+// locale, then the Accept-Language header, then the configured default. The
+// choice is written back to data so cached documents are kept per language.
+func documentLocale(w http.ResponseWriter, r *http.Request, data *types.ItineraryData) (*i18n.Locale, bool) {
+    locale, ok := requestLocale(w, r, data.Locale)
+    if !ok {
+        return nil, false
+    }
+    data.Locale = locale.Tag
+    return locale, true
+}
+
+// requestLocale picks the language of a response: the tag the body names,
+// then the Accept-Language header, then the configured default. An
+// unsupported tag in the body is answered with a 422 reported at $.locale.
+func requestLocale(w http.ResponseWriter, r *http.Request, tag string) (*i18n.Locale, bool) {
+    def, _ := i18n.Lookup(cfg.Locale.Default)
+    locale := i18n.Negotiate(r.Header.Get("Accept-Language"), def)
+    if tag != "" {
+        var ok bool
+        locale, ok = i18n.Lookup(tag)
+        if !ok {
+            writeErrorDetails(w, r, http.StatusUnprocessableEntity, "Unsupported locale", "The requested locale is not supported",
+                []schema.FieldError{{Path: "$.locale", Message: fmt.Sprintf("must be one of %s", strings.Join(i18n.Tags(), ", "))}})
+            return nil, false
+        }
+    }
+
+    w.Header().Set("Content-Language", locale.Tag)
+    return locale, true
+}
+
+// decodeBody strictly decodes a size limited JSON body into v against its
+// generated schema. On failure it writes the error response and returns false.
+func decodeBody(w http.ResponseWriter, r *http.Request, v interface{}, transforms ...schema.Transform) bool {
+    r.Body = http.MaxBytesReader(w, r.Body, cfg.Server.MaxBodyBytes)
+
+    _, decodeSpan := tracing.StartSpan(r.Context(), "decode_request")
+    err := apiSchemas.DecodeStrict(r.Body, v, transforms...)
+    tracing.RecordError(decodeSpan, err)
+    decodeSpan.End()
+    if err != nil {
+        var maxBytesErr *http.MaxBytesError
+        var validationErr *schema.ValidationError
+        switch {
+        case errors.As(err, &maxBytesErr):
+            writeError(w, r, http.StatusRequestEntityTooLarge, "Payload too large", fmt.Sprintf("Request body must not exceed %d bytes", maxBytesErr.Limit))
+        case errors.As(err, &validationErr):
+            writeErrorDetails(w, r, http.StatusBadRequest, "Invalid data", "Request body does not match the schema", validationErr.Errors)
+        default:
+            writeError(w, r, http.StatusBadRequest, "Invalid JSON", "Failed to parse request body: "+err.Error())
+        }
+        return false
+    }
+    return true
+}
+
+func usageHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+
+    key, ok := auth.KeyFromContext(r.Context())
+    if !ok {
+        writeError(w, r, http.StatusNotFound, "Not Found", "API key authentication is disabled")
+        return
+    }
+    json.NewEncoder(w).Encode(authenticator.Usage(key))
+}
+
+func adminUsageHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "keys": authenticator.AllUsage(),
+    })
+}
+
 func notFoundHandler(w http.ResponseWriter, r *http.Request) {
+    writeError(w, r, http.StatusNotFound, "Not Found", "API endpoint not found")
+}
+
+// Helper function to write a JSON error carrying the request ID for support tickets
+func writeError(w http.ResponseWriter, r *http.Request, status int, title, message string) {
+    writeErrorDetails(w, r, status, title, message, nil)
+}
+
+// Helper function to write a JSON error listing the offending fields
+func writeErrorDetails(w http.ResponseWriter, r *http.Request, status int, title, message string, details []schema.FieldError) {
     w.Header().Set("Content-Type", "application/json")
-    w.Header().Set("Access-Control-Allow-Origin", "http://localhost:5173")
-    w.WriteHeader(http.StatusNotFound)
-    json.NewEncoder(w).Encode(map[string]string{
-        "error":   "Not Found",
-        "message": "API endpoint not found",
+    w.WriteHeader(status)
+    json.NewEncoder(w).Encode(errorBody{
+        Error:     title,
+        Message:   message,
+        RequestID: logging.RequestIDFromContext(r.Context()),
+        Details:   details,
     })
-}
\ No newline at end of file
+}
+
+// Helper function to derive the render cache key from the decoded payload
+func renderCacheKey(data types.ItineraryData) string {
+    raw, _ := json.Marshal(data)
+    sum := sha256.Sum256(raw)
+    return hex.EncodeToString(sum[:])
+}
+
+// Helper function to log an error that stops the server and hand it back to main
+func fail(msg string, err error) error {
+    slog.Error(msg, "error", err)
+    return err
+}
+
+// Helper function to turn a listen address like ":5000" into "localhost:5000"
+func displayHost(addr string) string {
+    if strings.HasPrefix(addr, ":") {
+        return "localhost" + addr
+    }
+    return addr
+}
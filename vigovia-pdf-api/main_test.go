@@ -0,0 +1,109 @@
+package main
+
+import (
+    "net/http"
+    "net/http/httptest"
+    "strings"
+    "testing"
+    "vigovia-pdf-api/auth"
+    "vigovia-pdf-api/cache"
+    "vigovia-pdf-api/config"
+    "vigovia-pdf-api/tracing"
+    "github.com/gorilla/mux"
+    "go.opentelemetry.io/otel"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+const testItinerary = `{
+    "tripDetails": {"customerName": "Asha Rao", "destination": "Goa", "departureFrom": "Mumbai",
+        "departureDate": "2026-11-10", "arrivalDate": "2026-11-12", "numberOfTravelers": 2, "days": 2, "nights": 1},
+    "dailyItinerary": [{"day": 1, "date": "2026-11-10", "activities": [{"name": "Fort Aguada", "type": "morning"}]}]
+}`
+
+// newTestRouter sets the globals up the way run does, without API keys
+func newTestRouter(t *testing.T) *mux.Router {
+    t.Helper()
+    cfg = config.Default()
+    var err error
+    authenticator, err = auth.NewAuthenticator(nil, "")
+    if err != nil {
+        t.Fatal(err)
+    }
+    pdfCache = cache.NewLRU[[]byte](cfg.Cache.Entries, cfg.Cache.TTL.Duration)
+
+    r := mux.NewRouter()
+    r.Use(tracing.Middleware)
+    registerRoutes(r.PathPrefix("/api/v1").Subrouter(), openAPIHandler(buildOpenAPI()))
+    return r
+}
+
+func TestGeneratePDFSpans(t *testing.T) {
+    exporter := tracetest.NewInMemoryExporter()
+    provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+    previous := otel.GetTracerProvider()
+    otel.SetTracerProvider(provider)
+    defer otel.SetTracerProvider(previous)
+
+    router := newTestRouter(t)
+    req := httptest.NewRequest("POST", "/api/v1/generate-pdf", strings.NewReader(testItinerary))
+    req.Header.Set("Content-Type", "application/json")
+    w := httptest.NewRecorder()
+    router.ServeHTTP(w, req)
+    if w.Code != http.StatusOK || w.Header().Get("Content-Type") != "application/pdf" {
+        t.Fatalf("status %d: %s", w.Code, w.Body)
+    }
+
+    spans := make(map[string]tracetest.SpanStub)
+    for _, span := range exporter.GetSpans() {
+        if _, dup := spans[span.Name]; dup {
+            t.Errorf("span %q recorded twice", span.Name)
+        }
+        spans[span.Name] = span
+    }
+
+    // Every span belongs to the request's trace, under the span named here
+    parents := map[string]string{
+        "POST /api/v1/generate-pdf": "",
+        "decode_request":            "POST /api/v1/generate-pdf",
+        "validate_request":          "POST /api/v1/generate-pdf",
+        "generate_pdf":              "POST /api/v1/generate-pdf",
+        "render.header":             "generate_pdf",
+        "render.trip_details":       "generate_pdf",
+        "render.daily_itinerary":    "generate_pdf",
+        "render.footer":             "generate_pdf",
+        "render.output":             "generate_pdf",
+    }
+    handler := spans["POST /api/v1/generate-pdf"]
+    for name, parent := range parents {
+        span, ok := spans[name]
+        if !ok {
+            t.Errorf("no %q span", name)
+            continue
+        }
+        if span.SpanContext.TraceID() != handler.SpanContext.TraceID() {
+            t.Errorf("%q is in another trace", name)
+        }
+        if parent == "" {
+            if span.Parent.IsValid() {
+                t.Errorf("%q has a parent, want a root span", name)
+            }
+            continue
+        }
+        if span.Parent.SpanID() != spans[parent].SpanContext.SpanID() {
+            t.Errorf("%q is not a child of %q", name, parent)
+        }
+    }
+
+    if got := handler.SpanKind.String(); got != "server" {
+        t.Errorf("handler span kind %s, want server", got)
+    }
+    attrs := make(map[string]string)
+    for _, kv := range append(handler.Attributes, spans["generate_pdf"].Attributes...) {
+        attrs[string(kv.Key)] = kv.Value.Emit()
+    }
+    if attrs["http.route"] != "/api/v1/generate-pdf" || attrs["http.response.status_code"] != "200" ||
+        attrs["pdf.cache_hit"] != "false" || attrs["pdf.pages"] == "" {
+        t.Errorf("attributes %v", attrs)
+    }
+}
@@ -0,0 +1,130 @@
+// metrics/metrics.go
+package metrics
+
+import (
+    "net/http"
+    "strconv"
+    "time"
+    "github.com/felixge/httpsnoop"
+    "github.com/gorilla/mux"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "vigovia"
+
+var (
+    httpRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "http_requests_total",
+        Help:      "HTTP requests by route, method and status code.",
+    }, []string{"route", "method", "status"})
+
+    httpDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "http_request_duration_seconds",
+        Help:      "HTTP request latency by route.",
+        Buckets:   prometheus.DefBuckets,
+    }, []string{"route", "method"})
+
+    renderDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "pdf_render_section_duration_seconds",
+        Help:      "Time spent rendering each section of a PDF.",
+        Buckets:   prometheus.ExponentialBuckets(0.0005, 2, 14),
+    }, []string{"section"})
+
+    renderFailures = promauto.NewCounter(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "pdf_render_failures_total",
+        Help:      "PDF renders that returned an error.",
+    })
+
+    pdfSize = promauto.NewHistogram(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "pdf_size_bytes",
+        Help:      "Size of generated PDFs.",
+        Buckets:   prometheus.ExponentialBuckets(4<<10, 2, 12),
+    })
+
+    pdfPages = promauto.NewHistogram(prometheus.HistogramOpts{
+        Namespace: namespace,
+        Name:      "pdf_pages",
+        Help:      "Page count of generated PDFs.",
+        Buckets:   []float64{1, 2, 3, 4, 5, 6, 8, 10, 15, 20, 30, 50},
+    })
+
+    rendersInFlight = promauto.NewGauge(prometheus.GaugeOpts{
+        Namespace: namespace,
+        Name:      "pdf_renders_in_flight",
+        Help:      "PDF renders currently in progress.",
+    })
+
+    cacheRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+        Namespace: namespace,
+        Name:      "cache_requests_total",
+        Help:      "Cache lookups by cache name and result (hit or miss).",
+    }, []string{"cache", "result"})
+)
+
+// Handler serves the Prometheus exposition format
+func Handler() http.Handler {
+    return promhttp.Handler()
+}
+
+// Middleware counts requests and observes latency per route template so
+// path parameters don't explode label cardinality.
+func Middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        m := httpsnoop.CaptureMetrics(next, w, r)
+        route := routeTemplate(r)
+        httpRequests.WithLabelValues(route, r.Method, strconv.Itoa(m.Code)).Inc()
+        httpDuration.WithLabelValues(route, r.Method).Observe(m.Duration.Seconds())
+    })
+}
+
+// Helper function to resolve the matched mux route, "unmatched" for 404s
+func routeTemplate(r *http.Request) string {
+    if route := mux.CurrentRoute(r); route != nil {
+        if tpl, err := route.GetPathTemplate(); err == nil {
+            return tpl
+        }
+    }
+    return "unmatched"
+}
+
+// RenderStarted marks a render as in flight; call the returned func when it ends
+func RenderStarted() func(err error) {
+    rendersInFlight.Inc()
+    return func(err error) {
+        rendersInFlight.Dec()
+        if err != nil {
+            renderFailures.Inc()
+        }
+    }
+}
+
+// CacheLookup records a hit or miss for the named cache
+func CacheLookup(cache string, hit bool) {
+    result := "miss"
+    if hit {
+        result = "hit"
+    }
+    cacheRequests.WithLabelValues(cache, result).Inc()
+}
+
+// RenderObserver records per-section timings and document size for GeneratePDF
+type RenderObserver struct{}
+
+func (RenderObserver) StartSection(section string) func() {
+    start := time.Now()
+    return func() {
+        renderDuration.WithLabelValues(section).Observe(time.Since(start).Seconds())
+    }
+}
+
+func (RenderObserver) Rendered(pages, size int) {
+    pdfPages.Observe(float64(pages))
+    pdfSize.Observe(float64(size))
+}
@@ -0,0 +1,65 @@
+// migrate/migrate.go
+package migrate
+
+import (
+    "encoding/json"
+    "fmt"
+    "vigovia-pdf-api/schema"
+    "vigovia-pdf-api/types"
+)
+
+// Upgrader rewrites a decoded payload of one schema version into the next.
+// It works on the raw JSON tree so older shapes never need Go types of their own.
+type Upgrader func(doc map[string]interface{}) error
+
+// upgraders[v] converts a version v payload to version v+1
+var upgraders = map[int]Upgrader{}
+
+// register is called from the init functions of the per-version files
+func register(from int, up Upgrader) {
+    if _, exists := upgraders[from]; exists {
+        panic(fmt.Sprintf("migrate: upgrader from version %d registered twice", from))
+    }
+    upgraders[from] = up
+}
+
+// Upgrade brings a decoded JSON payload up to types.CurrentSchemaVersion in
+// place. Payloads without schemaVersion are the original, version 1 shape.
+// It returns the version the client sent.
+func Upgrade(doc interface{}) (int, error) {
+    obj, ok := doc.(map[string]interface{})
+    if !ok {
+        // Let schema validation report the wrong top level type
+        return 0, nil
+    }
+
+    version := 1
+    if raw, present := obj["schemaVersion"]; present {
+        num, ok := raw.(json.Number)
+        v, err := num.Int64()
+        if !ok || err != nil || v < 1 {
+            return 0, versionError("must be a positive integer")
+        }
+        version = int(v)
+    }
+    if version > types.CurrentSchemaVersion {
+        return version, versionError(fmt.Sprintf("version %d is newer than the latest supported version %d", version, types.CurrentSchemaVersion))
+    }
+
+    sent := version
+    for ; version < types.CurrentSchemaVersion; version++ {
+        up, ok := upgraders[version]
+        if !ok {
+            return sent, versionError(fmt.Sprintf("no upgrade path from version %d", version))
+        }
+        if err := up(obj); err != nil {
+            return sent, fmt.Errorf("upgrading payload from version %d: %w", version, err)
+        }
+    }
+    obj["schemaVersion"] = json.Number(fmt.Sprint(types.CurrentSchemaVersion))
+    return sent, nil
+}
+
+func versionError(message string) error {
+    return &schema.ValidationError{Errors: []schema.FieldError{{Path: "$.schemaVersion", Message: message}}}
+}
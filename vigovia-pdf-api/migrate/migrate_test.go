@@ -0,0 +1,109 @@
+package migrate
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "testing"
+    "vigovia-pdf-api/schema"
+    "vigovia-pdf-api/types"
+)
+
+// decode reads JSON the way the API does, keeping numbers as json.Number
+func decode(t *testing.T, s string) map[string]interface{} {
+    t.Helper()
+    dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+    dec.UseNumber()
+    var doc map[string]interface{}
+    if err := dec.Decode(&doc); err != nil {
+        t.Fatal(err)
+    }
+    return doc
+}
+
+// encode writes doc back as JSON with sorted keys for comparisons
+func encode(t *testing.T, doc interface{}) string {
+    t.Helper()
+    b, err := json.Marshal(doc)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return string(b)
+}
+
+// asValidation returns err as a validation error, failing the test otherwise
+func asValidation(t *testing.T, err error) *schema.ValidationError {
+    t.Helper()
+    var verr *schema.ValidationError
+    if !errors.As(err, &verr) {
+        t.Fatalf("error %v, want a validation error", err)
+    }
+    return verr
+}
+
+// fieldError returns the path of a validation error, or "" for other errors
+func fieldError(err error) string {
+    var verr *schema.ValidationError
+    if !errors.As(err, &verr) || len(verr.Errors) == 0 {
+        return ""
+    }
+    return verr.Errors[0].Path
+}
+
+func TestUpgradeVersion(t *testing.T) {
+    current := types.CurrentSchemaVersion
+    tests := []struct {
+        name    string
+        version string
+        sent    int
+        path    string
+    }{
+        {"current", fmt.Sprint(current), current, ""},
+        {"newer", fmt.Sprint(current + 1), current + 1, "$.schemaVersion"},
+        {"zero", "0", 0, "$.schemaVersion"},
+        {"negative", "-1", 0, "$.schemaVersion"},
+        {"fraction", "1.5", 0, "$.schemaVersion"},
+        {"string", `"1"`, 0, "$.schemaVersion"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            doc := decode(t, `{"schemaVersion": `+tt.version+`}`)
+            sent, err := Upgrade(doc)
+            if sent != tt.sent || fieldError(err) != tt.path || (err == nil) != (tt.path == "") {
+                t.Fatalf("Upgrade = %d, %v; want %d with an error at %q", sent, err, tt.sent, tt.path)
+            }
+            if err == nil && encode(t, doc["schemaVersion"]) != fmt.Sprint(current) {
+                t.Errorf("schemaVersion %v, want %d", doc["schemaVersion"], current)
+            }
+        })
+    }
+}
+
+func TestUpgradeWithoutVersion(t *testing.T) {
+    // Payloads from before versioning are version 1
+    doc := decode(t, `{"tripDetails": {"destination": "Goa"}}`)
+    sent, err := Upgrade(doc)
+    if err != nil || sent != 1 {
+        t.Fatalf("Upgrade = %d, %v; want 1", sent, err)
+    }
+    if encode(t, doc["schemaVersion"]) != fmt.Sprint(types.CurrentSchemaVersion) {
+        t.Errorf("schemaVersion %v, want %d", doc["schemaVersion"], types.CurrentSchemaVersion)
+    }
+
+    // Anything but an object is left for schema validation
+    if sent, err := Upgrade([]interface{}{}); sent != 0 || err != nil {
+        t.Errorf("Upgrade of an array = %d, %v", sent, err)
+    }
+}
+
+func TestRegisterTwice(t *testing.T) {
+    defer func() {
+        if recover() == nil {
+            t.Error("no panic registering an upgrader twice")
+        }
+    }()
+    register(0, func(map[string]interface{}) error { return nil })
+    defer delete(upgraders, 0)
+    register(0, func(map[string]interface{}) error { return nil })
+}
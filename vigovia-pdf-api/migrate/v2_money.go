@@ -0,0 +1,82 @@
+// migrate/v2_money.go
+package migrate
+
+import (
+    "encoding/json"
+    "fmt"
+    "math"
+    "vigovia-pdf-api/money"
+)
+
+// Version 2 replaced bare rupee integers with money objects carrying a
+// currency and minor units: 45000 becomes {"currency":"INR","minorUnits":4500000}.
+func init() {
+    register(1, upgradeMoney)
+}
+
+func upgradeMoney(doc map[string]interface{}) error {
+    for _, day := range objects(doc["dailyItinerary"]) {
+        for _, activity := range objects(day["activities"]) {
+            if err := convertAmount(activity, "price"); err != nil {
+                return err
+            }
+        }
+        for _, transfer := range objects(day["transfers"]) {
+            if err := convertAmount(transfer, "price"); err != nil {
+                return err
+            }
+        }
+    }
+
+    if plan, ok := doc["paymentPlan"].(map[string]interface{}); ok {
+        if err := convertAmount(plan, "totalAmount"); err != nil {
+            return err
+        }
+        for _, installment := range objects(plan["installments"]) {
+            if err := convertAmount(installment, "amount"); err != nil {
+                return err
+            }
+        }
+    }
+    return nil
+}
+
+// convertAmount rewrites obj[key] from whole rupees to a money object. Values
+// that aren't numbers are left for schema validation to report.
+func convertAmount(obj map[string]interface{}, key string) error {
+    num, ok := obj[key].(json.Number)
+    if !ok {
+        return nil
+    }
+    major, err := num.Int64()
+    if err != nil {
+        // Fractional rupees: keep paise precision
+        f, ferr := num.Float64()
+        if ferr != nil {
+            return fmt.Errorf("%s: %w", key, ferr)
+        }
+        obj[key] = map[string]interface{}{
+            "currency":   money.DefaultCurrency,
+            "minorUnits": json.Number(fmt.Sprint(int64(math.Round(f * 100)))),
+        }
+        return nil
+    }
+    m := money.FromMajor(major, money.DefaultCurrency)
+    obj[key] = map[string]interface{}{
+        "currency":   m.Currency,
+        "minorUnits": json.Number(fmt.Sprint(m.MinorUnits)),
+    }
+    return nil
+}
+
+// Helper function to iterate a JSON array of objects, skipping anything else
+func objects(value interface{}) []map[string]interface{} {
+    arr, _ := value.([]interface{})
+    var result []map[string]interface{}
+    for _, item := range arr {
+        if obj, ok := item.(map[string]interface{}); ok {
+            result = append(result, obj)
+        }
+    }
+    return result
+}
@@ -0,0 +1,35 @@
+package migrate
+
+import "testing"
+
+func TestUpgradeMoney(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        want string
+    }{
+        {"activity and transfer prices",
+            `{"dailyItinerary": [{"activities": [{"price": 45000}], "transfers": [{"price": 1200}]}]}`,
+            `{"dailyItinerary":[{"activities":[{"price":{"currency":"INR","minorUnits":4500000}}],"transfers":[{"price":{"currency":"INR","minorUnits":120000}}]}]}`},
+        {"payment plan",
+            `{"paymentPlan": {"totalAmount": 150000, "installments": [{"amount": 50000}, {"amount": 100000}]}}`,
+            `{"paymentPlan":{"installments":[{"amount":{"currency":"INR","minorUnits":5000000}},{"amount":{"currency":"INR","minorUnits":10000000}}],"totalAmount":{"currency":"INR","minorUnits":15000000}}}`},
+        {"paise are kept", `{"paymentPlan": {"totalAmount": 1999.5}}`,
+            `{"paymentPlan":{"totalAmount":{"currency":"INR","minorUnits":199950}}}`},
+        {"anything but a number is left alone",
+            `{"dailyItinerary": [{"activities": [{"price": "free"}, {"name": "walk"}, {"price": {"currency": "EUR", "minorUnits": 500}}]}]}`,
+            `{"dailyItinerary":[{"activities":[{"price":"free"},{"name":"walk"},{"price":{"currency":"EUR","minorUnits":500}}]}]}`},
+        {"nothing priced", `{"tripDetails": {"destination": "Goa"}}`, `{"tripDetails":{"destination":"Goa"}}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            doc := decode(t, tt.doc)
+            if err := upgradeMoney(doc); err != nil {
+                t.Fatal(err)
+            }
+            if got := encode(t, doc); got != tt.want {
+                t.Errorf("got  %s\nwant %s", got, tt.want)
+            }
+        })
+    }
+}
@@ -0,0 +1,92 @@
+// migrate/v3_dates.go
+package migrate
+
+import (
+    "fmt"
+    "strconv"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/schema"
+)
+
+// Version 3 typed the dates, which were free text before: "27th November"
+// becomes "2026-11-27". Dates without a year are placed on or after the
+// trip's departure. Text that isn't a date at all is rejected at its path,
+// so the client fixes it rather than losing it without a word.
+func init() {
+    register(2, upgradeDates)
+}
+
+func upgradeDates(doc map[string]interface{}) error {
+    var errs []schema.FieldError
+    convert := func(obj map[string]interface{}, path, key string, ref datetime.Date) {
+        if s, ok := convertDate(obj, key, ref); !ok {
+            errs = append(errs, schema.FieldError{Path: path + "." + key, Message: fmt.Sprintf("%q is not a date", s)})
+        }
+    }
+
+    ref := datetime.Today(time.UTC)
+    trip, _ := doc["tripDetails"].(map[string]interface{})
+    if trip != nil {
+        convert(trip, "$.tripDetails", "departureDate", ref)
+        if s, ok := trip["departureDate"].(string); ok {
+            if d, err := datetime.ParseDate(s); err == nil {
+                ref = d
+            }
+        }
+        convert(trip, "$.tripDetails", "arrivalDate", ref)
+    }
+
+    eachObject(doc["dailyItinerary"], "$.dailyItinerary", func(path string, day map[string]interface{}) {
+        convert(day, path, "date", ref)
+    })
+    eachObject(doc["flights"], "$.flights", func(path string, flight map[string]interface{}) {
+        convert(flight, path, "date", ref)
+    })
+    eachObject(doc["hotels"], "$.hotels", func(path string, hotel map[string]interface{}) {
+        convert(hotel, path, "checkIn", ref)
+        convert(hotel, path, "checkOut", ref)
+    })
+    if plan, ok := doc["paymentPlan"].(map[string]interface{}); ok {
+        // Installments fall due before the trip, so their year is found
+        // from a year earlier
+        eachObject(plan["installments"], "$.paymentPlan.installments", func(path string, installment map[string]interface{}) {
+            convert(installment, path, "dueDate", ref.AddDays(-365))
+        })
+    }
+    if visa, ok := doc["visaDetails"].(map[string]interface{}); ok {
+        convert(visa, "$.visaDetails", "processingDate", ref.AddDays(-365))
+    }
+
+    if len(errs) > 0 {
+        return &schema.ValidationError{Errors: errs}
+    }
+    return nil
+}
+
+// convertDate rewrites obj[key] as a YYYY-MM-DD date. Text that isn't a date
+// is left in place and returned with false. Values that aren't strings are
+// left for schema validation.
+func convertDate(obj map[string]interface{}, key string, ref datetime.Date) (string, bool) {
+    s, ok := obj[key].(string)
+    if !ok || s == "" {
+        return s, true
+    }
+    d, err := datetime.ParseDateNear(s, ref)
+    if err != nil {
+        return s, false
+    }
+    obj[key] = d.String()
+    return s, true
+}
+
+// Helper function to visit the objects of a JSON array with their paths,
+// skipping anything else
+func eachObject(value interface{}, path string, visit func(path string, obj map[string]interface{})) {
+    arr, _ := value.([]interface{})
+    for i, item := range arr {
+        if obj, ok := item.(map[string]interface{}); ok {
+            visit(path+"["+strconv.Itoa(i)+"]", obj)
+        }
+    }
+}
@@ -0,0 +1,53 @@
+package migrate
+
+import "testing"
+
+func TestUpgradeDates(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        want string
+    }{
+        {"trip dates",
+            `{"tripDetails": {"departureDate": "10 Nov 2026", "arrivalDate": "15 November"}}`,
+            `{"tripDetails":{"arrivalDate":"2026-11-15","departureDate":"2026-11-10"}}`},
+        {"yearless dates follow the departure into the next year",
+            `{"tripDetails": {"departureDate": "28/12/2026"}, "dailyItinerary": [{"date": "Dec 30"}, {"date": "2 Jan"}],
+                "flights": [{"date": "05JAN"}], "hotels": [{"checkIn": "28th December", "checkOut": "January 2"}]}`,
+            `{"dailyItinerary":[{"date":"2026-12-30"},{"date":"2027-01-02"}],"flights":[{"date":"2027-01-05"}],"hotels":[{"checkIn":"2026-12-28","checkOut":"2027-01-02"}],"tripDetails":{"departureDate":"2026-12-28"}}`},
+        {"installments and visa processing fall up to a year before",
+            `{"tripDetails": {"departureDate": "2026-12-28"}, "paymentPlan": {"installments": [{"dueDate": "1 Dec"}, {"dueDate": "2nd January"}]},
+                "visaDetails": {"processingDate": "15 Oct"}}`,
+            `{"paymentPlan":{"installments":[{"dueDate":"2026-12-01"},{"dueDate":"2026-01-02"}]},"tripDetails":{"departureDate":"2026-12-28"},"visaDetails":{"processingDate":"2026-10-15"}}`},
+        {"blank and typed values are left alone",
+            `{"tripDetails": {"departureDate": "2026-11-10", "arrivalDate": ""}, "flights": [{"date": 20261110}]}`,
+            `{"flights":[{"date":20261110}],"tripDetails":{"arrivalDate":"","departureDate":"2026-11-10"}}`},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            doc := decode(t, tt.doc)
+            if err := upgradeDates(doc); err != nil {
+                t.Fatal(err)
+            }
+            if got := encode(t, doc); got != tt.want {
+                t.Errorf("got  %s\nwant %s", got, tt.want)
+            }
+        })
+    }
+}
+
+func TestUpgradeDatesRejectsText(t *testing.T) {
+    doc := decode(t, `{"tripDetails": {"departureDate": "2026-11-10"}, "flights": [{"date": "11 Nov"}, {"date": "the day after"}],
+        "hotels": [{"checkIn": "10 Nov", "checkOut": "31 Feb"}]}`)
+    var paths []string
+    for _, e := range asValidation(t, upgradeDates(doc)).Errors {
+        paths = append(paths, e.Path)
+    }
+    if encode(t, paths) != `["$.flights[1].date","$.hotels[0].checkOut"]` {
+        t.Errorf("errors at %v", paths)
+    }
+    // The text is kept for the client to fix
+    if got := encode(t, doc["flights"]); got != `[{"date":"2026-11-11"},{"date":"the day after"}]` {
+        t.Errorf("flights %s", got)
+    }
+}
@@ -0,0 +1,42 @@
+// migrate/v4_visas.go
+package migrate
+
+import "vigovia-pdf-api/schema"
+
+// Version 4 replaced the group's single visaDetails with a list of visas, one
+// per country and traveller. The old details become one visa for everyone
+// going to the destination; an empty form becomes no visas at all.
+func init() {
+    register(3, upgradeVisas)
+}
+
+// upgradeVisas keeps a visas list the payload already has, as payloads
+// without a schemaVersion are read as version 1 even when they are written
+// for the current one. Sending the old details as well is ambiguous.
+func upgradeVisas(doc map[string]interface{}) error {
+    details, _ := doc["visaDetails"].(map[string]interface{})
+    if _, ok := doc["visas"]; ok {
+        if hasValues(details) {
+            return &schema.ValidationError{Errors: []schema.FieldError{{Path: "$.visaDetails", Message: "give either visaDetails or visas, not both"}}}
+        }
+        delete(doc, "visaDetails")
+        return nil
+    }
+    delete(doc, "visaDetails")
+    visas := []interface{}{}
+    if hasValues(details) {
+        visas = append(visas, details)
+    }
+    doc["visas"] = visas
+    return nil
+}
+
+// hasValues reports whether any of an object's fields is filled in
+func hasValues(obj map[string]interface{}) bool {
+    for _, v := range obj {
+        if s, ok := v.(string); v != nil && (!ok || s != "") {
+            return true
+        }
+    }
+    return false
+}
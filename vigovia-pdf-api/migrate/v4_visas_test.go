@@ -0,0 +1,70 @@
+package migrate
+
+import "testing"
+
+func TestUpgradeVisas(t *testing.T) {
+    tests := []struct {
+        name string
+        doc  string
+        want string
+        path string
+    }{
+        {"details become a visa",
+            `{"visaDetails": {"visaType": "Tourist", "validity": "30 days", "processingDate": "2026-10-15"}}`,
+            `{"visas":[{"processingDate":"2026-10-15","validity":"30 days","visaType":"Tourist"}]}`, ""},
+        {"an empty form becomes no visas", `{"visaDetails": {"visaType": "", "validity": null}}`, `{"visas":[]}`, ""},
+        {"no details", `{"tripDetails": {}}`, `{"tripDetails":{},"visas":[]}`, ""},
+        {"visas are kept", `{"visas": [{"country": "FR"}]}`, `{"visas":[{"country":"FR"}]}`, ""},
+        {"an empty form next to visas is dropped", `{"visaDetails": {"visaType": ""}, "visas": [{"country": "FR"}]}`,
+            `{"visas":[{"country":"FR"}]}`, ""},
+        {"details and visas", `{"visaDetails": {"visaType": "Tourist"}, "visas": []}`, "", "$.visaDetails"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            doc := decode(t, tt.doc)
+            err := upgradeVisas(doc)
+            if tt.path != "" {
+                if fieldError(err) != tt.path {
+                    t.Fatalf("error %v, want one at %s", err, tt.path)
+                }
+                return
+            }
+            if err != nil {
+                t.Fatal(err)
+            }
+            if got := encode(t, doc); got != tt.want {
+                t.Errorf("got  %s\nwant %s", got, tt.want)
+            }
+        })
+    }
+}
+
+// TestUpgradeFromVersion1 takes an original payload through every upgrade
+func TestUpgradeFromVersion1(t *testing.T) {
+    doc := decode(t, `{
+        "tripDetails": {"destination": "Paris", "departureDate": "10 Nov 2026", "arrivalDate": "15 Nov"},
+        "dailyItinerary": [{"date": "11th November", "activities": [{"name": "Louvre", "price": 2200}]}],
+        "paymentPlan": {"totalAmount": 250000, "installments": [{"amount": 250000, "dueDate": "1 Oct"}]},
+        "visaDetails": {"visaType": "Schengen", "processingDate": "20 Sep"}
+    }`)
+    sent, err := Upgrade(doc)
+    if err != nil || sent != 1 {
+        t.Fatalf("Upgrade = %d, %v; want 1", sent, err)
+    }
+    want := `{"dailyItinerary":[{"activities":[{"name":"Louvre","price":{"currency":"INR","minorUnits":220000}}],"date":"2026-11-11"}],` +
+        `"paymentPlan":{"installments":[{"amount":{"currency":"INR","minorUnits":25000000},"dueDate":"2026-10-01"}],"totalAmount":{"currency":"INR","minorUnits":25000000}},` +
+        `"schemaVersion":4,"tripDetails":{"arrivalDate":"2026-11-15","departureDate":"2026-11-10","destination":"Paris"},` +
+        `"visas":[{"processingDate":"2026-09-20","visaType":"Schengen"}]}`
+    if got := encode(t, doc); got != want {
+        t.Errorf("got  %s\nwant %s", got, want)
+    }
+
+    // A version 3 payload only has its visas upgraded
+    doc = decode(t, `{"schemaVersion": 3, "paymentPlan": {"totalAmount": {"currency": "INR", "minorUnits": 100}}, "visaDetails": {}}`)
+    if sent, err := Upgrade(doc); err != nil || sent != 3 {
+        t.Fatalf("Upgrade = %d, %v; want 3", sent, err)
+    }
+    if got := encode(t, doc); got != `{"paymentPlan":{"totalAmount":{"currency":"INR","minorUnits":100}},"schemaVersion":4,"visas":[]}` {
+        t.Errorf("got %s", got)
+    }
+}
@@ -0,0 +1,90 @@
+// money/currency.go
+package money
+
+import "strings"
+
+// Grouping selects how integer digits are grouped
+type Grouping int
+
+const (
+    // GroupThousands groups every three digits: 1,234,567
+    GroupThousands Grouping = iota
+    // GroupIndian groups the last three digits, then pairs: 12,34,567 (lakh/crore)
+    GroupIndian
+)
+
+// Currency describes how amounts in a currency are stored and printed. Where
+// the symbol goes and which separators are used depend on the language, see
+// Format.
+type Currency struct {
+    Code        string
+    Digits      int // number of minor unit digits, 2 for paise/cents
+    Symbol      string
+    SymbolSpace bool // separate symbol and number with a space, for symbols that are letters
+    Grouping    Grouping
+}
+
+var currencies = map[string]Currency{
+    "INR": {Code: "INR", Digits: 2, Symbol: "₹", Grouping: GroupIndian},
+    "USD": {Code: "USD", Digits: 2, Symbol: "$"},
+    "EUR": {Code: "EUR", Digits: 2, Symbol: "€"},
+    "GBP": {Code: "GBP", Digits: 2, Symbol: "£"},
+    "AED": {Code: "AED", Digits: 2, Symbol: "AED", SymbolSpace: true},
+    "SGD": {Code: "SGD", Digits: 2, Symbol: "S$"},
+    "AUD": {Code: "AUD", Digits: 2, Symbol: "A$"},
+    // The embedded font has no baht sign, so THB prints its code like AED
+    "THB": {Code: "THB", Digits: 2, Symbol: "THB", SymbolSpace: true},
+    "JPY": {Code: "JPY", Digits: 0, Symbol: "¥"},
+}
+
+// numberStyle is how a language writes amounts
+type numberStyle struct {
+    GroupSep    string
+    DecimalSep  string
+    SymbolAfter bool // "1 234,56 €" instead of "€1,234.56"
+}
+
+// numberStyles are keyed by language; other languages write amounts the
+// English way. French groups with a no-break space so amounts never wrap.
+var numberStyles = map[string]numberStyle{
+    "en": {GroupSep: ",", DecimalSep: "."},
+    "hi": {GroupSep: ",", DecimalSep: "."},
+    "fr": {GroupSep: "\u00a0", DecimalSep: ",", SymbolAfter: true},
+}
+
+// styleFor returns the number style of a language tag such as "fr" or "fr-CA"
+func styleFor(locale string) numberStyle {
+    lang, _, _ := strings.Cut(strings.ToLower(locale), "-")
+    lang, _, _ = strings.Cut(lang, "_")
+    if style, ok := numberStyles[lang]; ok {
+        return style
+    }
+    return numberStyles["en"]
+}
+
+// DefaultCurrency is assumed for amounts that don't name a currency
+const DefaultCurrency = "INR"
+
+// Lookup returns the currency for an ISO 4217 code
+func Lookup(code string) (Currency, bool) {
+    c, ok := currencies[strings.ToUpper(code)]
+    return c, ok
+}
+
+// Codes returns every supported currency code
+func Codes() []string {
+    codes := make([]string, 0, len(currencies))
+    for code := range currencies {
+        codes = append(codes, code)
+    }
+    return codes
+}
+
+// scale returns 10^Digits, the number of minor units in one major unit
+func (c Currency) scale() int64 {
+    s := int64(1)
+    for i := 0; i < c.Digits; i++ {
+        s *= 10
+    }
+    return s
+}
@@ -0,0 +1,270 @@
+// money/money.go
+package money
+
+import (
+    "encoding/json"
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+    "vigovia-pdf-api/schema"
+)
+
+// Money is an amount in the minor units (paise, cents) of a currency
+type Money struct {
+    Currency   string `json:"currency"`
+    MinorUnits int64  `json:"minorUnits"`
+}
+
+var ErrCurrencyMismatch = errors.New("money: currency mismatch")
+
+// New returns an amount given in minor units
+func New(minorUnits int64, currency string) Money {
+    return Money{Currency: strings.ToUpper(currency), MinorUnits: minorUnits}
+}
+
+// FromMajor returns an amount given in whole units, e.g. rupees
+func FromMajor(major int64, currency string) Money {
+    c, ok := Lookup(currency)
+    if !ok {
+        return New(major*100, currency)
+    }
+    return New(major*c.scale(), currency)
+}
+
+func (m Money) IsZero() bool {
+    return m.MinorUnits == 0
+}
+
+// Add returns m+o; both must be in the same currency. A zero value without a
+// currency adopts the other operand's currency so sums can start from Money{}.
+func (m Money) Add(o Money) (Money, error) {
+    if m.Currency == "" {
+        m.Currency = o.Currency
+    }
+    if o.Currency != "" && o.Currency != m.Currency {
+        return Money{}, fmt.Errorf("%w: %s + %s", ErrCurrencyMismatch, m.Currency, o.Currency)
+    }
+    return New(m.MinorUnits+o.MinorUnits, m.Currency), nil
+}
+
+func (m Money) Sub(o Money) (Money, error) {
+    return m.Add(New(-o.MinorUnits, o.Currency))
+}
+
+// MulRat multiplies by num/den, rounding half away from zero
+func (m Money) MulRat(num, den int64) Money {
+    return New(roundDiv(m.MinorUnits*num, den), m.Currency)
+}
+
+// Major returns the amount in whole units as a float, for display maths only
+func (m Money) Major() float64 {
+    c, ok := Lookup(m.Currency)
+    if !ok {
+        return float64(m.MinorUnits) / 100
+    }
+    return float64(m.MinorUnits) / float64(c.scale())
+}
+
+// Allocate splits m into parts proportional to weights without losing a
+// single minor unit; the remainder goes to the earliest parts.
+func (m Money) Allocate(weights ...int64) []Money {
+    var total int64
+    for _, w := range weights {
+        total += w
+    }
+    parts := make([]Money, len(weights))
+    if total == 0 {
+        for i := range parts {
+            parts[i] = New(0, m.Currency)
+        }
+        return parts
+    }
+
+    var allocated int64
+    for i, w := range weights {
+        share := m.MinorUnits * w / total
+        parts[i] = New(share, m.Currency)
+        allocated += share
+    }
+    for i := 0; allocated != m.MinorUnits; i = (i + 1) % len(parts) {
+        step := int64(1)
+        if m.MinorUnits < allocated {
+            step = -1
+        }
+        parts[i].MinorUnits += step
+        allocated += step
+    }
+    return parts
+}
+
+// String formats the amount the English way, e.g. "₹1,23,456.50" or "€1,234.56"
+func (m Money) String() string {
+    return Format(m, "")
+}
+
+// Format renders an amount for a language tag such as "en" or "fr": the
+// currency decides the digits, grouping and symbol, the language the
+// separators and which side the symbol goes, so euros print as "€1,234.56"
+// in English and "1 234,56 €" in French. Whole amounts are printed without
+// decimals, matching how prices are quoted.
+func Format(m Money, locale string) string {
+    c, ok := Lookup(m.Currency)
+    if !ok {
+        c = Currency{Code: m.Currency, Digits: 2, Symbol: m.Currency, SymbolSpace: true}
+    }
+    style := styleFor(locale)
+
+    minor := m.MinorUnits
+    negative := minor < 0
+    if negative {
+        minor = -minor
+    }
+    scale := c.scale()
+    whole, frac := minor/scale, minor%scale
+
+    number := group(fmt.Sprint(whole), c.Grouping, style.GroupSep)
+    if frac != 0 {
+        number += style.DecimalSep + fmt.Sprintf("%0*d", c.Digits, frac)
+    }
+
+    var out string
+    switch {
+    case style.SymbolAfter:
+        // A symbol after the number is always spaced off it
+        out = number + "\u00a0" + c.Symbol
+    case c.SymbolSpace:
+        out = c.Symbol + " " + number
+    default:
+        out = c.Symbol + number
+    }
+    if negative {
+        out = "-" + out
+    }
+    return out
+}
+
+func group(digits string, grouping Grouping, sep string) string {
+    if len(digits) <= 3 {
+        return digits
+    }
+    head, tail := digits[:len(digits)-3], digits[len(digits)-3:]
+    size := 3
+    if grouping == GroupIndian {
+        size = 2
+    }
+    var groups []string
+    for len(head) > size {
+        groups = append([]string{head[len(head)-size:]}, groups...)
+        head = head[:len(head)-size]
+    }
+    groups = append([]string{head}, groups...)
+    return strings.Join(append(groups, tail), sep)
+}
+
+// Helper function to divide rounding half away from zero
+func roundDiv(n, d int64) int64 {
+    if d < 0 {
+        n, d = -n, -d
+    }
+    if n >= 0 {
+        return (n + d/2) / d
+    }
+    return -((-n + d/2) / d)
+}
+
+func (m *Money) UnmarshalJSON(b []byte) error {
+    type plain Money
+    var p plain
+    if err := json.Unmarshal(b, &p); err != nil {
+        return err
+    }
+    p.Currency = strings.ToUpper(p.Currency)
+    if _, ok := Lookup(p.Currency); !ok {
+        return fmt.Errorf("money: unsupported currency %q", p.Currency)
+    }
+    *m = Money(p)
+    return nil
+}
+
+func (Money) JSONSchema() *schema.Schema {
+    codes := Codes()
+    sort.Strings(codes)
+    enum := make([]interface{}, len(codes))
+    for i, code := range codes {
+        enum[i] = code
+    }
+    return &schema.Schema{
+        Type:        "object",
+        Description: "An amount in the minor units (paise, cents) of an ISO 4217 currency",
+        Properties: map[string]*schema.Schema{
+            "currency":   {Type: "string", Enum: enum},
+            "minorUnits": {Type: "integer", Format: "int64"},
+        },
+        Required: []string{"currency", "minorUnits"},
+    }
+}
+
+// RateTable converts between currencies. Rates[c] is how many units of c one
+// unit of Base buys; Base itself is implicitly 1.
+type RateTable struct {
+    Base  string             `json:"base"`
+    Rates map[string]float64 `json:"rates"`
+}
+
+func (t RateTable) rate(code string) (float64, bool) {
+    if strings.EqualFold(code, t.Base) {
+        return 1, true
+    }
+    r, ok := t.Rates[strings.ToUpper(code)]
+    return r, ok && r > 0
+}
+
+// Convert expresses m in another currency, rounding to the nearest minor unit
+func (t RateTable) Convert(m Money, to string) (Money, error) {
+    to = strings.ToUpper(to)
+    if m.Currency == to {
+        return m, nil
+    }
+    fromRate, ok := t.rate(m.Currency)
+    if !ok {
+        return Money{}, fmt.Errorf("money: no exchange rate for %s", m.Currency)
+    }
+    toRate, ok := t.rate(to)
+    if !ok {
+        return Money{}, fmt.Errorf("money: no exchange rate for %s", to)
+    }
+    target, ok := Lookup(to)
+    if !ok {
+        return Money{}, fmt.Errorf("money: unsupported currency %q", to)
+    }
+    major := m.Major() / fromRate * toRate
+    return New(int64(math.Round(major*float64(target.scale()))), to), nil
+}
+
+// Merge returns a table with the rates of o layered over t, re-expressing o's
+// rates in t's base when the bases differ
+func (t RateTable) Merge(o RateTable) RateTable {
+    merged := RateTable{Base: t.Base, Rates: make(map[string]float64)}
+    for code, r := range t.Rates {
+        merged.Rates[strings.ToUpper(code)] = r
+    }
+    if o.Base == "" || len(o.Rates) == 0 {
+        return merged
+    }
+    if merged.Base == "" {
+        merged.Base = strings.ToUpper(o.Base)
+    }
+    // factor converts "per unit of o.Base" into "per unit of merged.Base"
+    factor, ok := merged.rate(o.Base)
+    if !ok {
+        return RateTable{Base: strings.ToUpper(o.Base), Rates: o.Rates}
+    }
+    for code, r := range o.Rates {
+        if !strings.EqualFold(code, merged.Base) {
+            merged.Rates[strings.ToUpper(code)] = r * factor
+        }
+    }
+    return merged
+}
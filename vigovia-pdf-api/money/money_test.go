@@ -0,0 +1,176 @@
+package money
+
+import (
+    "encoding/json"
+    "errors"
+    "math"
+    "testing"
+)
+
+func TestFormat(t *testing.T) {
+    tests := []struct {
+        m      Money
+        locale string
+        want   string
+    }{
+        // Indian grouping: the last three digits, then pairs
+        {New(0, "INR"), "en", "₹0"},
+        {New(99900, "INR"), "en", "₹999"},
+        {New(100000, "INR"), "en", "₹1,000"},
+        {New(12345650, "INR"), "en", "₹1,23,456.50"},
+        {New(1234567800, "INR"), "en", "₹1,23,45,678"},
+        {New(123456789000, "INR"), "hi", "₹1,23,45,67,890"},
+        {New(-4500000, "INR"), "en", "-₹45,000"},
+        {New(5, "INR"), "en", "₹0.05"},
+        {New(123456789, "USD"), "en", "$1,234,567.89"},
+        {New(123456, "EUR"), "en", "€1,234.56"},
+        {New(123456, "EUR"), "fr", "1\u00a0234,56\u00a0€"},
+        {New(123456, "EUR"), "fr-CA", "1\u00a0234,56\u00a0€"},
+        {New(12345650, "INR"), "fr", "1\u00a023\u00a0456,50\u00a0₹"},
+        {New(-100, "USD"), "fr", "-1\u00a0$"},
+        {New(123456, "AED"), "en", "AED 1,234.56"},
+        {New(123456, "AED"), "fr", "1\u00a0234,56\u00a0AED"},
+        {New(1234567, "JPY"), "en", "¥1,234,567"},
+        // Languages without a style of their own write amounts the English way
+        {New(123456, "EUR"), "ar", "€1,234.56"},
+        {New(123456, "EUR"), "", "€1,234.56"},
+        {New(123456, "XYZ"), "en", "XYZ 1,234.56"},
+    }
+    for _, tt := range tests {
+        if got := Format(tt.m, tt.locale); got != tt.want {
+            t.Errorf("Format(%d %s, %q) = %q, want %q", tt.m.MinorUnits, tt.m.Currency, tt.locale, got, tt.want)
+        }
+    }
+    if got := New(12345650, "INR").String(); got != "₹1,23,456.50" {
+        t.Errorf("String() = %q", got)
+    }
+}
+
+func TestFromMajor(t *testing.T) {
+    tests := []struct {
+        major int64
+        code  string
+        want  int64
+    }{
+        {45000, "inr", 4500000},
+        {100, "JPY", 100},
+        {7, "XYZ", 700},
+    }
+    for _, tt := range tests {
+        m := FromMajor(tt.major, tt.code)
+        if m.MinorUnits != tt.want || m.Currency != New(0, tt.code).Currency {
+            t.Errorf("FromMajor(%d, %s) = %+v, want %d minor units", tt.major, tt.code, m, tt.want)
+        }
+    }
+}
+
+func TestArithmetic(t *testing.T) {
+    sum, err := Money{}.Add(New(250, "USD"))
+    if err != nil || sum != New(250, "USD") {
+        t.Errorf("zero + 250 = %+v, %v", sum, err)
+    }
+    if _, err := New(1, "USD").Sub(New(1, "EUR")); !errors.Is(err, ErrCurrencyMismatch) {
+        t.Errorf("USD - EUR error %v", err)
+    }
+
+    // Half away from zero
+    if got := New(5, "INR").MulRat(1, 2); got.MinorUnits != 3 {
+        t.Errorf("5 * 1/2 = %d", got.MinorUnits)
+    }
+    if got := New(-5, "INR").MulRat(1, 2); got.MinorUnits != -3 {
+        t.Errorf("-5 * 1/2 = %d", got.MinorUnits)
+    }
+}
+
+func TestAllocate(t *testing.T) {
+    tests := []struct {
+        total   int64
+        weights []int64
+        want    []int64
+    }{
+        {100, []int64{1, 1, 1}, []int64{34, 33, 33}},
+        {-100, []int64{1, 1, 1}, []int64{-34, -33, -33}},
+        {1000, []int64{30, 70}, []int64{300, 700}},
+        {1, []int64{1, 1}, []int64{1, 0}},
+        {100, []int64{0, 0}, []int64{0, 0}},
+    }
+    for _, tt := range tests {
+        parts := New(tt.total, "INR").Allocate(tt.weights...)
+        for i, part := range parts {
+            if part.MinorUnits != tt.want[i] || part.Currency != "INR" {
+                t.Errorf("Allocate(%d, %v) = %v, want %v", tt.total, tt.weights, parts, tt.want)
+                break
+            }
+        }
+    }
+}
+
+func TestConvert(t *testing.T) {
+    rates := RateTable{Base: "INR", Rates: map[string]float64{"USD": 0.012, "EUR": 0.011, "JPY": 1.8}}
+    tests := []struct {
+        m    Money
+        to   string
+        want Money
+    }{
+        {New(10000000, "INR"), "usd", New(120000, "USD")},
+        {New(120000, "USD"), "INR", New(10000000, "INR")},
+        {New(120000, "USD"), "EUR", New(110000, "EUR")},
+        {New(10000, "INR"), "JPY", New(180, "JPY")},
+        {New(500, "EUR"), "EUR", New(500, "EUR")},
+    }
+    for _, tt := range tests {
+        got, err := rates.Convert(tt.m, tt.to)
+        if err != nil || got != tt.want {
+            t.Errorf("Convert(%v, %s) = %+v, %v; want %+v", tt.m, tt.to, got, err, tt.want)
+        }
+    }
+    if _, err := rates.Convert(New(100, "GBP"), "INR"); err == nil {
+        t.Error("no error converting without a rate")
+    }
+}
+
+func TestMerge(t *testing.T) {
+    config := RateTable{Base: "INR", Rates: map[string]float64{"usd": 0.012, "EUR": 0.011}}
+    tests := []struct {
+        name  string
+        table RateTable
+        base  string
+        want  map[string]float64
+    }{
+        {"nothing to merge", RateTable{}, "INR", map[string]float64{"USD": 0.012, "EUR": 0.011}},
+        {"same base overrides", RateTable{Base: "inr", Rates: map[string]float64{"USD": 0.0125, "AED": 0.044}},
+            "INR", map[string]float64{"USD": 0.0125, "EUR": 0.011, "AED": 0.044}},
+        // 1 USD = 3.67 AED and 1 INR = 0.012 USD, so 1 INR = 0.04404 AED
+        {"other base is re-expressed", RateTable{Base: "USD", Rates: map[string]float64{"AED": 3.67, "INR": 80}},
+            "INR", map[string]float64{"USD": 0.012, "EUR": 0.011, "AED": 0.04404}},
+        {"unknown base replaces", RateTable{Base: "GBP", Rates: map[string]float64{"INR": 105}},
+            "GBP", map[string]float64{"INR": 105}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            merged := config.Merge(tt.table)
+            if merged.Base != tt.base || len(merged.Rates) != len(tt.want) {
+                t.Fatalf("Merge = %+v, want base %s with %v", merged, tt.base, tt.want)
+            }
+            for code, want := range tt.want {
+                if math.Abs(merged.Rates[code]-want) > 1e-9 {
+                    t.Errorf("%s rate %v, want %v", code, merged.Rates[code], want)
+                }
+            }
+        })
+    }
+    // The config table is left alone
+    if len(config.Rates) != 2 || config.Rates["usd"] != 0.012 {
+        t.Errorf("config rates changed: %v", config.Rates)
+    }
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+    var m Money
+    if err := json.Unmarshal([]byte(`{"currency": "usd", "minorUnits": 1999}`), &m); err != nil || m != New(1999, "USD") {
+        t.Errorf("decoded %+v, %v", m, err)
+    }
+    if err := json.Unmarshal([]byte(`{"currency": "XYZ", "minorUnits": 1}`), &m); err == nil {
+        t.Error("no error for an unsupported currency")
+    }
+}
@@ -0,0 +1,410 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "vigovia-pdf-api/auth"
+    "vigovia-pdf-api/pnr"
+    "vigovia-pdf-api/pricing"
+    "vigovia-pdf-api/schedule"
+    "vigovia-pdf-api/schema"
+    "vigovia-pdf-api/store"
+    "vigovia-pdf-api/tax"
+    "vigovia-pdf-api/timeline"
+    "vigovia-pdf-api/types"
+)
+
+// apiSchemas holds the schemas generated from the Go types; it is both the
+// source of the OpenAPI document and what request bodies are validated against.
+var apiSchemas = schema.NewRegistry()
+
+type errorBody struct {
+    Error     string              `json:"error"`
+    Message   string              `json:"message"`
+    RequestID string              `json:"requestId"`
+    Details   []schema.FieldError `json:"details,omitempty"`
+}
+
+type statusBody struct {
+    Status  string `json:"status"`
+    Message string `json:"message"`
+}
+
+// buildOpenAPI describes every route registered in main
+func buildOpenAPI() *schema.Document {
+    itinerary := apiSchemas.Ref(types.ItineraryData{})
+    errSchema := apiSchemas.Ref(errorBody{})
+    usage := apiSchemas.Ref(auth.Usage{})
+
+    errResponse := func(description string) schema.Response {
+        return schema.Response{Description: description, Content: schema.JSON(errSchema)}
+    }
+    apiKey := []map[string][]string{{"ApiKeyAuth": {}}}
+    idParam := schema.Parameter{Name: "id", In: "path", Required: true, Schema: &schema.Schema{Type: "string"}}
+    asOfParam := schema.Parameter{Name: "asOf", In: "query", Description: "Day to evaluate due and overdue installments on, defaults to today", Schema: &schema.Schema{Type: "string", Format: "date"}}
+    queryParam := schema.Parameter{Name: "q", In: "query", Required: true, Description: "Code or name to look up, e.g. BLR, Bangalore or Kempegowda", Schema: &schema.Schema{Type: "string"}}
+    languageParam := schema.Parameter{Name: "Accept-Language", In: "header", Description: "Language to print the PDF in when the itinerary sets no locale", Schema: &schema.Schema{Type: "string"}}
+    record := apiSchemas.Ref(store.Record{})
+    paymentStatus := apiSchemas.Ref(paymentsBody{})
+    pdfContent := map[string]schema.MediaType{
+        "application/pdf": {Schema: &schema.Schema{Type: "string", Format: "binary"}},
+    }
+
+    operations := map[string]schema.PathItem{
+        "/health": {
+            "get": {
+                Summary:     "Liveness check",
+                OperationID: "healthCheck",
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Server is running", Content: schema.JSON(apiSchemas.Ref(statusBody{}))},
+                },
+            },
+        },
+        "/ready": {
+            "get": {
+                Summary:     "Readiness check, fails once shutdown starts",
+                OperationID: "readinessCheck",
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Ready to accept work"},
+                    "503": {Description: "Shutting down"},
+                },
+            },
+        },
+        "/generate-pdf": {
+            "post": {
+                Summary:     "Render an itinerary PDF",
+                OperationID: "generatePdf",
+                Parameters:  []schema.Parameter{languageParam},
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(itinerary)},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The rendered PDF", Content: pdfContent},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "401": errResponse("Missing or invalid API key"),
+                    "403": errResponse("API key lacks the generate scope"),
+                    "413": errResponse("Request body too large"),
+                    "422": errResponse("Unsupported locale, or no font for its script"),
+                    "429": errResponse("Rate limit or monthly quota exceeded"),
+                    "500": errResponse("Rendering failed"),
+                    "503": errResponse("Server is shutting down"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/pricing/breakdown": {
+            "post": {
+                Summary:     "Compute the cost breakdown of an itinerary",
+                OperationID: "costBreakdown",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(itinerary)},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Per-day, per-category and per-person costs with reconciliation warnings", Content: schema.JSON(apiSchemas.Ref(pricing.Breakdown{}))},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/itinerary/timeline": {
+            "post": {
+                Summary:     "List an itinerary's dates in order and check them",
+                OperationID: "itineraryTimeline",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(itinerary)},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Events in date order with flight durations, and warnings for overlapping stays, inverted ranges and dates outside the trip", Content: schema.JSON(apiSchemas.Ref(timeline.Report{}))},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/flights/parse": {
+            "post": {
+                Summary:     "Read flight segments from an Amadeus or Sabre PNR display or e-ticket text",
+                OperationID: "parseFlights",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(apiSchemas.Ref(parseFlightsRequest{}))},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The flights found, the record locator and passenger names, and the lines that weren't understood", Content: schema.JSON(apiSchemas.Ref(pnr.Result{}))},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/reference/airports": {
+            "get": {
+                Summary:     "Look up an airport by IATA code, city or name",
+                OperationID: "lookupAirport",
+                Parameters:  []schema.Parameter{queryParam},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The airport named, if any, and near matches with their time zones", Content: schema.JSON(apiSchemas.Ref(airportLookup{}))},
+                    "400": errResponse("No query given"),
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/reference/airlines": {
+            "get": {
+                Summary:     "Look up an airline by IATA code or name",
+                OperationID: "lookupAirline",
+                Parameters:  []schema.Parameter{queryParam},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The airline named, if any, and near matches", Content: schema.JSON(apiSchemas.Ref(airlineLookup{}))},
+                    "400": errResponse("No query given"),
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/reference/visa-checklist": {
+            "get": {
+                Summary:     "List the documents holders of a passport need to visit a country",
+                OperationID: "visaChecklist",
+                Parameters: []schema.Parameter{
+                    {Name: "destination", In: "query", Required: true, Description: "Country visited, e.g. FR", Schema: &schema.Schema{Type: "string"}},
+                    {Name: "nationality", In: "query", Description: "Country of the passport, defaults to IN", Schema: &schema.Schema{Type: "string"}},
+                    {Name: "Accept-Language", In: "header", Description: "Language to name the documents in", Schema: &schema.Schema{Type: "string"}},
+                },
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Whether a visa is needed before travel, online, on arrival or not at all, and the documents to gather", Content: schema.JSON(apiSchemas.Ref(visaChecklist{}))},
+                    "400": errResponse("Missing or malformed country codes"),
+                    "401": errResponse("Missing or invalid API key"),
+                    "422": errResponse("The passport is the destination's own"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/tax/calculate": {
+            "post": {
+                Summary:     "Compute GST and TCS for a package amount",
+                OperationID: "calculateTax",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(apiSchemas.Ref(taxRequest{}))},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Tax line items and the amount payable", Content: schema.JSON(apiSchemas.Ref(tax.Result{}))},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "422": errResponse("The inputs can't be taxed, e.g. a non-INR amount"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/payment-plan/schedule": {
+            "post": {
+                Summary:     "Generate an installment schedule from a payment policy",
+                OperationID: "generateSchedule",
+                Parameters: []schema.Parameter{
+                    {Name: "Accept-Language", In: "header", Description: "Language to write installments in when the request sets no locale", Schema: &schema.Schema{Type: "string"}},
+                },
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(apiSchemas.Ref(schedule.Request{}))},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Installments ready to use as paymentPlan.installments", Content: schema.JSON(apiSchemas.Ref(schedule.Schedule{}))},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "422": errResponse("Invalid dates, an unknown policy, an installment due after departure or an unsupported locale"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/payment-plan/policies": {
+            "get": {
+                Summary:     "List the built-in payment policies",
+                OperationID: "listPolicies",
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Payment policies", Content: schema.JSON(&schema.Schema{
+                        Type:       "object",
+                        Properties: map[string]*schema.Schema{"policies": {Type: "array", Items: apiSchemas.Ref(schedule.Policy{})}},
+                    })},
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/documents/invoice": {
+            "post": {
+                Summary:     "Issue a numbered GST tax invoice for an itinerary's payment plan",
+                OperationID: "issueInvoice",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(apiSchemas.Ref(invoiceRequest{}))},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The invoice PDF; X-Document-Number carries the invoice number. An invoice already issued is reprinted with its number and date", Content: pdfContent},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "422": errResponse("The payment plan can't be invoiced, e.g. no total or a non-INR amount"),
+                    "503": errResponse("No supplier GSTIN is configured"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/documents/receipt": {
+            "post": {
+                Summary:     "Issue a numbered receipt for a payment against an installment",
+                OperationID: "issueReceipt",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(apiSchemas.Ref(receiptRequest{}))},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The receipt PDF; X-Document-Number carries the receipt number", Content: pdfContent},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "422": errResponse("Unknown installment or an amount in another currency"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/documents/vouchers": {
+            "post": {
+                Summary:     "Render vouchers for an itinerary's booked hotels, transfers and activities as their own PDF",
+                OperationID: "generateVouchers",
+                Parameters:  []schema.Parameter{languageParam},
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(itinerary)},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "One voucher page per booking", Content: pdfContent},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "422": errResponse("Nothing is booked, or an unsupported locale"),
+                    "500": errResponse("Rendering failed"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/itineraries": {
+            "post": {
+                Summary:     "Store an itinerary to track payments against it",
+                OperationID: "createItinerary",
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(itinerary)},
+                Responses: map[string]schema.Response{
+                    "201": {Description: "The stored itinerary", Content: schema.JSON(record)},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/itineraries/{id}": {
+            "get": {
+                Summary:     "Get a stored itinerary",
+                OperationID: "getItinerary",
+                Parameters:  []schema.Parameter{idParam},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The stored itinerary and its payments", Content: schema.JSON(record)},
+                    "404": errResponse("No itinerary with this ID for the calling key"),
+                },
+                Security: apiKey,
+            },
+            "put": {
+                Summary:     "Replace a stored itinerary, keeping its payments",
+                OperationID: "updateItinerary",
+                Parameters:  []schema.Parameter{idParam},
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(itinerary)},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The updated itinerary", Content: schema.JSON(record)},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "404": errResponse("No itinerary with this ID for the calling key"),
+                    "409": errResponse("Recorded payments don't fit the new payment plan"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/itineraries/{id}/payments": {
+            "get": {
+                Summary:     "List payments with outstanding and overdue amounts",
+                OperationID: "listPayments",
+                Parameters:  []schema.Parameter{idParam, asOfParam},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Payments and the status of each installment", Content: schema.JSON(paymentStatus)},
+                    "404": errResponse("No itinerary with this ID for the calling key"),
+                },
+                Security: apiKey,
+            },
+            "post": {
+                Summary:     "Record a payment against a stored itinerary",
+                OperationID: "recordPayment",
+                Parameters:  []schema.Parameter{idParam},
+                RequestBody: &schema.RequestBody{Required: true, Content: schema.JSON(apiSchemas.Ref(types.Payment{}))},
+                Responses: map[string]schema.Response{
+                    "201": {Description: "Payments and the status of each installment", Content: schema.JSON(paymentStatus)},
+                    "400": errResponse("Malformed JSON or a payload that doesn't match the schema"),
+                    "404": errResponse("No itinerary with this ID for the calling key"),
+                    "422": errResponse("Unknown installment, another currency or a non-positive amount"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/itineraries/{id}/pdf": {
+            "get": {
+                Summary:     "Render a stored itinerary with Paid, Due and Overdue badges",
+                OperationID: "renderItinerary",
+                Parameters:  []schema.Parameter{idParam, asOfParam, languageParam},
+                Responses: map[string]schema.Response{
+                    "200": {Description: "The rendered PDF", Content: pdfContent},
+                    "404": errResponse("No itinerary with this ID for the calling key"),
+                    "422": errResponse("Unsupported locale, or no font for its script"),
+                    "500": errResponse("Rendering failed"),
+                    "503": errResponse("Server is shutting down"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/usage": {
+            "get": {
+                Summary:     "Usage of the calling API key this month",
+                OperationID: "getUsage",
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Key usage", Content: schema.JSON(usage)},
+                    "401": errResponse("Missing or invalid API key"),
+                },
+                Security: apiKey,
+            },
+        },
+        "/admin/usage": {
+            "get": {
+                Summary:     "Usage of every configured API key",
+                OperationID: "getAllUsage",
+                Responses: map[string]schema.Response{
+                    "200": {Description: "Usage per key", Content: schema.JSON(&schema.Schema{
+                        Type:       "object",
+                        Properties: map[string]*schema.Schema{"keys": {Type: "array", Items: usage}},
+                    })},
+                    "403": errResponse("API key lacks the admin scope"),
+                },
+                Security: apiKey,
+            },
+        },
+    }
+
+    paths := make(map[string]schema.PathItem)
+    for suffix, item := range operations {
+        paths["/api/v1"+suffix] = item
+
+        legacy := make(schema.PathItem)
+        for method, op := range item {
+            legacyOp := *op
+            legacyOp.OperationID = op.OperationID + "Legacy"
+            legacyOp.Deprecated = true
+            legacy[method] = &legacyOp
+        }
+        paths["/api"+suffix] = legacy
+    }
+
+    return &schema.Document{
+        OpenAPI: "3.0.3",
+        Info: schema.Info{
+            Title:       "Vigovia PDF API",
+            Version:     "1.0.0",
+            Description: "Generates travel itinerary PDFs from itinerary data. Unversioned /api routes are deprecated aliases of /api/v1.",
+        },
+        Paths: paths,
+        Components: schema.Components{
+            Schemas: apiSchemas.Components,
+            SecuritySchemes: map[string]schema.SecurityScheme{
+                "ApiKeyAuth": {Type: "apiKey", In: "header", Name: auth.HeaderAPIKey},
+            },
+        },
+    }
+}
+
+func openAPIHandler(doc *schema.Document) http.HandlerFunc {
+    body, err := json.MarshalIndent(doc, "", "  ")
+    return func(w http.ResponseWriter, r *http.Request) {
+        if r.Method == http.MethodOptions {
+            w.WriteHeader(http.StatusOK)
+            return
+        }
+        if err != nil {
+            writeError(w, r, http.StatusInternalServerError, "OpenAPI unavailable", err.Error())
+            return
+        }
+        w.Header().Set("Content-Type", "application/json")
+        w.Write(body)
+    }
+}
@@ -0,0 +1,178 @@
+// payments/status.go
+package payments
+
+import (
+    "errors"
+    "fmt"
+    "sort"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+// Installment states shown as badges in the payment plan
+const (
+    StatusPaid    = "paid"
+    StatusDue     = "due"
+    StatusOverdue = "overdue"
+)
+
+// InstallmentStatus is how much of an installment has been paid
+type InstallmentStatus struct {
+    InstallmentID string        `json:"installmentId"`
+    Name          string        `json:"name"`
+    DueDate       datetime.Date `json:"dueDate"`
+    Amount        money.Money   `json:"amount"`
+    Paid          money.Money   `json:"paid"`
+    Outstanding   money.Money   `json:"outstanding"`
+    Status        string        `json:"status" schema:"enum=paid|due|overdue"`
+}
+
+// Summary is the payment position of an itinerary on a given day
+type Summary struct {
+    AsOf         datetime.Date       `json:"asOf"`
+    Currency     string              `json:"currency"`
+    Total        money.Money         `json:"total"`
+    Paid         money.Money         `json:"paid"`
+    Outstanding  money.Money         `json:"outstanding"`
+    Overdue      money.Money         `json:"overdue"`
+    Installments []InstallmentStatus `json:"installments"`
+    // Overpaid is what was received beyond the installments
+    Overpaid money.Money `json:"overpaid"`
+}
+
+// ByInstallment returns the status of each installment keyed by its ID
+func (s *Summary) ByInstallment() map[string]InstallmentStatus {
+    statuses := make(map[string]InstallmentStatus, len(s.Installments))
+    for _, st := range s.Installments {
+        statuses[st.InstallmentID] = st
+    }
+    return statuses
+}
+
+// Validate checks a payment against the payment plan it is recorded on
+func Validate(plan types.PaymentPlan, p types.Payment) error {
+    if p.Amount.MinorUnits <= 0 {
+        return errors.New("payments: amount must be positive")
+    }
+    if p.Date.IsZero() {
+        return errors.New("payments: date is required")
+    }
+    if currency := planCurrency(plan); p.Amount.Currency != currency {
+        return fmt.Errorf("payments: payment is in %s but the payment plan is in %s", p.Amount.Currency, currency)
+    }
+    if p.InstallmentID == "" {
+        return nil
+    }
+    for _, installment := range plan.Installments {
+        if installment.ID == p.InstallmentID {
+            return nil
+        }
+    }
+    return fmt.Errorf("payments: the payment plan has no installment %q", p.InstallmentID)
+}
+
+// Compute allocates payments to installments and works out what is still
+// outstanding on asOf. Payments naming an installment settle it first and
+// anything left over, like unassigned payments, settles the earliest unpaid
+// installments. Installments with an outstanding amount past their due date
+// are overdue.
+func Compute(plan types.PaymentPlan, paid []types.Payment, asOf datetime.Date) *Summary {
+    currency := planCurrency(plan)
+    zero := money.New(0, currency)
+    s := &Summary{
+        AsOf:        asOf,
+        Currency:    currency,
+        Total:       zero,
+        Paid:        zero,
+        Outstanding: zero,
+        Overdue:     zero,
+        Overpaid:    zero,
+    }
+
+    // Settle installments in due date order, keeping the plan's order for
+    // ties; undated installments go last
+    order := make([]int, len(plan.Installments))
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(a, b int) bool {
+        da, db := plan.Installments[order[a]].DueDate, plan.Installments[order[b]].DueDate
+        return !da.IsZero() && (db.IsZero() || da.Before(db))
+    })
+
+    s.Installments = make([]InstallmentStatus, len(plan.Installments))
+    index := make(map[string]int, len(plan.Installments))
+    for i, installment := range plan.Installments {
+        s.Installments[i] = InstallmentStatus{
+            InstallmentID: installment.ID,
+            Name:          installment.Name,
+            DueDate:       installment.DueDate,
+            Amount:        installment.Amount,
+            Paid:          zero,
+            Outstanding:   installment.Amount,
+        }
+        index[installment.ID] = i
+        s.Total = money.New(s.Total.MinorUnits+installment.Amount.MinorUnits, currency)
+    }
+    if len(plan.Installments) == 0 {
+        s.Total = money.New(plan.TotalAmount.MinorUnits, currency)
+    }
+
+    var pool int64
+    apply := func(st *InstallmentStatus, amount int64) int64 {
+        applied := min(amount, st.Outstanding.MinorUnits)
+        st.Paid = money.New(st.Paid.MinorUnits+applied, currency)
+        st.Outstanding = money.New(st.Outstanding.MinorUnits-applied, currency)
+        return amount - applied
+    }
+    for _, p := range paid {
+        if p.Amount.Currency != currency {
+            continue
+        }
+        s.Paid = money.New(s.Paid.MinorUnits+p.Amount.MinorUnits, currency)
+        if i, ok := index[p.InstallmentID]; ok && p.InstallmentID != "" {
+            pool += apply(&s.Installments[i], p.Amount.MinorUnits)
+        } else {
+            pool += p.Amount.MinorUnits
+        }
+    }
+    for _, i := range order {
+        if pool == 0 {
+            break
+        }
+        pool = apply(&s.Installments[i], pool)
+    }
+    if len(plan.Installments) == 0 {
+        pool = max(s.Paid.MinorUnits-s.Total.MinorUnits, 0)
+    }
+    s.Overpaid = money.New(pool, currency)
+
+    for i := range s.Installments {
+        st := &s.Installments[i]
+        switch {
+        case st.Outstanding.MinorUnits == 0:
+            st.Status = StatusPaid
+        case !st.DueDate.IsZero() && st.DueDate.Before(asOf):
+            st.Status = StatusOverdue
+            s.Overdue = money.New(s.Overdue.MinorUnits+st.Outstanding.MinorUnits, currency)
+        default:
+            st.Status = StatusDue
+        }
+    }
+    s.Outstanding = money.New(max(s.Total.MinorUnits-s.Paid.MinorUnits, 0), currency)
+    return s
+}
+
+// planCurrency is the currency the plan is paid in, taken from its installments
+func planCurrency(plan types.PaymentPlan) string {
+    for _, installment := range plan.Installments {
+        if installment.Amount.Currency != "" {
+            return installment.Amount.Currency
+        }
+    }
+    if plan.TotalAmount.Currency != "" {
+        return plan.TotalAmount.Currency
+    }
+    return money.DefaultCurrency
+}
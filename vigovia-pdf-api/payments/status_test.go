@@ -0,0 +1,117 @@
+package payments
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+func inr(rupees int64) money.Money {
+    return money.FromMajor(rupees, "INR")
+}
+
+func day(month time.Month, d int) datetime.Date {
+    return datetime.NewDate(2026, month, d)
+}
+
+// testPlan lists its installments out of due date order, with an undated one
+func testPlan() types.PaymentPlan {
+    return types.PaymentPlan{
+        TotalAmount: inr(105000),
+        Installments: []types.PaymentInstallment{
+            {ID: "balance", Name: "Balance", Amount: inr(50000), DueDate: day(time.December, 1)},
+            {ID: "deposit", Name: "Deposit", Amount: inr(20000), DueDate: day(time.October, 1)},
+            {ID: "second", Name: "Second", Amount: inr(30000), DueDate: day(time.November, 15)},
+            {ID: "extras", Name: "Extras", Amount: inr(5000)},
+        },
+    }
+}
+
+func pay(installmentID string, amount money.Money) types.Payment {
+    return types.Payment{InstallmentID: installmentID, Amount: amount, Date: day(time.October, 1)}
+}
+
+func TestCompute(t *testing.T) {
+    tests := []struct {
+        name     string
+        paid     []types.Payment
+        asOf     datetime.Date
+        statuses string
+        overdue  money.Money
+        overpaid money.Money
+    }{
+        {"nothing paid", nil, day(time.November, 20),
+            "balance due 0, deposit overdue 0, second overdue 0, extras due 0", inr(50000), inr(0)},
+        {"due today is not overdue", nil, day(time.November, 15),
+            "balance due 0, deposit overdue 0, second due 0, extras due 0", inr(20000), inr(0)},
+        {"unassigned payments settle the earliest", []types.Payment{pay("", inr(25000))}, day(time.November, 20),
+            "balance due 0, deposit paid 20000, second overdue 5000, extras due 0", inr(25000), inr(0)},
+        {"named installment first", []types.Payment{pay("balance", inr(10000)), pay("", inr(20000))}, day(time.November, 20),
+            "balance due 10000, deposit paid 20000, second overdue 0, extras due 0", inr(30000), inr(0)},
+        {"overpaid installment spills over", []types.Payment{pay("balance", inr(60000))}, day(time.November, 20),
+            "balance paid 50000, deposit overdue 10000, second overdue 0, extras due 0", inr(40000), inr(0)},
+        {"undated installments last", []types.Payment{pay("", inr(102000))}, day(time.December, 20),
+            "balance paid 50000, deposit paid 20000, second paid 30000, extras due 2000", inr(0), inr(0)},
+        {"more than the plan", []types.Payment{pay("", inr(100000)), pay("unknown", inr(10000))}, day(time.December, 20),
+            "balance paid 50000, deposit paid 20000, second paid 30000, extras paid 5000", inr(0), inr(5000)},
+        {"other currencies are left out", []types.Payment{pay("deposit", money.FromMajor(300, "USD"))}, day(time.November, 20),
+            "balance due 0, deposit overdue 0, second overdue 0, extras due 0", inr(50000), inr(0)},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            s := Compute(testPlan(), tt.paid, tt.asOf)
+            var got []string
+            var paid int64
+            for _, st := range s.Installments {
+                got = append(got, fmt.Sprintf("%s %s %d", st.InstallmentID, st.Status, st.Paid.MinorUnits/100))
+                if st.Paid.MinorUnits+st.Outstanding.MinorUnits != st.Amount.MinorUnits {
+                    t.Errorf("%s: paid %v and outstanding %v don't add up to %v", st.InstallmentID, st.Paid, st.Outstanding, st.Amount)
+                }
+                paid += st.Paid.MinorUnits
+            }
+            if strings.Join(got, ", ") != tt.statuses {
+                t.Errorf("installments\n%s\nwant\n%s", strings.Join(got, ", "), tt.statuses)
+            }
+            if s.Overdue != tt.overdue || s.Overpaid != tt.overpaid {
+                t.Errorf("overdue %v, overpaid %v; want %v and %v", s.Overdue, s.Overpaid, tt.overdue, tt.overpaid)
+            }
+            if s.Total != inr(105000) || s.Paid.MinorUnits != paid+s.Overpaid.MinorUnits ||
+                s.Outstanding.MinorUnits != s.Total.MinorUnits-paid {
+                t.Errorf("total %v, paid %v, outstanding %v", s.Total, s.Paid, s.Outstanding)
+            }
+        })
+    }
+}
+
+func TestComputeWithoutInstallments(t *testing.T) {
+    plan := types.PaymentPlan{TotalAmount: inr(40000)}
+    s := Compute(plan, []types.Payment{pay("", inr(25000)), pay("", inr(25000))}, day(time.November, 1))
+    if s.Total != inr(40000) || s.Paid != inr(50000) || s.Outstanding != inr(0) || s.Overpaid != inr(10000) || len(s.Installments) != 0 {
+        t.Errorf("summary %+v", s)
+    }
+}
+
+func TestValidate(t *testing.T) {
+    tests := []struct {
+        name    string
+        payment types.Payment
+        want    string
+    }{
+        {"valid", pay("deposit", inr(100)), ""},
+        {"unassigned", pay("", inr(100)), ""},
+        {"zero", pay("deposit", inr(0)), "must be positive"},
+        {"no date", types.Payment{Amount: inr(100)}, "date is required"},
+        {"other currency", pay("", money.FromMajor(100, "USD")), "in USD"},
+        {"unknown installment", pay("final", inr(100)), `no installment "final"`},
+    }
+    for _, tt := range tests {
+        err := Validate(testPlan(), tt.payment)
+        if (tt.want == "" && err != nil) || (tt.want != "" && (err == nil || !strings.Contains(err.Error(), tt.want))) {
+            t.Errorf("%s: error %v, want %q", tt.name, err, tt.want)
+        }
+    }
+}
@@ -0,0 +1,53 @@
+// payments/upi.go
+package payments
+
+import (
+    "fmt"
+    "net/url"
+    "strings"
+    "vigovia-pdf-api/money"
+)
+
+// UPIPayee is who installments are paid to over UPI
+type UPIPayee struct {
+    // ID is the payee's virtual payment address, e.g. vigovia@okaxis
+    ID   string `json:"id"`
+    Name string `json:"name"`
+}
+
+// UPILink is the upi://pay link payment apps open, from a scanned QR code, to
+// pay the amount to the payee with a note. UPI only pays rupees.
+func UPILink(payee UPIPayee, amount money.Money, note string) (string, error) {
+    if amount.Currency != "INR" {
+        return "", fmt.Errorf("UPI pays INR, not %s", amount.Currency)
+    }
+    if amount.MinorUnits <= 0 {
+        return "", fmt.Errorf("UPI amount must be positive, got %s", amount)
+    }
+    // Apps read the parameters in this order and expect spaces as %20
+    params := [][2]string{
+        {"pa", payee.ID},
+        {"pn", payee.Name},
+        {"am", fmt.Sprintf("%d.%02d", amount.MinorUnits/100, amount.MinorUnits%100)},
+        {"cu", "INR"},
+        {"tn", truncate(note, 50)},
+    }
+    var parts []string
+    for _, p := range params {
+        if p[1] == "" {
+            continue
+        }
+        value := strings.ReplaceAll(url.QueryEscape(p[1]), "+", "%20")
+        parts = append(parts, p[0]+"="+strings.ReplaceAll(value, "%40", "@"))
+    }
+    return "upi://pay?" + strings.Join(parts, "&"), nil
+}
+
+// truncate shortens s to at most n characters; apps reject long notes
+func truncate(s string, n int) string {
+    runes := []rune(strings.TrimSpace(s))
+    if len(runes) <= n {
+        return string(runes)
+    }
+    return strings.TrimSpace(string(runes[:n]))
+}
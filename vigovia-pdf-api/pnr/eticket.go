@@ -0,0 +1,184 @@
+// pnr/eticket.go
+package pnr
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/iata"
+    "vigovia-pdf-api/types"
+)
+
+// E-tickets and airline emails write each detail on a labelled line. A
+// "Flight" line starts a segment and the lines after it fill it in.
+var (
+    ticketLocator  = regexp.MustCompile(`(?i)^\s*(?:airline\s+)?(?:booking\s+ref(?:erence)?|pnr|confirmation(?:\s+(?:number|code))?|record\s+locator|reservation\s+code)\s*(?:no\.?|number)?\s*[:#-]?\s*([A-Z0-9]{6})\b`)
+    ticketFlight   = regexp.MustCompile(`(?i)^\s*flight(?:\s*(?:no\.?|number))?\s*[:#-]?\s*([A-Z0-9]{2})\s?-?(\d{1,4})\b[\s,:-]*(.*)$`)
+    ticketFrom     = regexp.MustCompile(`(?i)^\s*(?:departure|departs?|depart(?:ing)?\s+from|from)\s*[:-]\s*(.*)$`)
+    ticketTo       = regexp.MustCompile(`(?i)^\s*(?:arrival|arrives?|arriv(?:ing)?\s+at|to)\s*[:-]\s*(.*)$`)
+    ticketCabin    = regexp.MustCompile(`(?i)^\s*(?:class|cabin|cabin\s+class|travel\s+class)\s*[:-]\s*(.*)$`)
+    ticketCabinBag = regexp.MustCompile(`(?i)^\s*(?:cabin|hand)\s+(?:baggage|bag(?:gage)?\s+allowance)\s*[:-]\s*(.*)$`)
+    ticketBaggage  = regexp.MustCompile(`(?i)^\s*(?:checked\s+|check-in\s+)?baggage(?:\s+allowance)?\s*[:-]\s*(.*)$`)
+
+    placeCode = regexp.MustCompile(`\(([A-Z]{3})\)|\b([A-Z]{3})\b`)
+    clock     = regexp.MustCompile(`(?i)\b(\d{1,2})[:.h](\d{2})\s*(am|pm)?\b`)
+    terminal  = regexp.MustCompile(`(?i)\bterminal\s*([A-Z0-9]{1,3})\b|\bT([0-9][A-Z]?)\b`)
+    // dates as e-tickets write them: 10 Nov 2026, Nov 10, 2026, 2026-11-10,
+    // 10/11/2026 or 10NOV26
+    ticketDate = regexp.MustCompile(`(?i)\b(?:(?:mon|tue|wed|thu|fri|sat|sun)[a-z]*,?\s+)?(?:\d{4}-\d{2}-\d{2}|\d{1,2}[/.-]\d{1,2}[/.-]\d{4}|\d{1,2}(?:st|nd|rd|th)?\s?[a-z]{3,9}\.?,?\s?(?:'?\d{2,4})?|[a-z]{3,9}\.?\s\d{1,2}(?:st|nd|rd|th)?,?\s\d{4})\b`)
+    kilos      = regexp.MustCompile(`(?i)\b(\d{1,3})\s*(?:kg|kgs|k)\b`)
+    pieces     = regexp.MustCompile(`(?i)\b(\d)\s*(?:pc|pcs|piece|pieces|bags?)\b`)
+)
+
+// months are words ticketDate can mistake for airport codes
+var months = map[string]bool{"JAN": true, "FEB": true, "MAR": true, "APR": true, "MAY": true, "JUN": true, "JUL": true, "AUG": true, "SEP": true, "OCT": true, "NOV": true, "DEC": true}
+
+// ticketLine reads a labelled e-ticket line, reporting whether it was understood
+func (p *parser) ticketLine(line string) bool {
+    if m := ticketLocator.FindStringSubmatch(line); m != nil {
+        p.result.RecordLocator = strings.ToUpper(m[1])
+        return true
+    }
+    if m := ticketFlight.FindStringSubmatch(line); m != nil {
+        p.endTicketFlight()
+        p.setFormat(FormatETicket)
+        p.ticket = &types.Flight{Airline: strings.ToUpper(m[1]), FlightNumber: flightNumber(m[1], m[2])}
+        if name := strings.TrimSpace(m[3]); name != "" && strings.ContainsFunc(name, isAlnum) {
+            p.ticket.Airline = name
+        }
+        return true
+    }
+    if p.ticket == nil {
+        return false
+    }
+
+    f := p.ticket
+    switch {
+    case ticketCabinBag.MatchString(line):
+        if m := kilos.FindStringSubmatch(line); m != nil {
+            p.baggage().CabinKg, _ = strconv.Atoi(m[1])
+        }
+    case ticketBaggage.MatchString(line):
+        b := p.baggage()
+        if m := kilos.FindStringSubmatch(line); m != nil {
+            b.CheckedKg, _ = strconv.Atoi(m[1])
+        }
+        if m := pieces.FindStringSubmatch(line); m != nil {
+            b.CheckedPieces, _ = strconv.Atoi(m[1])
+        }
+    case ticketFrom.MatchString(line):
+        place, day, time, term := p.place(ticketFrom.FindStringSubmatch(line)[1], f.Date)
+        f.From, f.DepartureTerminal = place, term
+        if !day.IsZero() {
+            f.Date = day
+        }
+        f.Departure = at(f.Date, time)
+    case ticketTo.MatchString(line):
+        place, day, time, term := p.place(ticketTo.FindStringSubmatch(line)[1], f.Date)
+        f.To, f.ArrivalTerminal = place, term
+        if day.IsZero() {
+            day = f.Date
+        }
+        f.Arrival = at(day, time)
+    case ticketCabin.MatchString(line):
+        f.Cabin = cabinFromText(ticketCabin.FindStringSubmatch(line)[1])
+    default:
+        return false
+    }
+    return true
+}
+
+func (p *parser) baggage() *types.Baggage {
+    if p.ticket.Baggage == nil {
+        p.ticket.Baggage = &types.Baggage{}
+    }
+    return p.ticket.Baggage
+}
+
+// endTicketFlight adds the e-ticket flight being read, if any
+func (p *parser) endTicketFlight() {
+    if p.ticket != nil {
+        p.addFlight(*p.ticket)
+        p.ticket = nil
+    }
+}
+
+// place reads an airport code, date, HHMM time and terminal from the rest of a
+// Departure or Arrival line, e.g. "Delhi (DEL) 10 Nov 2026 04:15 Terminal 3".
+// Yearless dates are taken to be on or after near, or the parser's reference.
+func (p *parser) place(s string, near datetime.Date) (airport string, day datetime.Date, hhmm, term string) {
+    if near.IsZero() {
+        near = p.ref
+    }
+    if m := terminal.FindStringSubmatch(s); m != nil {
+        term = strings.ToUpper(m[1] + m[2])
+        s = strings.Replace(s, m[0], " ", 1)
+    }
+    if m := clock.FindStringSubmatch(s); m != nil {
+        hour, _ := strconv.Atoi(m[1])
+        min, _ := strconv.Atoi(m[2])
+        switch strings.ToLower(m[3]) {
+        case "am":
+            hour %= 12
+        case "pm":
+            hour = hour%12 + 12
+        }
+        if hour < 24 && min < 60 {
+            hhmm = fmt.Sprintf("%02d%02d", hour, min)
+        }
+        s = strings.Replace(s, m[0], " ", 1)
+    }
+    for _, m := range ticketDate.FindAllString(s, -1) {
+        if d, err := datetime.ParseDateNear(strings.TrimSpace(m), near); err == nil {
+            day = d
+            s = strings.Replace(s, m, " ", 1)
+            break
+        }
+    }
+    return airportCode(s), day, hhmm, term
+}
+
+// airportCode picks the airport code out of the rest of a place: a code in
+// brackets, then a code in the reference data, then any other three capitals.
+// Places without a code are looked up by name, so "Bangalore" becomes BLR.
+func airportCode(s string) string {
+    fallback := ""
+    for _, m := range placeCode.FindAllStringSubmatch(s, -1) {
+        code := m[1] + m[2]
+        if _, known := iata.LookupAirport(code); m[1] != "" || known {
+            return code
+        }
+        if fallback == "" && !months[code] {
+            fallback = code
+        }
+    }
+    if fallback != "" {
+        return fallback
+    }
+    name := strings.Trim(strings.Join(strings.Fields(s), " "), " ,-")
+    if a, ok := iata.FindAirport(name); ok {
+        return a.Code
+    }
+    return name
+}
+
+// cabinFromText reads a cabin such as "Business", "Economy (Y)" or "J"
+func cabinFromText(s string) string {
+    lower := strings.ToLower(s)
+    switch {
+    case strings.Contains(lower, "premium"):
+        return types.CabinPremiumEconomy
+    case strings.Contains(lower, "first"):
+        return types.CabinFirst
+    case strings.Contains(lower, "business"), strings.Contains(lower, "club"):
+        return types.CabinBusiness
+    case strings.Contains(lower, "economy"), strings.Contains(lower, "coach"):
+        return types.CabinEconomy
+    }
+    if s = strings.TrimSpace(s); len(s) == 1 {
+        return cabinForClass(s)
+    }
+    return ""
+}
@@ -0,0 +1,153 @@
+// pnr/gds.go
+package pnr
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/types"
+)
+
+var (
+    // amadeusSegment matches an air segment of an Amadeus PNR display:
+    //   3  EK 511 J 10NOV 2 DELDXB HK2  0415 0625  10NOV  E  EK/ABC123
+    amadeusSegment = regexp.MustCompile(`^\s*\d{1,2}\s+([A-Z0-9]{2})\s*(\d{1,4}[A-Z]?)\s+([A-Z])\s+(\d{1,2}[A-Z]{3})\s+(?:\d\*?|\*)?\s*([A-Z]{3})([A-Z]{3})\s+[A-Z]{2}\d{1,2}\s+(\d{4})\s+(\d{4})(\+\d)?(?:\s+(\d{1,2}[A-Z]{3})\b)?(.*)$`)
+    // amadeusLocator is the airline's own reference at the end of a segment
+    amadeusLocator = regexp.MustCompile(`\b[A-Z0-9]{2}/([A-Z0-9]{6})\b`)
+
+    // sabreSegment matches an air segment of a Sabre display:
+    //   1 EK 511J 10NOV 2 DELDXB HK2   415A  625A /DCEK*ABC123 /E
+    sabreSegment = regexp.MustCompile(`^\s*\d{1,2}\s+([A-Z0-9]{2})\s*(\d{1,4})([A-Z])\s+(\d{1,2}[A-Z]{3})\s+(?:\d\s+)?\*?([A-Z]{3})([A-Z]{3})[\s*]+[A-Z]{2}\d{1,2}\s+(\d{3,4}[APNM])\s+(\d{3,4}[APNM])(?:\s*([#¥+]\d)|\s+(\d{1,2}[A-Z]{3})\b)?(.*)$`)
+    sabreLocator  = regexp.MustCompile(`/DC[A-Z0-9]{2}\*([A-Z0-9]{6})\b`)
+
+    // passengers matches name lines such as "1.SHARMA/RAHUL MR  2.SHARMA/PRIYA MRS"
+    // (Amadeus) or " 1.1SHARMA/RAHUL MR" (Sabre)
+    passengerLine  = regexp.MustCompile(`^\s*(\d{1,2}\.\d?[A-Z][A-Z' -]*/[A-Z][A-Z ]*?\s*)+$`)
+    passengerEntry = regexp.MustCompile(`\d{1,2}\.\d?([A-Z][A-Z' -]*/[A-Z][A-Z ]*?)(?:\s{2,}|\s*$)`)
+
+    // amadeusHeader is the RP/ line that ends with the record locator
+    amadeusHeader = regexp.MustCompile(`^\s*RP/\S+.*\s([A-Z0-9]{6})\s*$`)
+    // sabreHeader is a line holding nothing but the record locator
+    sabreHeader = regexp.MustCompile(`^\s*([A-Z0-9]{6})\s*$`)
+)
+
+// gdsLine reads a line of an Amadeus or Sabre display, reporting whether it
+// was understood
+func (p *parser) gdsLine(line string) bool {
+    switch {
+    case p.amadeus(line), p.sabre(line):
+        return true
+    case passengerLine.MatchString(line):
+        for _, m := range passengerEntry.FindAllStringSubmatch(line, -1) {
+            p.result.Passengers = append(p.result.Passengers, passengerName(m[1]))
+        }
+        return true
+    }
+    if m := amadeusHeader.FindStringSubmatch(line); m != nil {
+        p.setFormat(FormatAmadeus)
+        p.result.RecordLocator = m[1]
+        return true
+    }
+    if m := sabreHeader.FindStringSubmatch(line); m != nil && p.result.RecordLocator == "" && len(p.result.Flights) == 0 {
+        p.result.RecordLocator = m[1]
+        return true
+    }
+    return false
+}
+
+func (p *parser) amadeus(line string) bool {
+    m := amadeusSegment.FindStringSubmatch(line)
+    if m == nil {
+        return false
+    }
+    day, err := datetime.ParseDateNear(m[4], p.ref)
+    if err != nil {
+        return false
+    }
+    p.endTicketFlight()
+    p.setFormat(FormatAmadeus)
+    f := types.Flight{
+        Airline:      m[1],
+        FlightNumber: flightNumber(m[1], m[2]),
+        Date:         day,
+        From:         m[5],
+        To:           m[6],
+        Cabin:        cabinForClass(m[3]),
+        Departure:    at(day, m[7]),
+    }
+    arrivalDay := day.AddDays(dayOffset(m[9]))
+    if m[10] != "" {
+        if d, err := datetime.ParseDateNear(m[10], day); err == nil {
+            arrivalDay = d
+        }
+    }
+    f.Arrival = at(arrivalDay, m[8])
+    if l := amadeusLocator.FindStringSubmatch(m[11]); l != nil {
+        f.PNR = l[1]
+    }
+    p.addFlight(f)
+    return true
+}
+
+func (p *parser) sabre(line string) bool {
+    m := sabreSegment.FindStringSubmatch(line)
+    if m == nil {
+        return false
+    }
+    day, err := datetime.ParseDateNear(m[4], p.ref)
+    if err != nil {
+        return false
+    }
+    dep, depOK := sabreTime(m[7])
+    arr, arrOK := sabreTime(m[8])
+    if !depOK || !arrOK {
+        return false
+    }
+    p.endTicketFlight()
+    p.setFormat(FormatSabre)
+    f := types.Flight{
+        Airline:      m[1],
+        FlightNumber: flightNumber(m[1], m[2]),
+        Date:         day,
+        From:         m[5],
+        To:           m[6],
+        Cabin:        cabinForClass(m[3]),
+        Departure:    at(day, dep),
+    }
+    arrivalDay := day.AddDays(dayOffset(m[9]))
+    if m[10] != "" {
+        if d, err := datetime.ParseDateNear(m[10], day); err == nil {
+            arrivalDay = d
+        }
+    }
+    f.Arrival = at(arrivalDay, arr)
+    if l := sabreLocator.FindStringSubmatch(m[11]); l != nil {
+        f.PNR = l[1]
+    }
+    p.addFlight(f)
+    return true
+}
+
+// sabreTime converts a Sabre time such as 415A, 120P, 1200N (noon) or 1200M
+// (midnight) to HHMM
+func sabreTime(s string) (string, bool) {
+    suffix := s[len(s)-1]
+    n, err := strconv.Atoi(s[:len(s)-1])
+    if err != nil {
+        return "", false
+    }
+    hour, min := n/100, n%100
+    if hour < 1 || hour > 12 || min > 59 {
+        return "", false
+    }
+    switch suffix {
+    case 'A', 'M':
+        hour %= 12
+    case 'P', 'N':
+        if hour != 12 {
+            hour += 12
+        }
+    }
+    return fmt.Sprintf("%02d%02d", hour, min), true
+}
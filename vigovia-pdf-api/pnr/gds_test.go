@@ -0,0 +1,139 @@
+package pnr
+
+import (
+    "testing"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/types"
+)
+
+// ref is the day the bookings are pasted, a few days before the year turns
+var ref = datetime.NewDate(2026, 12, 20)
+
+func TestParseSegments(t *testing.T) {
+    tests := []struct {
+        name   string
+        line   string
+        format string
+        // Flight number, cabin, departure, arrival and the airline's locator
+        flight, cabin, departure, arrival, pnr string
+    }{
+        {"amadeus", "  3  EK 511 J 22DEC 2 DELDXB HK2  0415 0625  E  EK/ABC123", FormatAmadeus,
+            "EK511", types.CabinBusiness, "2026-12-22T04:15:00", "2026-12-22T06:25:00", "ABC123"},
+        {"amadeus next day", "  4  AI 101 Y 30DEC 3 DELJFK HK1  2355 0600+1 E AI/XYZ789", FormatAmadeus,
+            "AI101", types.CabinEconomy, "2026-12-30T23:55:00", "2026-12-31T06:00:00", "XYZ789"},
+        {"amadeus next day into the new year", "  5  AI 101 Y 31DEC 4 DELJFK HK1  2355 0600+1 E AI/XYZ789", FormatAmadeus,
+            "AI101", types.CabinEconomy, "2026-12-31T23:55:00", "2027-01-01T06:00:00", "XYZ789"},
+        {"amadeus arrival date", "  6  QR 557 W 31DEC 4 BOMDOH HK2  2340 0105  01JAN  E  QR/QRX5K2", FormatAmadeus,
+            "QR557", types.CabinPremiumEconomy, "2026-12-31T23:40:00", "2027-01-01T01:05:00", "QRX5K2"},
+        {"amadeus yearless date after the new year", "  7  6E 023 Y 05JAN 1 BLRGOI HK2  0710 0820", FormatAmadeus,
+            "6E23", types.CabinEconomy, "2027-01-05T07:10:00", "2027-01-05T08:20:00", ""},
+        {"sabre", " 1 EK 511J 22DEC 2 DELDXB HK2   415A  625A /DCEK*ABC123 /E", FormatSabre,
+            "EK511", types.CabinBusiness, "2026-12-22T04:15:00", "2026-12-22T06:25:00", "ABC123"},
+        {"sabre next day", " 2 AI 101Y 30DEC 3 DELJFK HK1  1155P  600A#1 /DCAI*XYZ789 /E", FormatSabre,
+            "AI101", types.CabinEconomy, "2026-12-30T23:55:00", "2026-12-31T06:00:00", "XYZ789"},
+        {"sabre next day into the new year", " 3 AI 101Y 31DEC 4 DELJFK HK1  1155P  600A#1 /DCAI*XYZ789 /E", FormatSabre,
+            "AI101", types.CabinEconomy, "2026-12-31T23:55:00", "2027-01-01T06:00:00", "XYZ789"},
+        {"sabre noon and after midnight", " 4 EK 512Y 23DEC 3 DXBDEL HK2  1200N  1205A+1 /DCEK*ABC124 /E", FormatSabre,
+            "EK512", types.CabinEconomy, "2026-12-23T12:00:00", "2026-12-24T00:05:00", "ABC124"},
+        {"sabre midnight and half past noon", " 5 6E 1402Y 24DEC 4 GOIBOM HK2  1200M  1230P", FormatSabre,
+            "6E1402", types.CabinEconomy, "2026-12-24T00:00:00", "2026-12-24T12:30:00", ""},
+        {"sabre arrival date", " 6 QR 557W 31DEC 4 BOMDOH HK2  1140P  105A 01JAN /DCQR*QRX5K2 /E", FormatSabre,
+            "QR557", types.CabinPremiumEconomy, "2026-12-31T23:40:00", "2027-01-01T01:05:00", "QRX5K2"},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r := Parse(tt.line, ref)
+            if r.Format != tt.format || len(r.Flights) != 1 {
+                t.Fatalf("format %s with %d flights, unparsed %q", r.Format, len(r.Flights), r.Unparsed)
+            }
+            f := r.Flights[0]
+            got := []string{f.FlightNumber, f.Cabin, f.Departure.String(), f.Arrival.String(), f.PNR}
+            want := []string{tt.flight, tt.cabin, tt.departure, tt.arrival, tt.pnr}
+            for i := range want {
+                if got[i] != want[i] {
+                    t.Errorf("flight %q, want %q", got, want)
+                    break
+                }
+            }
+        })
+    }
+}
+
+func TestSabreTime(t *testing.T) {
+    tests := []struct {
+        in   string
+        want string
+        ok   bool
+    }{
+        {"415A", "0415", true},
+        {"1205A", "0005", true},
+        {"1200M", "0000", true},
+        {"1200N", "1200", true},
+        {"1230P", "1230", true},
+        {"120P", "1320", true},
+        {"1159P", "2359", true},
+        {"1300P", "", false},
+        {"060A", "", false},
+        {"975A", "", false},
+    }
+    for _, tt := range tests {
+        got, ok := sabreTime(tt.in)
+        if got != tt.want || ok != tt.ok {
+            t.Errorf("sabreTime(%q) = %q, %t, want %q, %t", tt.in, got, ok, tt.want, tt.ok)
+        }
+    }
+}
+
+func TestParseDisplays(t *testing.T) {
+    tests := []struct {
+        name       string
+        text       string
+        format     string
+        locator    string
+        passengers []string
+        flights    []string
+    }{
+        {"amadeus", `RP/DELVS3100/DELVS3100            AA/SU  15DEC26/0830Z   XYZ9K2
+  1.SHARMA/RAHUL MR   2.SHARMA/PRIYA MRS
+  3  EK 511 J 30DEC 3 DELDXB HK2  0415 0625  E  EK/ABC123
+  4  EK 201 J 31DEC 4 DXBJFK HK2  0830 1410  E  EK/ABC123
+  5  AI 102 Y 05JAN 2 JFKDEL HK2  2355 0040+2 E
+  6 AP DEL 011 2345 6789 - VIGOVIA TRAVEL`, FormatAmadeus, "XYZ9K2",
+            []string{"Rahul Sharma", "Priya Sharma"},
+            []string{"EK511 ABC123 2026-12-30", "EK201 ABC123 2026-12-31", "AI102 XYZ9K2 2027-01-05"}},
+        {"sabre", `LMNOPQ
+ 1.1SHARMA/RAHUL MR
+ 1 EK 511J 30DEC 3 DELDXB HK2   415A  625A /DCEK*ABC123 /E
+ 2 AI 102Y 05JAN 2 JFKDEL HK2  1155P 1240A¥2 /E`, FormatSabre, "LMNOPQ",
+            []string{"Rahul Sharma"},
+            []string{"EK511 ABC123 2026-12-30", "AI102 LMNOPQ 2027-01-05"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            r := Parse(tt.text, ref)
+            if r.Format != tt.format || r.RecordLocator != tt.locator {
+                t.Errorf("format %s, locator %s", r.Format, r.RecordLocator)
+            }
+            if len(r.Passengers) != len(tt.passengers) {
+                t.Fatalf("passengers %q, want %q", r.Passengers, tt.passengers)
+            }
+            for i := range tt.passengers {
+                if r.Passengers[i] != tt.passengers[i] {
+                    t.Errorf("passengers %q, want %q", r.Passengers, tt.passengers)
+                }
+            }
+            if len(r.Flights) != len(tt.flights) {
+                t.Fatalf("%d flights, want %d; unparsed %q", len(r.Flights), len(tt.flights), r.Unparsed)
+            }
+            for i, f := range r.Flights {
+                if got := f.FlightNumber + " " + f.PNR + " " + f.Date.String(); got != tt.flights[i] {
+                    t.Errorf("flight %d is %q, want %q", i, got, tt.flights[i])
+                }
+            }
+            // The last flight lands two days later, after the new year
+            if last := r.Flights[len(r.Flights)-1]; last.Arrival.Date().String() != "2027-01-07" {
+                t.Errorf("last arrival %s, want 2027-01-07", last.Arrival)
+            }
+        })
+    }
+}
@@ -0,0 +1,139 @@
+// pnr/pnr.go
+package pnr
+
+import (
+    "fmt"
+    "regexp"
+    "strconv"
+    "strings"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/types"
+)
+
+// Formats Parse recognises
+const (
+    FormatAmadeus = "amadeus"
+    FormatSabre   = "sabre"
+    FormatETicket = "eticket"
+    FormatUnknown = "unknown"
+)
+
+// Result is what was read from a booking confirmation. Unparsed holds the
+// lines that weren't understood, so agents can check nothing was missed.
+type Result struct {
+    Format        string         `json:"format" schema:"enum=amadeus|sabre|eticket|unknown"`
+    RecordLocator string         `json:"recordLocator,omitempty"`
+    Passengers    []string       `json:"passengers"`
+    Flights       []types.Flight `json:"flights"`
+    Unparsed      []string       `json:"unparsed"`
+}
+
+// Parse reads flight segments from text pasted from a GDS or an airline:
+// an Amadeus PNR display, a Sabre *I or *A display, or an e-ticket or
+// confirmation email with labelled lines such as "Departure: DEL 10 Nov 2026
+// 04:15 Terminal 3". Dates without a year are taken to be on or after ref.
+// Times are read as local times without a zone.
+func Parse(text string, ref datetime.Date) *Result {
+    p := &parser{ref: ref, result: &Result{Format: FormatUnknown, Passengers: []string{}, Flights: []types.Flight{}, Unparsed: []string{}}}
+    for _, line := range strings.Split(strings.ReplaceAll(text, "\r", ""), "\n") {
+        if !strings.ContainsFunc(line, isAlnum) {
+            continue
+        }
+        if !p.gdsLine(line) && !p.ticketLine(line) {
+            p.result.Unparsed = append(p.result.Unparsed, strings.TrimSpace(line))
+        }
+    }
+    p.endTicketFlight()
+
+    r := p.result
+    for i := range r.Flights {
+        f := &r.Flights[i]
+        f.ID = fmt.Sprintf("flight-%d", i+1)
+        if f.PNR == "" {
+            f.PNR = r.RecordLocator
+        }
+    }
+    return r
+}
+
+type parser struct {
+    ref    datetime.Date
+    result *Result
+    // ticket is the e-ticket flight whose labelled lines are being read
+    ticket *types.Flight
+}
+
+func (p *parser) setFormat(format string) {
+    if p.result.Format == FormatUnknown {
+        p.result.Format = format
+    }
+}
+
+func (p *parser) addFlight(f types.Flight) {
+    p.result.Flights = append(p.result.Flights, f)
+}
+
+func isAlnum(r rune) bool {
+    return r >= 'A' && r <= 'Z' || r >= 'a' && r <= 'z' || r >= '0' && r <= '9'
+}
+
+// flightNumber writes an airline code and number the way agents do, e.g. AI123
+func flightNumber(airline, number string) string {
+    digits := strings.TrimLeft(number, "0")
+    if digits == "" || digits[0] < '0' || digits[0] > '9' {
+        digits = "0" + digits
+    }
+    return strings.ToUpper(airline) + strings.ToUpper(digits)
+}
+
+// bookingClasses maps booking class letters to cabins; other letters are
+// economy
+var bookingClasses = map[string]string{
+    "F": types.CabinFirst, "A": types.CabinFirst, "P": types.CabinFirst,
+    "J": types.CabinBusiness, "C": types.CabinBusiness, "D": types.CabinBusiness,
+    "I": types.CabinBusiness, "Z": types.CabinBusiness, "R": types.CabinBusiness,
+    "W": types.CabinPremiumEconomy,
+}
+
+func cabinForClass(class string) string {
+    if cabin, ok := bookingClasses[strings.ToUpper(class)]; ok {
+        return cabin
+    }
+    return types.CabinEconomy
+}
+
+// at combines a day and a 24 hour HHMM time into a floating local time
+func at(day datetime.Date, hhmm string) datetime.DateTime {
+    if day.IsZero() || len(hhmm) != 4 {
+        return datetime.DateTime{}
+    }
+    dt, err := datetime.ParseDateTime(day.String() + "T" + hhmm[:2] + ":" + hhmm[2:])
+    if err != nil {
+        return datetime.DateTime{}
+    }
+    return dt
+}
+
+// dayOffset reads a next day marker such as +1, #1 or ¥1
+func dayOffset(marker string) int {
+    marker = strings.TrimLeft(marker, "+#¥")
+    n, _ := strconv.Atoi(marker)
+    return n
+}
+
+var titles = regexp.MustCompile(`\s+(MR|MRS|MS|MSTR|MISS|DR|CHD|INF)$`)
+
+// passengerName turns a GDS name such as SHARMA/RAHUL MR into Rahul Sharma
+func passengerName(gds string) string {
+    gds = titles.ReplaceAllString(strings.TrimSpace(gds), "")
+    last, first, _ := strings.Cut(gds, "/")
+    return strings.TrimSpace(titleCase(first) + " " + titleCase(last))
+}
+
+func titleCase(s string) string {
+    words := strings.Fields(strings.ToLower(s))
+    for i, w := range words {
+        words[i] = strings.ToUpper(w[:1]) + w[1:]
+    }
+    return strings.Join(words, " ")
+}
@@ -0,0 +1,231 @@
+// pricing/pricing.go
+package pricing
+
+import (
+    "math"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/tax"
+    "vigovia-pdf-api/types"
+)
+
+const (
+    CategoryActivities = "activities"
+    CategoryTransfers  = "transfers"
+)
+
+// DayCost is the cost of everything priced on one itinerary day
+type DayCost struct {
+    Day        int           `json:"day"`
+    Date       datetime.Date `json:"date"`
+    Activities money.Money   `json:"activities"`
+    Transfers  money.Money   `json:"transfers"`
+    Total      money.Money   `json:"total"`
+}
+
+// Line is one labelled amount in the adjustments below the subtotal
+type Line struct {
+    Label  i18n.Message `json:"label"`
+    Amount money.Money  `json:"amount"`
+}
+
+// Breakdown is the computed package price and how it was reached
+type Breakdown struct {
+    Currency    string                 `json:"currency"`
+    Days        []DayCost              `json:"days"`
+    Categories  map[string]money.Money `json:"categories"`
+    Subtotal    money.Money            `json:"subtotal"`
+    Adjustments []Line                 `json:"adjustments"`
+    // PreTax is the package price after markup and discounts
+    PreTax      money.Money            `json:"preTax"`
+    Total       money.Money            `json:"total"`
+    Travellers  int                    `json:"travellers"`
+    PerPerson   money.Money            `json:"perPerson"`
+    // Quoted is PaymentPlan.TotalAmount; Difference is Quoted - Total
+    Quoted     money.Money `json:"quoted"`
+    Difference money.Money `json:"difference"`
+    Warnings   []i18n.Message `json:"warnings,omitempty"`
+}
+
+// Reconciled reports whether the computed total matches the quoted total and
+// nothing else looked off
+func (b *Breakdown) Reconciled() bool {
+    return len(b.Warnings) == 0
+}
+
+// Calculator computes breakdowns; Rates converts prices quoted in another
+// currency into the payment plan's currency
+type Calculator struct {
+    Rates money.RateTable
+}
+
+// Compute builds the cost breakdown for an itinerary. Problems that make the
+// numbers unreliable are reported as warnings rather than errors so the PDF
+// can still show them to the agent.
+func (c Calculator) Compute(data types.ItineraryData) *Breakdown {
+    currency := data.PaymentPlan.TotalAmount.Currency
+    if currency == "" {
+        currency = money.DefaultCurrency
+    }
+    if data.ExchangeRates != nil {
+        c.Rates = c.Rates.Merge(*data.ExchangeRates)
+    }
+
+    b := &Breakdown{
+        Currency:   currency,
+        Categories: map[string]money.Money{},
+        Subtotal:   money.New(0, currency),
+        Travellers: data.TripDetails.NumberOfTravelers,
+        Quoted:     data.PaymentPlan.TotalAmount,
+    }
+    zero := money.New(0, currency)
+    b.Categories[CategoryActivities] = zero
+    b.Categories[CategoryTransfers] = zero
+
+    for _, day := range data.DailyItinerary {
+        dc := DayCost{Day: day.Day, Date: day.Date, Activities: zero, Transfers: zero}
+        for _, activity := range day.Activities {
+            dc.Activities = c.add(b, dc.Activities, activity.Price, i18n.Msg("pricing.item.activity", day.Day, activity.Name))
+        }
+        for _, transfer := range day.Transfers {
+            dc.Transfers = c.add(b, dc.Transfers, transfer.Price, i18n.Msg("pricing.item.transfer", day.Day, transfer.Type))
+        }
+        dc.Total = b.plus(dc.Activities, dc.Transfers)
+        b.Categories[CategoryActivities] = b.plus(b.Categories[CategoryActivities], dc.Activities)
+        b.Categories[CategoryTransfers] = b.plus(b.Categories[CategoryTransfers], dc.Transfers)
+        b.Subtotal = b.plus(b.Subtotal, dc.Total)
+        b.Days = append(b.Days, dc)
+    }
+
+    rules := types.PricingRules{}
+    if data.Pricing != nil {
+        rules = *data.Pricing
+    }
+    running := b.Subtotal
+
+    if rules.MarkupPercent > 0 {
+        markup := percentOf(b.Subtotal, rules.MarkupPercent)
+        b.Adjustments = append(b.Adjustments, Line{Label: i18n.Msg("pricing.markup", rules.MarkupPercent), Amount: markup})
+        running = b.plus(running, markup)
+    }
+
+    for _, discount := range rules.Discounts {
+        var amount money.Money
+        label := i18n.Msg("pricing.discount")
+        if discount.Name != "" {
+            label = i18n.Text(discount.Name)
+        }
+        switch {
+        case discount.Amount != nil:
+            amount = c.convert(b, *discount.Amount, label)
+        case discount.Percent > 0:
+            amount = percentOf(running, discount.Percent)
+            label = i18n.Msg("pricing.percent", label, discount.Percent)
+        default:
+            continue
+        }
+        amount = money.New(-amount.MinorUnits, currency)
+        b.Adjustments = append(b.Adjustments, Line{Label: label, Amount: amount})
+        running = b.plus(running, amount)
+    }
+    if running.MinorUnits < 0 {
+        b.Warnings = append(b.Warnings, i18n.Msg("pricing.warning.discounts"))
+    }
+
+    b.PreTax = running
+
+    if tax.TotalIsPreTax(data.PaymentPlan) {
+        // Statutory GST and TCS replace the flat percentages
+        result, err := tax.Compute(running, *data.PaymentPlan.Tax, tax.DefaultRules())
+        if err != nil {
+            b.Warnings = append(b.Warnings, i18n.Msg("pricing.warning.tax", err.Error()))
+        } else {
+            for _, line := range result.Lines {
+                b.Adjustments = append(b.Adjustments, Line{Label: line.Label, Amount: line.Amount})
+            }
+            running = result.Payable
+        }
+    } else {
+        if rules.GSTPercent > 0 {
+            gst := percentOf(running, rules.GSTPercent)
+            b.Adjustments = append(b.Adjustments, Line{Label: i18n.Msg("pricing.gst", rules.GSTPercent), Amount: gst})
+            running = b.plus(running, gst)
+        }
+        if rules.TCSPercent > 0 {
+            tcs := percentOf(running, rules.TCSPercent)
+            b.Adjustments = append(b.Adjustments, Line{Label: i18n.Msg("pricing.tcs", rules.TCSPercent), Amount: tcs})
+            running = b.plus(running, tcs)
+        }
+    }
+
+    b.Total = running
+    if b.Travellers > 0 {
+        b.PerPerson = money.New(int64(math.Round(float64(b.Total.MinorUnits)/float64(b.Travellers))), currency)
+    }
+
+    c.reconcile(b, data.PaymentPlan)
+    return b
+}
+
+// reconcile compares the computed price with the payment plan. When the quoted
+// total is the pre-tax package price (see tax.TotalIsPreTax) installments must
+// cover the amount payable including taxes.
+func (c Calculator) reconcile(b *Breakdown, plan types.PaymentPlan) {
+    quoted := c.convert(b, plan.TotalAmount, i18n.Msg("pricing.item.quoted"))
+    computed, payable := b.Total, quoted
+    if tax.TotalIsPreTax(plan) {
+        computed = b.PreTax
+        payable = b.plus(quoted, b.plus(b.Total, money.New(-b.PreTax.MinorUnits, b.Currency)))
+    }
+    b.Difference = money.New(quoted.MinorUnits-computed.MinorUnits, b.Currency)
+    if b.Difference.MinorUnits != 0 && (computed.MinorUnits != 0 || quoted.MinorUnits != 0) {
+        b.Warnings = append(b.Warnings, i18n.Msg("pricing.warning.quoted",
+            quoted, computed, b.Difference))
+    }
+
+    if len(plan.Installments) > 0 {
+        sum := money.New(0, b.Currency)
+        for _, installment := range plan.Installments {
+            sum = b.plus(sum, c.convert(b, installment.Amount, i18n.Msg("pricing.item.installment", installment.Name)))
+        }
+        if sum.MinorUnits != payable.MinorUnits {
+            b.Warnings = append(b.Warnings, i18n.Msg("pricing.warning.installments",
+                sum, payable))
+        }
+    }
+}
+
+// add converts an item price into the breakdown currency and adds it to sum
+func (c Calculator) add(b *Breakdown, sum, price money.Money, what i18n.Message) money.Money {
+    return b.plus(sum, c.convert(b, price, what))
+}
+
+func (c Calculator) convert(b *Breakdown, m money.Money, what i18n.Message) money.Money {
+    if m.Currency == "" || m.Currency == b.Currency {
+        return money.New(m.MinorUnits, b.Currency)
+    }
+    converted, err := c.Rates.Convert(m, b.Currency)
+    if err != nil {
+        b.Warnings = append(b.Warnings, i18n.Msg("pricing.warning.currency", what, m.Currency, b.Currency))
+        return money.New(0, b.Currency)
+    }
+    return converted
+}
+
+// Helper function to take a percentage of an amount, rounded to the minor unit
+func percentOf(m money.Money, percent float64) money.Money {
+    return m.MulRat(int64(math.Round(percent*100)), 10000)
+}
+
+// plus adds two amounts of the breakdown currency. Amounts that can't be
+// added are reported as a warning and the second is left out, so a bad
+// amount never stops the breakdown.
+func (b *Breakdown) plus(x, y money.Money) money.Money {
+    sum, err := x.Add(y)
+    if err != nil {
+        b.Warnings = append(b.Warnings, i18n.Msg("pricing.warning.unaddable", err.Error()))
+        return x
+    }
+    return sum
+}
@@ -0,0 +1,165 @@
+package pricing
+
+import (
+    "strings"
+    "testing"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+func inr(rupees int64) money.Money {
+    return money.FromMajor(rupees, "INR")
+}
+
+// trip prices ₹6,500 of activities and ₹800 of transfers over two days
+func trip() types.ItineraryData {
+    return types.ItineraryData{
+        TripDetails: types.TripDetails{NumberOfTravelers: 2},
+        DailyItinerary: []types.DayItinerary{
+            {Day: 1, Activities: []types.Activity{{Name: "Fort", Price: inr(2000)}, {Name: "Cruise", Price: inr(1500)}},
+                Transfers: []types.Transfer{{Type: "Airport", Price: inr(800)}}},
+            {Day: 2, Activities: []types.Activity{{Name: "Spice farm", Price: inr(3000)}}},
+        },
+        PaymentPlan: types.PaymentPlan{TotalAmount: inr(7300)},
+    }
+}
+
+// texts returns the English text of messages
+func texts(msgs []i18n.Message) []string {
+    out := make([]string, len(msgs))
+    for i, msg := range msgs {
+        out[i] = msg.String()
+    }
+    return out
+}
+
+func TestComputeSums(t *testing.T) {
+    b := Calculator{}.Compute(trip())
+
+    if b.Currency != "INR" || b.Subtotal != inr(7300) || b.Total != inr(7300) || b.PreTax != inr(7300) {
+        t.Errorf("subtotal %v, pre-tax %v, total %v in %s", b.Subtotal, b.PreTax, b.Total, b.Currency)
+    }
+    if b.Categories[CategoryActivities] != inr(6500) || b.Categories[CategoryTransfers] != inr(800) {
+        t.Errorf("categories %v", b.Categories)
+    }
+    if len(b.Days) != 2 || b.Days[0].Total != inr(4300) || b.Days[0].Transfers != inr(800) || b.Days[1].Total != inr(3000) {
+        t.Errorf("days %+v", b.Days)
+    }
+    if b.PerPerson != inr(3650) || len(b.Adjustments) != 0 {
+        t.Errorf("per person %v, adjustments %v", b.PerPerson, b.Adjustments)
+    }
+    if !b.Reconciled() || !b.Difference.IsZero() {
+        t.Errorf("not reconciled: %v", texts(b.Warnings))
+    }
+}
+
+func TestComputeAdjustments(t *testing.T) {
+    data := trip()
+    data.TripDetails.NumberOfTravelers = 3
+    fixed := inr(500)
+    data.Pricing = &types.PricingRules{
+        MarkupPercent: 10,
+        Discounts: []types.Discount{
+            {Name: "Early bird", Percent: 5},
+            {Amount: &fixed},
+            {Name: "Nothing off"},
+        },
+        GSTPercent: 5,
+        TCSPercent: 5,
+    }
+    // 7,300 + 730 markup - 401.50 - 500 = 7,128.50; GST 356.43 (356.425
+    // rounded away from zero), then TCS 374.25 on 7,484.93
+    data.PaymentPlan = types.PaymentPlan{
+        TotalAmount:  money.New(785918, "INR"),
+        Installments: []types.PaymentInstallment{{Name: "Deposit", Amount: inr(3000)}, {Name: "Balance", Amount: money.New(485918, "INR")}},
+    }
+    b := Calculator{}.Compute(data)
+
+    want := []string{"Markup (10%): ₹730", "Early bird (5%): -₹401.50", "Discount: -₹500", "GST (5%): ₹356.43", "TCS (5%): ₹374.25"}
+    var got []string
+    for _, line := range b.Adjustments {
+        got = append(got, line.Label.String()+": "+line.Amount.String())
+    }
+    if strings.Join(got, "\n") != strings.Join(want, "\n") {
+        t.Errorf("adjustments\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+    }
+    if b.PreTax != money.New(712850, "INR") || b.Total != money.New(785918, "INR") || b.PerPerson != money.New(261973, "INR") {
+        t.Errorf("pre-tax %v, total %v, per person %v", b.PreTax, b.Total, b.PerPerson)
+    }
+    if !b.Reconciled() {
+        t.Errorf("not reconciled: %v", texts(b.Warnings))
+    }
+}
+
+func TestReconcile(t *testing.T) {
+    tests := []struct {
+        name       string
+        change     func(d *types.ItineraryData)
+        difference money.Money
+        warnings   []string
+    }{
+        {"quoted total differs", func(d *types.ItineraryData) { d.PaymentPlan.TotalAmount = inr(8000) }, inr(700),
+            []string{"Quoted total ₹8,000 differs from the computed total ₹7,300 by ₹700"}},
+        {"installments fall short", func(d *types.ItineraryData) {
+            d.PaymentPlan.Installments = []types.PaymentInstallment{{Amount: inr(3000)}, {Amount: inr(4000)}}
+        }, inr(0), []string{"Installments add up to ₹7,000 but the amount payable is ₹7,300"}},
+        {"nothing priced or quoted", func(d *types.ItineraryData) {
+            d.DailyItinerary, d.PaymentPlan.TotalAmount = nil, money.Money{}
+        }, inr(0), nil},
+        {"price in another currency", func(d *types.ItineraryData) {
+            d.ExchangeRates = &money.RateTable{Base: "INR", Rates: map[string]float64{"USD": 0.0125}}
+            d.DailyItinerary[1].Activities[0].Price = money.New(3750, "USD")
+        }, inr(0), nil},
+        {"price without a rate", func(d *types.ItineraryData) {
+            d.DailyItinerary[1].Activities[0].Price = money.New(3000, "EUR")
+        }, inr(3000), []string{
+            `Day 2 activity "Spice farm" is priced in EUR and can't be converted to INR; it was left out`,
+            "Quoted total ₹7,300 differs from the computed total ₹4,300 by ₹3,000",
+        }},
+        {"discounts exceed the price", func(d *types.ItineraryData) {
+            discount := inr(8000)
+            d.Pricing = &types.PricingRules{Discounts: []types.Discount{{Amount: &discount}}}
+            d.PaymentPlan.TotalAmount = inr(-700)
+        }, inr(0), []string{"Discounts exceed the package price"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            data := trip()
+            tt.change(&data)
+            b := Calculator{}.Compute(data)
+            if got := texts(b.Warnings); strings.Join(got, "\n") != strings.Join(tt.warnings, "\n") {
+                t.Errorf("warnings %q, want %q", got, tt.warnings)
+            }
+            if b.Reconciled() != (len(tt.warnings) == 0) {
+                t.Errorf("Reconciled() = %v with warnings %q", b.Reconciled(), texts(b.Warnings))
+            }
+            if b.Difference != tt.difference {
+                t.Errorf("difference %v, want %v", b.Difference, tt.difference)
+            }
+        })
+    }
+}
+
+func TestReconcileWithTaxInputs(t *testing.T) {
+    // With tax inputs the quoted total is the pre-tax price and installments
+    // cover it plus TCS; flat percentages are ignored
+    data := trip()
+    data.Pricing = &types.PricingRules{GSTPercent: 18}
+    data.PaymentPlan.Tax = &types.TaxInputs{Overseas: true, PAN: "ABCDE1234F", GSTScheme: "none"}
+    data.PaymentPlan.Installments = []types.PaymentInstallment{{Amount: inr(7665)}}
+    b := Calculator{}.Compute(data)
+
+    if b.PreTax != inr(7300) || b.Total != inr(7665) || len(b.Adjustments) != 1 || b.Adjustments[0].Amount != inr(365) {
+        t.Errorf("pre-tax %v, total %v, adjustments %v", b.PreTax, b.Total, b.Adjustments)
+    }
+    if !b.Reconciled() || !b.Difference.IsZero() {
+        t.Errorf("not reconciled: %v", texts(b.Warnings))
+    }
+
+    data.PaymentPlan.Installments[0].Amount = inr(7300)
+    b = Calculator{}.Compute(data)
+    if got := texts(b.Warnings); len(got) != 1 || got[0] != "Installments add up to ₹7,300 but the amount payable is ₹7,665" {
+        t.Errorf("warnings %q", got)
+    }
+}
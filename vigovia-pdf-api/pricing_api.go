@@ -0,0 +1,96 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/pricing"
+    "vigovia-pdf-api/schedule"
+    "vigovia-pdf-api/tax"
+    "vigovia-pdf-api/types"
+)
+
+// costBreakdownHandler returns the cost breakdown the PDF would show, so
+// agents can check their numbers before generating a document
+func costBreakdownHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    itineraryData, ok := decodeItinerary(w, r)
+    if !ok {
+        return
+    }
+
+    breakdown := pricing.Calculator{Rates: cfg.Currency.Rates}.Compute(itineraryData)
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(breakdown)
+}
+
+type taxRequest struct {
+    Amount money.Money     `json:"amount" schema:"required"`
+    Tax    types.TaxInputs `json:"tax" schema:"required"`
+}
+
+// taxHandler computes GST and TCS line items for a package amount
+func taxHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    var req taxRequest
+    if !decodeBody(w, r, &req) {
+        return
+    }
+
+    result, err := tax.Compute(req.Amount, req.Tax, tax.DefaultRules())
+    if err != nil {
+        writeError(w, r, http.StatusUnprocessableEntity, "Tax calculation failed", err.Error())
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(result)
+}
+
+// scheduleHandler generates installments for a package from a payment policy
+func scheduleHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    var req schedule.Request
+    if !decodeBody(w, r, &req) {
+        return
+    }
+
+    locale, ok := requestLocale(w, r, req.Locale)
+    if !ok {
+        return
+    }
+
+    result, err := schedule.Generate(req, locale)
+    if err != nil {
+        writeError(w, r, http.StatusUnprocessableEntity, "Schedule generation failed", err.Error())
+        return
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(result)
+}
+
+// policiesHandler lists the built-in payment policies
+func policiesHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    policies := make([]schedule.Policy, 0, len(schedule.Policies))
+    for _, name := range schedule.Names() {
+        policies = append(policies, schedule.Policies[name])
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(map[string][]schedule.Policy{"policies": policies})
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "regexp"
+    "strings"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/iata"
+    "vigovia-pdf-api/visa"
+)
+
+// suggestionLimit is how many near matches a reference lookup returns
+const suggestionLimit = 5
+
+type airportLookup struct {
+    Match       *iata.Airport  `json:"match" doc:"The airport the query names, when it names exactly one"`
+    Suggestions []iata.Airport `json:"suggestions" doc:"Airports the query starts or may misspell, closest first"`
+}
+
+type airlineLookup struct {
+    Match       *iata.Airline  `json:"match" doc:"The airline the query names, when it names exactly one"`
+    Suggestions []iata.Airline `json:"suggestions" doc:"Airlines the query starts or may misspell, closest first"`
+}
+
+// lookupQuery reads the q parameter, writing an error when it is missing
+func lookupQuery(w http.ResponseWriter, r *http.Request) (string, bool) {
+    q := strings.TrimSpace(r.URL.Query().Get("q"))
+    if q == "" {
+        writeError(w, r, http.StatusBadRequest, "Missing query", "Pass the code or name to look up as q")
+        return "", false
+    }
+    return q, true
+}
+
+// airportsHandler resolves a code, city or airport name against the
+// reference data and suggests corrections for typos
+func airportsHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    q, ok := lookupQuery(w, r)
+    if !ok {
+        return
+    }
+    body := airportLookup{Suggestions: iata.SuggestAirports(q, suggestionLimit)}
+    if a, ok := iata.FindAirport(q); ok {
+        body.Match = &a
+    }
+    if body.Suggestions == nil {
+        body.Suggestions = []iata.Airport{}
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(body)
+}
+
+// airlinesHandler resolves an airline code or name against the reference
+// data and suggests corrections for typos
+func airlinesHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    q, ok := lookupQuery(w, r)
+    if !ok {
+        return
+    }
+    body := airlineLookup{Suggestions: iata.SuggestAirlines(q, suggestionLimit)}
+    if a, ok := iata.FindAirline(q); ok {
+        body.Match = &a
+    }
+    if body.Suggestions == nil {
+        body.Suggestions = []iata.Airline{}
+    }
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(body)
+}
+
+type visaDocument struct {
+    Code string `json:"code"`
+    Name string `json:"name" doc:"The document's name in the language Accept-Language asks for"`
+}
+
+type visaChecklist struct {
+    Nationality    string         `json:"nationality" doc:"ISO 3166-1 alpha-2 code"`
+    Destination    string         `json:"destination" doc:"ISO 3166-1 alpha-2 code"`
+    Requirement    string         `json:"requirement" schema:"enum=required|eVisa|onArrival|free"`
+    Documents      []visaDocument `json:"documents" doc:"What every traveller gathers, in the order it is usually gathered"`
+    MinorDocuments []visaDocument `json:"minorDocuments" doc:"What children and infants gather as well"`
+}
+
+var countryCode = regexp.MustCompile(`^[A-Za-z]{2}$`)
+
+// visaChecklistHandler looks up the documents holders of a passport need to
+// visit a country in the offline rules table
+func visaChecklistHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    destination := strings.TrimSpace(r.URL.Query().Get("destination"))
+    nationality := strings.TrimSpace(r.URL.Query().Get("nationality"))
+    if nationality == "" {
+        nationality = visa.DefaultNationality
+    }
+    if !countryCode.MatchString(destination) || !countryCode.MatchString(nationality) {
+        writeError(w, r, http.StatusBadRequest, "Invalid query", "Pass destination, and optionally nationality, as two letter country codes")
+        return
+    }
+    rule, ok := visa.Lookup(nationality, destination)
+    if !ok {
+        writeError(w, r, http.StatusUnprocessableEntity, "No visa needed", "Travellers need no visa for their own country")
+        return
+    }
+
+    def, _ := i18n.Lookup(cfg.Locale.Default)
+    loc := i18n.Negotiate(r.Header.Get("Accept-Language"), def)
+    documents := func(codes []string) []visaDocument {
+        out := make([]visaDocument, len(codes))
+        for i, code := range codes {
+            out[i] = visaDocument{Code: code, Name: loc.T("visaDoc." + code)}
+        }
+        return out
+    }
+    w.Header().Set("Content-Type", "application/json")
+    w.Header().Set("Content-Language", loc.Tag)
+    json.NewEncoder(w).Encode(visaChecklist{
+        Nationality:    rule.Nationality,
+        Destination:    rule.Destination,
+        Requirement:    rule.Requirement,
+        Documents:      documents(rule.Documents),
+        MinorDocuments: documents(rule.MinorDocuments),
+    })
+}
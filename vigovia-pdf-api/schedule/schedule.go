@@ -0,0 +1,286 @@
+// schedule/schedule.go
+package schedule
+
+import (
+    "errors"
+    "fmt"
+    "math"
+    "sort"
+    "strings"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+const (
+    AnchorBooking   = "booking"
+    AnchorDeparture = "departure"
+
+    RoundingMinor   = "minor"
+    RoundingUnit    = "unit"
+    RoundingHundred = "hundred"
+)
+
+var ErrDueAfterDeparture = errors.New("schedule: installment due after departure")
+
+// Rule is one installment of a policy. Due dates are counted from the
+// booking date or back from the departure date. The balance rule takes
+// whatever the percentages of the other rules leave over.
+type Rule struct {
+    Name    string  `json:"name" schema:"required"`
+    Percent float64 `json:"percent,omitempty" schema:"min=0"`
+    Balance bool    `json:"balance,omitempty"`
+    Anchor  string  `json:"anchor" schema:"required,enum=booking|departure"`
+    Days    int     `json:"days,omitempty" schema:"min=0"`
+}
+
+// Policy is a named set of installment rules
+type Policy struct {
+    Name        string `json:"name" schema:"required"`
+    Description string `json:"description,omitempty"`
+    Rules       []Rule `json:"rules" schema:"required"`
+}
+
+// Policies are the built-in payment policies agents can pick by name
+var Policies = map[string]Policy{
+    "standard": {
+        Name:        "standard",
+        Description: "30% at booking, 50% 45 days before departure, balance 15 days before departure",
+        Rules: []Rule{
+            {Name: "Booking Deposit", Percent: 30, Anchor: AnchorBooking},
+            {Name: "Second Installment", Percent: 50, Anchor: AnchorDeparture, Days: 45},
+            {Name: "Final Payment", Balance: true, Anchor: AnchorDeparture, Days: 15},
+        },
+    },
+    "halfAndHalf": {
+        Name:        "halfAndHalf",
+        Description: "50% at booking, balance 30 days before departure",
+        Rules: []Rule{
+            {Name: "Booking Deposit", Percent: 50, Anchor: AnchorBooking},
+            {Name: "Final Payment", Balance: true, Anchor: AnchorDeparture, Days: 30},
+        },
+    },
+    "peakSeason": {
+        Name:        "peakSeason",
+        Description: "40% at booking, 40% 60 days before departure, balance 30 days before departure",
+        Rules: []Rule{
+            {Name: "Booking Deposit", Percent: 40, Anchor: AnchorBooking},
+            {Name: "Second Installment", Percent: 40, Anchor: AnchorDeparture, Days: 60},
+            {Name: "Final Payment", Balance: true, Anchor: AnchorDeparture, Days: 30},
+        },
+    },
+    "full": {
+        Name:        "full",
+        Description: "Full payment at booking",
+        Rules: []Rule{
+            {Name: "Full Payment", Balance: true, Anchor: AnchorBooking},
+        },
+    },
+}
+
+// ruleNames are the catalogue keys of the built-in policies' installment names
+var ruleNames = map[string]string{
+    "Booking Deposit":    "schedule.bookingDeposit",
+    "Second Installment": "schedule.secondInstallment",
+    "Final Payment":      "schedule.finalPayment",
+    "Full Payment":       "schedule.fullPayment",
+}
+
+// Names returns the built-in policy names in sorted order
+func Names() []string {
+    names := make([]string, 0, len(Policies))
+    for name := range Policies {
+        names = append(names, name)
+    }
+    sort.Strings(names)
+    return names
+}
+
+// Request describes the schedule to generate. Policy names a built-in
+// policy; Rules replaces it with a custom one.
+type Request struct {
+    Total         money.Money   `json:"total" schema:"required"`
+    BookingDate   datetime.Date `json:"bookingDate" schema:"required"`
+    DepartureDate datetime.Date `json:"departureDate" schema:"required"`
+    Policy        string        `json:"policy,omitempty"`
+    Rules         []Rule        `json:"rules,omitempty"`
+    Rounding      string        `json:"rounding,omitempty" schema:"enum=minor|unit|hundred"`
+    Locale        string        `json:"locale,omitempty" schema:"pattern=^[A-Za-z]+([-_][A-Za-z0-9]+)*$" doc:"Language of the installment names, descriptions and notes, as the itinerary's locale; defaults to the Accept-Language header"`
+}
+
+// Schedule is the generated list of installments
+type Schedule struct {
+    Policy       string                     `json:"policy"`
+    Installments []types.PaymentInstallment `json:"installments"`
+    Notes        []string                   `json:"notes,omitempty"`
+}
+
+// Generate splits the total into installments according to the policy.
+// Installments other than the balance are rounded down to the rounding step
+// and the balance absorbs the remainder, so they always add up to the total.
+// Due dates before the booking date are moved to the booking date; a due date
+// after departure is an error. Names, descriptions and notes are written in
+// loc's language, ready to copy into an itinerary of that locale.
+func Generate(req Request, loc *i18n.Locale) (*Schedule, error) {
+    booking, departure := req.BookingDate, req.DepartureDate
+    if booking.IsZero() || departure.IsZero() {
+        return nil, errors.New("schedule: bookingDate and departureDate are required")
+    }
+    if departure.Before(booking) {
+        return nil, fmt.Errorf("schedule: departure %s is before booking %s", req.DepartureDate, req.BookingDate)
+    }
+    if req.Total.MinorUnits <= 0 {
+        return nil, errors.New("schedule: total must be positive")
+    }
+
+    policy, err := resolvePolicy(req)
+    if err != nil {
+        return nil, err
+    }
+    if err := validateRules(policy.Rules); err != nil {
+        return nil, err
+    }
+
+    step, err := roundingStep(req.Rounding, req.Total.Currency)
+    if err != nil {
+        return nil, err
+    }
+
+    out := &Schedule{Policy: policy.Name}
+    remaining := req.Total
+    balanceAt := -1
+    for i, rule := range policy.Rules {
+        name := rule.Name
+        if key, ok := ruleNames[name]; ok && len(req.Rules) == 0 {
+            name = loc.T(key)
+        }
+        due := dueDate(rule, booking, departure)
+        if due.After(departure) {
+            return nil, fmt.Errorf("%w: %s would be due on %s, after departure on %s",
+                ErrDueAfterDeparture, rule.Name, due, req.DepartureDate)
+        }
+        if due.Before(booking) {
+            out.Notes = append(out.Notes, loc.T("schedule.note.dueAtBooking", name))
+            due = booking
+        }
+
+        amount := money.New(0, req.Total.Currency)
+        if rule.Balance {
+            balanceAt = i
+        } else {
+            amount = req.Total.MulRat(int64(math.Round(rule.Percent*100)), 10000)
+            amount = money.New(amount.MinorUnits/step*step, amount.Currency)
+            remaining, _ = remaining.Sub(amount)
+        }
+
+        out.Installments = append(out.Installments, types.PaymentInstallment{
+            ID:          fmt.Sprintf("inst-%d", i+1),
+            Name:        name,
+            Amount:      amount,
+            DueDate:     due,
+            Description: describe(rule, loc),
+        })
+    }
+
+    if balanceAt >= 0 {
+        out.Installments[balanceAt].Amount = remaining
+    } else if remaining.MinorUnits != 0 {
+        // Percentages adding up to 100 can still leave rounding crumbs
+        last := &out.Installments[len(out.Installments)-1]
+        last.Amount, _ = last.Amount.Add(remaining)
+    }
+
+    // Installments are listed in the order they fall due
+    sort.SliceStable(out.Installments, func(i, j int) bool {
+        return out.Installments[i].DueDate.Before(out.Installments[j].DueDate)
+    })
+    return out, nil
+}
+
+func resolvePolicy(req Request) (Policy, error) {
+    if len(req.Rules) > 0 {
+        if req.Policy != "" {
+            return Policy{}, errors.New("schedule: give either a policy name or custom rules, not both")
+        }
+        return Policy{Name: "custom", Rules: req.Rules}, nil
+    }
+
+    name := req.Policy
+    if name == "" {
+        name = "standard"
+    }
+    policy, ok := Policies[name]
+    if !ok {
+        return Policy{}, fmt.Errorf("schedule: unknown policy %q, expected one of %s", name, strings.Join(Names(), ", "))
+    }
+    return policy, nil
+}
+
+func validateRules(rules []Rule) error {
+    var errs []error
+    var percent float64
+    balances := 0
+    for _, rule := range rules {
+        if rule.Balance {
+            balances++
+            if rule.Percent != 0 {
+                errs = append(errs, fmt.Errorf("schedule: balance rule %q can't also have a percent", rule.Name))
+            }
+        }
+        percent += rule.Percent
+    }
+
+    switch {
+    case len(rules) == 0:
+        errs = append(errs, errors.New("schedule: a policy needs at least one rule"))
+    case balances > 1:
+        errs = append(errs, errors.New("schedule: only one rule can take the balance"))
+    case balances == 1 && percent >= 100:
+        errs = append(errs, fmt.Errorf("schedule: percentages add up to %g%%, leaving no balance", percent))
+    case balances == 0 && math.Abs(percent-100) > 1e-9:
+        errs = append(errs, fmt.Errorf("schedule: percentages add up to %g%%, expected 100%% without a balance rule", percent))
+    }
+    return errors.Join(errs...)
+}
+
+// roundingStep returns the rounding step in minor units. Unit rounds to whole
+// rupees (dollars, euros); hundred to multiples of a hundred of them.
+func roundingStep(rounding, currency string) (int64, error) {
+    unit := money.FromMajor(1, currency).MinorUnits
+    switch rounding {
+    case "", RoundingUnit:
+        return unit, nil
+    case RoundingMinor:
+        return 1, nil
+    case RoundingHundred:
+        return unit * 100, nil
+    default:
+        return 0, fmt.Errorf("schedule: unknown rounding %q", rounding)
+    }
+}
+
+func dueDate(rule Rule, booking, departure datetime.Date) datetime.Date {
+    if rule.Anchor == AnchorDeparture {
+        return departure.AddDays(-rule.Days)
+    }
+    return booking.AddDays(rule.Days)
+}
+
+func describe(rule Rule, loc *i18n.Locale) string {
+    share := loc.T("schedule.share", rule.Percent)
+    if rule.Balance {
+        share = loc.T("schedule.balance")
+    }
+
+    switch {
+    case rule.Anchor == AnchorDeparture && rule.Days == 0:
+        return loc.T("schedule.dueOnDeparture", share)
+    case rule.Anchor == AnchorDeparture:
+        return loc.T("schedule.dueBeforeDeparture", share, loc.N("schedule.days", rule.Days))
+    case rule.Days == 0:
+        return loc.T("schedule.dueAtBooking", share)
+    default:
+        return loc.T("schedule.dueAfterBooking", share, loc.N("schedule.days", rule.Days))
+    }
+}
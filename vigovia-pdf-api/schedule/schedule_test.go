@@ -0,0 +1,121 @@
+package schedule
+
+import (
+    "errors"
+    "testing"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/money"
+)
+
+var (
+    booking   = datetime.NewDate(2026, 10, 19)
+    departure = datetime.NewDate(2027, 2, 1)
+)
+
+func TestGenerateRounding(t *testing.T) {
+    tests := []struct {
+        name     string
+        total    money.Money
+        policy   string
+        rules    []Rule
+        rounding string
+        // Amounts in minor units, in the order they fall due
+        want []int64
+    }{
+        // 30% of 1,000.01 is 300.003 and 50% is 500.005
+        {"unit", money.New(1_000_01, "INR"), "standard", nil, RoundingUnit, []int64{300_00, 500_00, 200_01}},
+        {"default is unit", money.New(1_000_01, "INR"), "", nil, "", []int64{300_00, 500_00, 200_01}},
+        {"minor", money.New(1_000_01, "INR"), "standard", nil, RoundingMinor, []int64{300_00, 500_01, 200_00}},
+        {"hundred", money.New(12_345_67, "INR"), "standard", nil, RoundingHundred, []int64{3_700_00, 6_100_00, 2_545_67}},
+        {"half and half of an odd paisa", money.New(1_001, "INR"), "halfAndHalf", nil, RoundingMinor, []int64{501, 500}},
+        {"full", money.New(99_999_99, "INR"), "full", nil, RoundingHundred, []int64{99_999_99}},
+        {"currency without minor units", money.New(123_457, "JPY"), "standard", nil, RoundingHundred, []int64{37_000, 61_700, 24_757}},
+        // Without a balance rule the last installment takes the crumbs
+        {"thirds", money.New(1_000_00, "INR"), "", []Rule{
+            {Name: "First", Percent: 33.33, Anchor: AnchorBooking},
+            {Name: "Second", Percent: 33.33, Anchor: AnchorBooking, Days: 10},
+            {Name: "Third", Percent: 33.34, Anchor: AnchorBooking, Days: 20},
+        }, RoundingUnit, []int64{333_00, 333_00, 334_00}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            s, err := Generate(Request{
+                Total:         tt.total,
+                BookingDate:   booking,
+                DepartureDate: departure,
+                Policy:        tt.policy,
+                Rules:         tt.rules,
+                Rounding:      tt.rounding,
+            }, i18n.Default())
+            if err != nil {
+                t.Fatal(err)
+            }
+            if len(s.Installments) != len(tt.want) {
+                t.Fatalf("%d installments, want %d", len(s.Installments), len(tt.want))
+            }
+            var sum int64
+            for i, inst := range s.Installments {
+                if inst.Amount.MinorUnits != tt.want[i] || inst.Amount.Currency != tt.total.Currency {
+                    t.Errorf("installment %d is %d %s, want %d", i, inst.Amount.MinorUnits, inst.Amount.Currency, tt.want[i])
+                }
+                sum += inst.Amount.MinorUnits
+            }
+            if sum != tt.total.MinorUnits {
+                t.Errorf("installments add up to %d, want %d", sum, tt.total.MinorUnits)
+            }
+        })
+    }
+}
+
+func TestGenerateDueDates(t *testing.T) {
+    // Departure 20 days after booking: the 45 day installment moves to the
+    // booking date, and sorts before the balance due 15 days before departure
+    s, err := Generate(Request{
+        Total:         money.New(1_000_00, "INR"),
+        BookingDate:   booking,
+        DepartureDate: booking.AddDays(20),
+    }, i18n.Default())
+    if err != nil {
+        t.Fatal(err)
+    }
+    want := []string{"2026-10-19", "2026-10-19", "2026-10-24"}
+    for i, inst := range s.Installments {
+        if inst.DueDate.String() != want[i] {
+            t.Errorf("installment %d due %s, want %s", i, inst.DueDate, want[i])
+        }
+    }
+    if len(s.Notes) != 1 {
+        t.Errorf("notes %q, want one about the moved installment", s.Notes)
+    }
+}
+
+func TestGenerateErrors(t *testing.T) {
+    total := money.New(1_000_00, "INR")
+    tests := []struct {
+        name string
+        req  Request
+        is   error
+    }{
+        {"departure before booking", Request{Total: total, BookingDate: departure, DepartureDate: booking}, nil},
+        {"no total", Request{Total: money.New(0, "INR"), BookingDate: booking, DepartureDate: departure}, nil},
+        {"unknown policy", Request{Total: total, BookingDate: booking, DepartureDate: departure, Policy: "later"}, nil},
+        {"unknown rounding", Request{Total: total, BookingDate: booking, DepartureDate: departure, Rounding: "ten"}, nil},
+        {"percentages short of 100", Request{Total: total, BookingDate: booking, DepartureDate: departure, Rules: []Rule{
+            {Name: "Deposit", Percent: 40, Anchor: AnchorBooking},
+        }}, nil},
+        {"nothing left for the balance", Request{Total: total, BookingDate: booking, DepartureDate: departure, Rules: []Rule{
+            {Name: "Deposit", Percent: 100, Anchor: AnchorBooking},
+            {Name: "Rest", Balance: true, Anchor: AnchorDeparture},
+        }}, nil},
+        {"due after departure", Request{Total: total, BookingDate: booking, DepartureDate: booking.AddDays(5), Rules: []Rule{
+            {Name: "Later", Balance: true, Anchor: AnchorBooking, Days: 10},
+        }}, ErrDueAfterDeparture},
+    }
+    for _, tt := range tests {
+        _, err := Generate(tt.req, i18n.Default())
+        if err == nil || tt.is != nil && !errors.Is(err, tt.is) {
+            t.Errorf("%s: error %v", tt.name, err)
+        }
+    }
+}
@@ -0,0 +1,69 @@
+// schema/openapi.go
+package schema
+
+// Document is a minimal OpenAPI 3.0 document
+type Document struct {
+    OpenAPI    string               `json:"openapi"`
+    Info       Info                 `json:"info"`
+    Paths      map[string]PathItem  `json:"paths"`
+    Components Components           `json:"components"`
+    Security   []map[string][]string `json:"security,omitempty"`
+}
+
+type Info struct {
+    Title       string `json:"title"`
+    Version     string `json:"version"`
+    Description string `json:"description,omitempty"`
+}
+
+type PathItem map[string]*Operation
+
+type Operation struct {
+    Summary     string                `json:"summary"`
+    OperationID string                `json:"operationId"`
+    Tags        []string              `json:"tags,omitempty"`
+    Parameters  []Parameter           `json:"parameters,omitempty"`
+    RequestBody *RequestBody          `json:"requestBody,omitempty"`
+    Responses   map[string]Response   `json:"responses"`
+    Security    []map[string][]string `json:"security,omitempty"`
+    Deprecated  bool                  `json:"deprecated,omitempty"`
+}
+
+// Parameter is a path or query parameter of an operation
+type Parameter struct {
+    Name        string  `json:"name"`
+    In          string  `json:"in"`
+    Description string  `json:"description,omitempty"`
+    Required    bool    `json:"required,omitempty"`
+    Schema      *Schema `json:"schema"`
+}
+
+type RequestBody struct {
+    Required bool                 `json:"required"`
+    Content  map[string]MediaType `json:"content"`
+}
+
+type Response struct {
+    Description string               `json:"description"`
+    Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+type MediaType struct {
+    Schema *Schema `json:"schema"`
+}
+
+type Components struct {
+    Schemas         map[string]*Schema        `json:"schemas"`
+    SecuritySchemes map[string]SecurityScheme `json:"securitySchemes,omitempty"`
+}
+
+type SecurityScheme struct {
+    Type string `json:"type"`
+    In   string `json:"in,omitempty"`
+    Name string `json:"name,omitempty"`
+}
+
+// JSON wraps a schema as an application/json media type map
+func JSON(s *Schema) map[string]MediaType {
+    return map[string]MediaType{"application/json": {Schema: s}}
+}
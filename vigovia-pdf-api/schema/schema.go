@@ -0,0 +1,148 @@
+// schema/schema.go
+package schema
+
+import (
+    "reflect"
+    "strings"
+    "time"
+)
+
+// Schema is the subset of the OpenAPI 3.0 schema object the API uses
+type Schema struct {
+    Ref                  string             `json:"$ref,omitempty"`
+    Type                 string             `json:"type,omitempty"`
+    Format               string             `json:"format,omitempty"`
+    Description          string             `json:"description,omitempty"`
+    Nullable             bool               `json:"nullable,omitempty"`
+    Enum                 []interface{}      `json:"enum,omitempty"`
+    Properties           map[string]*Schema `json:"properties,omitempty"`
+    Required             []string           `json:"required,omitempty"`
+    Items                *Schema            `json:"items,omitempty"`
+    AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+    OneOf                []*Schema          `json:"oneOf,omitempty"`
+    Minimum              *float64           `json:"minimum,omitempty"`
+    Pattern              string             `json:"pattern,omitempty"`
+}
+
+// Provider lets a type describe its own JSON shape, e.g. types with a custom
+// UnmarshalJSON that accept more than one encoding.
+type Provider interface {
+    JSONSchema() *Schema
+}
+
+var providerType = reflect.TypeOf((*Provider)(nil)).Elem()
+var timeType = reflect.TypeOf(time.Time{})
+
+// Registry generates schemas from Go types and collects named struct schemas
+// as reusable components.
+type Registry struct {
+    Components map[string]*Schema
+}
+
+func NewRegistry() *Registry {
+    return &Registry{Components: make(map[string]*Schema)}
+}
+
+// Ref returns a $ref schema for a Go value's type, registering it and every
+// struct it reaches as components
+func (reg *Registry) Ref(v interface{}) *Schema {
+    return reg.schemaFor(reflect.TypeOf(v))
+}
+
+func (reg *Registry) schemaFor(t reflect.Type) *Schema {
+    if t.Kind() != reflect.Pointer && t.Implements(providerType) {
+        return reflect.Zero(t).Interface().(Provider).JSONSchema()
+    }
+    if t.Kind() != reflect.Pointer && reflect.PointerTo(t).Implements(providerType) {
+        return reflect.New(t).Interface().(Provider).JSONSchema()
+    }
+
+    if t == timeType {
+        return &Schema{Type: "string", Format: "date-time"}
+    }
+
+    switch t.Kind() {
+    case reflect.Pointer:
+        s := reg.schemaFor(t.Elem())
+        if s.Ref != "" {
+            return &Schema{OneOf: []*Schema{s}, Nullable: true}
+        }
+        s.Nullable = true
+        return s
+    case reflect.String:
+        return &Schema{Type: "string"}
+    case reflect.Bool:
+        return &Schema{Type: "boolean"}
+    case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+        reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+        format := "int64"
+        if t.Bits() <= 32 {
+            format = "int32"
+        }
+        return &Schema{Type: "integer", Format: format}
+    case reflect.Float32, reflect.Float64:
+        return &Schema{Type: "number"}
+    case reflect.Slice, reflect.Array:
+        return &Schema{Type: "array", Items: reg.schemaFor(t.Elem()), Nullable: t.Kind() == reflect.Slice}
+    case reflect.Map:
+        return &Schema{Type: "object", AdditionalProperties: reg.schemaFor(t.Elem()), Nullable: true}
+    case reflect.Struct:
+        name := t.Name()
+        if name == "" {
+            return reg.structSchema(t)
+        }
+        if _, ok := reg.Components[name]; !ok {
+            // Register before recursing so self-referencing types terminate
+            reg.Components[name] = &Schema{}
+            *reg.Components[name] = *reg.structSchema(t)
+        }
+        return &Schema{Ref: "#/components/schemas/" + name}
+    default:
+        return &Schema{}
+    }
+}
+
+// structSchema reads json tags for property names and the schema tag for
+// extra constraints: `schema:"required,enum=a|b,min=0,format=date,pattern=^x$"`
+func (reg *Registry) structSchema(t reflect.Type) *Schema {
+    s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+    for i := 0; i < t.NumField(); i++ {
+        field := t.Field(i)
+        if !field.IsExported() {
+            continue
+        }
+        name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+        if name == "-" {
+            continue
+        }
+        if name == "" {
+            name = field.Name
+        }
+
+        prop := reg.schemaFor(field.Type)
+        for _, opt := range strings.Split(field.Tag.Get("schema"), ",") {
+            key, value, _ := strings.Cut(opt, "=")
+            switch key {
+            case "required":
+                s.Required = append(s.Required, name)
+            case "enum":
+                for _, e := range strings.Split(value, "|") {
+                    prop.Enum = append(prop.Enum, e)
+                }
+            case "min":
+                if min, err := parseFloat(value); err == nil {
+                    prop.Minimum = &min
+                }
+            case "format":
+                prop.Format = value
+            case "pattern":
+                prop.Pattern = value
+            }
+        }
+        if desc := field.Tag.Get("doc"); desc != "" {
+            prop.Description = desc
+        }
+        s.Properties[name] = prop
+    }
+    return s
+}
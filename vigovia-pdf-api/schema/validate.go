@@ -0,0 +1,242 @@
+// schema/validate.go
+package schema
+
+import (
+    "bytes"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "io"
+    "reflect"
+    "regexp"
+    "sort"
+    "strconv"
+    "strings"
+)
+
+// FieldError describes one problem at a JSON path such as $.flights[0].date
+type FieldError struct {
+    Path    string `json:"path"`
+    Message string `json:"message"`
+}
+
+// ValidationError collects every FieldError found in a document
+type ValidationError struct {
+    Errors []FieldError
+}
+
+func (e *ValidationError) Error() string {
+    msgs := make([]string, 0, len(e.Errors))
+    for _, fe := range e.Errors {
+        msgs = append(msgs, fe.Path+": "+fe.Message)
+    }
+    return strings.Join(msgs, "; ")
+}
+
+// formats check string values with a format such as "date"; packages that
+// own a format register it with RegisterFormat
+var formats = map[string]func(string) error{}
+
+// RegisterFormat makes validation check strings with format name using
+// check, whose error message is reported at the value's path. Empty strings
+// aren't checked; "required" covers them.
+func RegisterFormat(name string, check func(string) error) {
+    formats[name] = check
+}
+
+// Transform rewrites the decoded JSON tree before it is validated
+type Transform func(doc interface{}) error
+
+// DecodeStrict reads one JSON document from r into the pointer v, validates it against the
+// schema of v's type (rejecting unknown fields and wrong types) and only then
+// decodes it into v. Syntax errors are returned as is, schema violations as a
+// *ValidationError listing every offending path. Transforms run in order on
+// the raw tree first, e.g. to upgrade older payload versions.
+func (reg *Registry) DecodeStrict(r io.Reader, v interface{}, transforms ...Transform) error {
+    raw, err := io.ReadAll(r)
+    if err != nil {
+        return err
+    }
+
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.UseNumber()
+    var doc interface{}
+    if err := dec.Decode(&doc); err != nil {
+        return err
+    }
+    if dec.More() {
+        return errors.New("request body must contain a single JSON document")
+    }
+
+    for _, transform := range transforms {
+        if err := transform(doc); err != nil {
+            return err
+        }
+    }
+
+    var errs []FieldError
+    reg.validate(reg.schemaFor(reflect.TypeOf(v).Elem()), doc, "$", &errs)
+    if len(errs) > 0 {
+        return &ValidationError{Errors: errs}
+    }
+    if len(transforms) > 0 {
+        if raw, err = json.Marshal(doc); err != nil {
+            return err
+        }
+    }
+    return json.Unmarshal(raw, v)
+}
+
+func (reg *Registry) resolve(s *Schema) *Schema {
+    for s.Ref != "" {
+        s = reg.Components[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+    }
+    return s
+}
+
+func (reg *Registry) validate(s *Schema, value interface{}, path string, errs *[]FieldError) {
+    s = reg.resolve(s)
+    add := func(format string, args ...interface{}) {
+        *errs = append(*errs, FieldError{Path: path, Message: fmt.Sprintf(format, args...)})
+    }
+
+    if value == nil {
+        if !s.Nullable && s.Type != "" {
+            add("must not be null")
+        }
+        return
+    }
+
+    if len(s.OneOf) > 0 {
+        for _, option := range s.OneOf {
+            var optionErrs []FieldError
+            reg.validate(option, value, path, &optionErrs)
+            if len(optionErrs) == 0 {
+                return
+            }
+        }
+        add("does not match any allowed shape, got %s", describe(value))
+        return
+    }
+
+    switch s.Type {
+    case "object":
+        obj, ok := value.(map[string]interface{})
+        if !ok {
+            add("expected object, got %s", describe(value))
+            return
+        }
+        for _, name := range s.Required {
+            if v, present := obj[name]; !present || v == "" {
+                *errs = append(*errs, FieldError{Path: path + "." + name, Message: "is required"})
+            }
+        }
+        keys := make([]string, 0, len(obj))
+        for k := range obj {
+            keys = append(keys, k)
+        }
+        sort.Strings(keys)
+        for _, k := range keys {
+            if prop, ok := s.Properties[k]; ok {
+                reg.validate(prop, obj[k], path+"."+k, errs)
+            } else if s.AdditionalProperties != nil {
+                reg.validate(s.AdditionalProperties, obj[k], path+"."+k, errs)
+            } else {
+                *errs = append(*errs, FieldError{Path: path + "." + k, Message: "unknown field"})
+            }
+        }
+    case "array":
+        arr, ok := value.([]interface{})
+        if !ok {
+            add("expected array, got %s", describe(value))
+            return
+        }
+        for i, item := range arr {
+            reg.validate(s.Items, item, path+"["+strconv.Itoa(i)+"]", errs)
+        }
+    case "string":
+        str, ok := value.(string)
+        if !ok {
+            add("expected string, got %s", describe(value))
+            return
+        }
+        if s.Pattern != "" {
+            if re, err := regexp.Compile(s.Pattern); err == nil && !re.MatchString(str) {
+                add("must match pattern %s", s.Pattern)
+            }
+        }
+        if check, ok := formats[s.Format]; ok && str != "" {
+            if err := check(str); err != nil {
+                add("%s", err.Error())
+            }
+        }
+    case "integer":
+        num, ok := value.(json.Number)
+        if !ok {
+            add("expected integer, got %s", describe(value))
+            return
+        }
+        if _, err := num.Int64(); err != nil {
+            add("expected integer, got %s", num)
+            return
+        }
+    case "number":
+        if _, ok := value.(json.Number); !ok {
+            add("expected number, got %s", describe(value))
+            return
+        }
+    case "boolean":
+        if _, ok := value.(bool); !ok {
+            add("expected boolean, got %s", describe(value))
+            return
+        }
+    }
+
+    if len(s.Enum) > 0 {
+        for _, allowed := range s.Enum {
+            if fmt.Sprint(allowed) == fmt.Sprint(value) {
+                return
+            }
+        }
+        add("must be one of %s", joinEnum(s.Enum))
+    }
+    if s.Minimum != nil {
+        if num, ok := value.(json.Number); ok {
+            if f, err := num.Float64(); err == nil && f < *s.Minimum {
+                add("must be at least %g", *s.Minimum)
+            }
+        }
+    }
+}
+
+// Helper function to name the JSON type of a decoded value for error messages
+func describe(value interface{}) string {
+    switch v := value.(type) {
+    case map[string]interface{}:
+        return "object"
+    case []interface{}:
+        return "array"
+    case string:
+        return "string"
+    case json.Number:
+        return "number " + v.String()
+    case bool:
+        return "boolean"
+    case nil:
+        return "null"
+    default:
+        return fmt.Sprintf("%T", v)
+    }
+}
+
+func joinEnum(values []interface{}) string {
+    parts := make([]string, 0, len(values))
+    for _, v := range values {
+        parts = append(parts, fmt.Sprintf("%q", fmt.Sprint(v)))
+    }
+    return strings.Join(parts, ", ")
+}
+
+func parseFloat(s string) (float64, error) {
+    return strconv.ParseFloat(s, 64)
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+    "context"
+    "encoding/json"
+    "errors"
+    "log/slog"
+    "net"
+    "net/http"
+    "os"
+    "os/signal"
+    "sync"
+    "sync/atomic"
+    "syscall"
+    "time"
+)
+
+// lifecycle tracks readiness and the PDF generation jobs still in flight so a
+// shutdown can wait for renders to finish instead of cutting them off.
+type lifecycle struct {
+    ready    atomic.Bool
+    inFlight atomic.Int64
+
+    mu sync.Mutex
+    // closed is set once the server has shut down; no job starts after it,
+    // so waitForJobs can't miss one
+    closed bool
+    jobs   sync.WaitGroup
+}
+
+var app lifecycle
+
+// trackJob marks a generation job as started, unless the server has shut
+// down; call the returned func when it ends
+func (l *lifecycle) trackJob() (func(), bool) {
+    l.mu.Lock()
+    defer l.mu.Unlock()
+    if l.closed {
+        return nil, false
+    }
+    l.jobs.Add(1)
+    l.inFlight.Add(1)
+    return func() {
+        l.inFlight.Add(-1)
+        l.jobs.Done()
+    }, true
+}
+
+// close stops jobs from starting
+func (l *lifecycle) close() {
+    l.mu.Lock()
+    l.closed = true
+    l.mu.Unlock()
+}
+
+// startJob tracks a generation job, or refuses it with 503 once the server
+// has shut down. Jobs are still taken while only readiness is failing, so
+// requests the load balancer sends during the shutdown delay succeed. Call
+// the returned func when the job ends.
+func startJob(w http.ResponseWriter, r *http.Request) (func(), bool) {
+    done, ok := app.trackJob()
+    if !ok {
+        w.Header().Set("Connection", "close")
+        writeError(w, r, http.StatusServiceUnavailable, "Service unavailable", "Server is shutting down")
+        return nil, false
+    }
+    return done, true
+}
+
+// waitForJobs blocks until every tracked job finished or the context expires
+func (l *lifecycle) waitForJobs(ctx context.Context) error {
+    done := make(chan struct{})
+    go func() {
+        l.jobs.Wait()
+        close(done)
+    }()
+    select {
+    case <-done:
+        return nil
+    case <-ctx.Done():
+        return ctx.Err()
+    }
+}
+
+// runServer serves until SIGINT/SIGTERM, then flips readiness and keeps
+// serving while the load balancer notices, before draining in-flight requests
+// and generation jobs.
+func runServer(server *http.Server) error {
+    ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+    defer stop()
+
+    // Bind before reporting ready so probes never see a ready server that can't accept
+    listener, err := net.Listen("tcp", server.Addr)
+    if err != nil {
+        return err
+    }
+
+    serveErr := make(chan error, 1)
+    go func() {
+        if cfg.TLSEnabled() {
+            serveErr <- server.ServeTLS(listener, cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+        } else {
+            serveErr <- server.Serve(listener)
+        }
+    }()
+
+    app.ready.Store(true)
+
+    select {
+    case err := <-serveErr:
+        app.ready.Store(false)
+        if errors.Is(err, http.ErrServerClosed) {
+            return nil
+        }
+        return err
+    case <-ctx.Done():
+    }
+    stop()
+
+    slog.Info("Shutdown signal received, no longer ready", "in_flight_jobs", app.inFlight.Load())
+    app.ready.Store(false)
+    time.Sleep(cfg.Server.ShutdownDelay.Duration)
+
+    shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.Server.ShutdownTimeout.Duration)
+    defer cancel()
+
+    server.SetKeepAlivesEnabled(false)
+    if err := server.Shutdown(shutdownCtx); err != nil {
+        slog.Error("Graceful shutdown incomplete", "error", err)
+        server.Close()
+    }
+    app.close()
+    if err := app.waitForJobs(shutdownCtx); err != nil {
+        slog.Error("Gave up waiting for PDF jobs", "in_flight_jobs", app.inFlight.Load(), "error", err)
+        return err
+    }
+
+    slog.Info("Server stopped")
+    return nil
+}
+
+func readinessHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    if !app.ready.Load() {
+        w.WriteHeader(http.StatusServiceUnavailable)
+        json.NewEncoder(w).Encode(map[string]interface{}{
+            "status":   "Shutting down",
+            "inFlight": app.inFlight.Load(),
+        })
+        return
+    }
+    json.NewEncoder(w).Encode(map[string]interface{}{
+        "status":   "Ready",
+        "inFlight": app.inFlight.Load(),
+    })
+}
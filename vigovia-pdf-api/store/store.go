@@ -0,0 +1,195 @@
+// store/store.go
+package store
+
+import (
+    "bytes"
+    "crypto/rand"
+    "encoding/hex"
+    "encoding/json"
+    "errors"
+    "fmt"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+    "vigovia-pdf-api/migrate"
+    "vigovia-pdf-api/types"
+)
+
+var ErrNotFound = errors.New("store: itinerary not found")
+
+// Record is a stored itinerary with the payments received against it
+type Record struct {
+    ID        string              `json:"id"`
+    Owner     string              `json:"owner,omitempty"`
+    Itinerary types.ItineraryData `json:"itinerary"`
+    Payments  []types.Payment     `json:"payments"`
+    CreatedAt time.Time           `json:"createdAt"`
+    UpdatedAt time.Time           `json:"updatedAt"`
+}
+
+// Store keeps itineraries in memory and, with a path, in a JSON file that is
+// rewritten on every change. It is meant for the volumes a single agency
+// produces, not as a general purpose database.
+type Store struct {
+    mu      sync.RWMutex
+    path    string
+    records map[string]*Record
+}
+
+// Open loads the records stored at path; a missing file starts an empty
+// store and an empty path keeps records in memory only
+func Open(path string) (*Store, error) {
+    s := &Store{path: path, records: make(map[string]*Record)}
+    if path == "" {
+        return s, nil
+    }
+
+    raw, err := os.ReadFile(path)
+    if errors.Is(err, os.ErrNotExist) {
+        return s, nil
+    }
+    if err != nil {
+        return nil, fmt.Errorf("reading itinerary store: %w", err)
+    }
+    records, err := decodeRecords(raw)
+    if err != nil {
+        return nil, fmt.Errorf("parsing itinerary store %s: %w", path, err)
+    }
+    for _, rec := range records {
+        s.records[rec.ID] = rec
+    }
+    return s, nil
+}
+
+// decodeRecords reads stored records, upgrading itineraries saved by an older
+// version of the server to the current payload version
+func decodeRecords(raw []byte) ([]*Record, error) {
+    dec := json.NewDecoder(bytes.NewReader(raw))
+    dec.UseNumber()
+    var docs []map[string]interface{}
+    if err := dec.Decode(&docs); err != nil {
+        return nil, err
+    }
+    for _, doc := range docs {
+        if _, err := migrate.Upgrade(doc["itinerary"]); err != nil {
+            return nil, fmt.Errorf("itinerary %v: %w", doc["id"], err)
+        }
+    }
+
+    upgraded, err := json.Marshal(docs)
+    if err != nil {
+        return nil, err
+    }
+    var records []*Record
+    if err := json.Unmarshal(upgraded, &records); err != nil {
+        return nil, err
+    }
+    return records, nil
+}
+
+// Create stores a new itinerary for owner and returns its record
+func (s *Store) Create(owner string, data types.ItineraryData) (Record, error) {
+    id, err := NewID()
+    if err != nil {
+        return Record{}, err
+    }
+    now := time.Now().UTC()
+    rec := &Record{ID: id, Owner: owner, Itinerary: data, Payments: []types.Payment{}, CreatedAt: now, UpdatedAt: now}
+
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    s.records[id] = rec
+    if err := s.save(); err != nil {
+        delete(s.records, id)
+        return Record{}, err
+    }
+    return clone(rec), nil
+}
+
+// Get returns the record with id if owner may see it. An empty owner is an
+// unauthenticated deployment or an admin and sees every record.
+func (s *Store) Get(owner, id string) (Record, error) {
+    s.mu.RLock()
+    defer s.mu.RUnlock()
+    rec, ok := s.records[id]
+    if !ok || !visible(rec, owner) {
+        return Record{}, ErrNotFound
+    }
+    return clone(rec), nil
+}
+
+// Update changes a stored record through fn and saves it; when fn fails the
+// record is left as it was
+func (s *Store) Update(owner, id string, fn func(*Record) error) (Record, error) {
+    s.mu.Lock()
+    defer s.mu.Unlock()
+    rec, ok := s.records[id]
+    if !ok || !visible(rec, owner) {
+        return Record{}, ErrNotFound
+    }
+
+    updated := clone(rec)
+    if err := fn(&updated); err != nil {
+        return Record{}, err
+    }
+    updated.UpdatedAt = time.Now().UTC()
+    s.records[id] = &updated
+    if err := s.save(); err != nil {
+        s.records[id] = rec
+        return Record{}, err
+    }
+    return clone(&updated), nil
+}
+
+func visible(rec *Record, owner string) bool {
+    return owner == "" || rec.Owner == owner
+}
+
+// clone copies a record so callers can't change the stored one
+func clone(rec *Record) Record {
+    c := *rec
+    c.Payments = append([]types.Payment{}, rec.Payments...)
+    return c
+}
+
+// save writes every record to a temporary file and renames it into place
+func (s *Store) save() error {
+    if s.path == "" {
+        return nil
+    }
+    records := make([]*Record, 0, len(s.records))
+    for _, rec := range s.records {
+        records = append(records, rec)
+    }
+    raw, err := json.Marshal(records)
+    if err != nil {
+        return err
+    }
+
+    tmp, err := os.CreateTemp(filepath.Dir(s.path), ".itineraries-*")
+    if err != nil {
+        return fmt.Errorf("saving itinerary store: %w", err)
+    }
+    defer os.Remove(tmp.Name())
+    if _, err := tmp.Write(raw); err != nil {
+        tmp.Close()
+        return fmt.Errorf("saving itinerary store: %w", err)
+    }
+    if err := tmp.Close(); err != nil {
+        return fmt.Errorf("saving itinerary store: %w", err)
+    }
+    if err := os.Rename(tmp.Name(), s.path); err != nil {
+        return fmt.Errorf("saving itinerary store: %w", err)
+    }
+    return nil
+}
+
+// NewID returns a random identifier for records and payments
+func NewID() (string, error) {
+    b := make([]byte, 8)
+    if _, err := rand.Read(b); err != nil {
+        return "", err
+    }
+    return hex.EncodeToString(b), nil
+}
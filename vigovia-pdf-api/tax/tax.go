@@ -0,0 +1,209 @@
+// tax/tax.go
+package tax
+
+import (
+    "fmt"
+    "math"
+    "strings"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+const (
+    KindGST = "GST"
+    KindTCS = "TCS"
+
+    SchemeTourOperator = "tourOperator"
+    SchemeServiceFee   = "serviceFee"
+    SchemeNone         = "none"
+)
+
+// Slab is a TCS rate applying to the part of the year's remittances above From
+type Slab struct {
+    From        money.Money
+    RatePercent float64
+}
+
+// Rules holds the statutory rates; DefaultRules reflects FY 2025-26
+type Rules struct {
+    // TCS under section 206C(1G) on overseas tour program packages
+    TCSSlabs []Slab
+    // Section 206CC: without a PAN, twice the rate or NoPANMinimum, whichever is higher
+    NoPANMinimumPercent float64
+    // GST on tour operator services without input tax credit
+    TourOperatorGSTPercent float64
+    // GST on the agent's service fee or commission
+    ServiceFeeGSTPercent float64
+}
+
+func DefaultRules() Rules {
+    return Rules{
+        TCSSlabs: []Slab{
+            {From: money.FromMajor(0, "INR"), RatePercent: 5},
+            {From: money.FromMajor(10_00_000, "INR"), RatePercent: 20},
+        },
+        NoPANMinimumPercent:    5,
+        TourOperatorGSTPercent: 5,
+        ServiceFeeGSTPercent:   18,
+    }
+}
+
+// TotalIsPreTax is the rule for reading PaymentPlan.TotalAmount, which
+// pricing, the itinerary PDF and invoices all follow. With tax inputs the total
+// is the package price before GST and TCS, which Compute adds on top. Without
+// them it is what the customer pays, GST included, and no tax is worked out
+// from it.
+func TotalIsPreTax(plan types.PaymentPlan) bool {
+    return plan.Tax != nil
+}
+
+// LineItem is one tax charged on a base amount
+type LineItem struct {
+    Kind        string       `json:"kind"`
+    Label       i18n.Message `json:"label"`
+    Base        money.Money  `json:"base"`
+    RatePercent float64      `json:"ratePercent"`
+    Amount      money.Money  `json:"amount"`
+}
+
+// Result lists the taxes on a package and the amount the customer pays
+type Result struct {
+    Package money.Money    `json:"package"`
+    Lines   []LineItem     `json:"lines"`
+    GST     money.Money    `json:"gst"`
+    TCS     money.Money    `json:"tcs"`
+    Payable money.Money    `json:"payable"`
+    Notes   []i18n.Message `json:"notes,omitempty"`
+}
+
+// Compute works out GST on the package (or its service component) and TCS on
+// the GST inclusive amount for overseas packages. Amounts must be in INR.
+func Compute(pkg money.Money, in types.TaxInputs, rules Rules) (*Result, error) {
+    if pkg.Currency != "INR" {
+        return nil, fmt.Errorf("tax: package amount must be in INR, got %s", pkg.Currency)
+    }
+
+    res := &Result{
+        Package: pkg,
+        GST:     money.New(0, "INR"),
+        TCS:     money.New(0, "INR"),
+    }
+
+    if err := computeGST(res, pkg, in, rules); err != nil {
+        return nil, err
+    }
+
+    gross, _ := pkg.Add(res.GST)
+    if in.Overseas {
+        if err := computeTCS(res, gross, in, rules); err != nil {
+            return nil, err
+        }
+    } else {
+        res.Notes = append(res.Notes, i18n.Msg("tax.note.domestic"))
+    }
+
+    res.Payable, _ = gross.Add(res.TCS)
+    return res, nil
+}
+
+func computeGST(res *Result, pkg money.Money, in types.TaxInputs, rules Rules) error {
+    var base money.Money
+    var rate float64
+    switch in.GSTScheme {
+    case "", SchemeTourOperator:
+        base, rate = pkg, rules.TourOperatorGSTPercent
+    case SchemeServiceFee:
+        if in.ServiceComponent == nil {
+            return fmt.Errorf("tax: serviceComponent is required for the %s GST scheme", SchemeServiceFee)
+        }
+        if in.ServiceComponent.Currency != "INR" {
+            return fmt.Errorf("tax: serviceComponent must be in INR, got %s", in.ServiceComponent.Currency)
+        }
+        base, rate = *in.ServiceComponent, rules.ServiceFeeGSTPercent
+    case SchemeNone:
+        return nil
+    default:
+        return fmt.Errorf("tax: unknown GST scheme %q", in.GSTScheme)
+    }
+
+    // Same state supplies split GST evenly into central and state tax
+    supplier, supply := strings.ToUpper(in.SupplierState), strings.ToUpper(in.PlaceOfSupply)
+    if supplier != "" && supplier == supply {
+        half := rate / 2
+        for _, label := range []string{"CGST", "SGST"} {
+            amount := percentOf(base, half)
+            res.Lines = append(res.Lines, LineItem{Kind: KindGST, Label: i18n.Msg("tax.gst", label, half), Base: base, RatePercent: half, Amount: amount})
+            res.GST, _ = res.GST.Add(amount)
+        }
+        return nil
+    }
+
+    label := "IGST"
+    if supplier == "" || supply == "" {
+        label = "GST"
+    }
+    amount := percentOf(base, rate)
+    res.Lines = append(res.Lines, LineItem{Kind: KindGST, Label: i18n.Msg("tax.gst", label, rate), Base: base, RatePercent: rate, Amount: amount})
+    res.GST = amount
+    return nil
+}
+
+// computeTCS applies the slab rates to this payment, taking the PAN's prior
+// remittances this financial year into account when placing it in the slabs
+func computeTCS(res *Result, gross money.Money, in types.TaxInputs, rules Rules) error {
+    prior := money.New(0, "INR")
+    if in.PriorRemittances != nil {
+        if in.PriorRemittances.Currency != "INR" {
+            return fmt.Errorf("tax: priorRemittances must be in INR, got %s", in.PriorRemittances.Currency)
+        }
+        prior = *in.PriorRemittances
+    }
+    if in.PAN == "" {
+        res.Notes = append(res.Notes, i18n.Msg("tax.note.noPAN"))
+    }
+
+    start := prior.MinorUnits
+    end := start + gross.MinorUnits
+    for i, slab := range rules.TCSSlabs {
+        from := slab.From.MinorUnits
+        to := int64(math.MaxInt64)
+        if i+1 < len(rules.TCSSlabs) {
+            to = rules.TCSSlabs[i+1].From.MinorUnits
+        }
+        lo, hi := max(start, from), min(end, to)
+        if hi <= lo {
+            continue
+        }
+
+        rate := slab.RatePercent
+        if in.PAN == "" {
+            rate = math.Max(rate*2, rules.NoPANMinimumPercent)
+        }
+        base := money.New(hi-lo, "INR")
+        amount := percentOf(base, rate)
+
+        label := i18n.Msg("tax.tcs", rate)
+        if len(rules.TCSSlabs) > 1 {
+            if i+1 < len(rules.TCSSlabs) {
+                label = i18n.Msg("tax.tcsUpTo", rate, rules.TCSSlabs[i+1].From)
+            } else {
+                label = i18n.Msg("tax.tcsAbove", rate, slab.From)
+            }
+        }
+        res.Lines = append(res.Lines, LineItem{Kind: KindTCS, Label: label, Base: base, RatePercent: rate, Amount: amount})
+        res.TCS, _ = res.TCS.Add(amount)
+    }
+    return nil
+}
+
+// Helper function to take a percentage of an amount, rounded to the rupee as
+// taxes are collected in whole rupees
+func percentOf(m money.Money, percent float64) money.Money {
+    paise := m.MulRat(int64(math.Round(percent*100)), 10000)
+    return money.New(roundToRupee(paise.MinorUnits), m.Currency)
+}
+
+func roundToRupee(paise int64) int64 {
+    return int64(math.Round(float64(paise)/100)) * 100
+}
@@ -0,0 +1,123 @@
+package tax
+
+import (
+    "testing"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/types"
+)
+
+func inr(rupees int64) *money.Money {
+    m := money.FromMajor(rupees, "INR")
+    return &m
+}
+
+func TestComputeTCSSlabs(t *testing.T) {
+    const pan = "ABCDE1234F"
+    tests := []struct {
+        name  string
+        pkg   int64
+        prior int64
+        pan   string
+        // TCS in rupees and the rate of each line
+        want  int64
+        rates []float64
+    }{
+        {"below the threshold", 5_00_000, 0, pan, 25_000, []float64{5}},
+        {"up to the threshold", 10_00_000, 0, pan, 50_000, []float64{5}},
+        {"ten rupees over", 10_00_010, 0, pan, 50_002, []float64{5, 20}},
+        {"prior remittances reach the threshold", 1_00_000, 10_00_000, pan, 20_000, []float64{20}},
+        {"payment crosses the threshold", 5_00_000, 8_00_000, pan, 70_000, []float64{5, 20}},
+        {"one rupee over, rounded away", 2_00_001, 8_00_000, pan, 10_000, []float64{5, 20}},
+        {"without a PAN", 5_00_000, 0, "", 50_000, []float64{10}},
+        {"without a PAN across the threshold", 5_00_000, 8_00_000, "", 1_40_000, []float64{10, 40}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            in := types.TaxInputs{Overseas: true, PAN: tt.pan, GSTScheme: SchemeNone, PriorRemittances: inr(tt.prior)}
+            res, err := Compute(*inr(tt.pkg), in, DefaultRules())
+            if err != nil {
+                t.Fatal(err)
+            }
+            if res.TCS.MinorUnits != tt.want*100 {
+                t.Errorf("TCS %s, want %s", res.TCS, money.FromMajor(tt.want, "INR"))
+            }
+            if len(res.Lines) != len(tt.rates) {
+                t.Fatalf("%d lines, want %d", len(res.Lines), len(tt.rates))
+            }
+            var bases int64
+            for i, line := range res.Lines {
+                if line.Kind != KindTCS || line.RatePercent != tt.rates[i] {
+                    t.Errorf("line %d is %s at %g%%, want TCS at %g%%", i, line.Kind, line.RatePercent, tt.rates[i])
+                }
+                bases += line.Base.MinorUnits
+            }
+            if bases != tt.pkg*100 {
+                t.Errorf("slab bases add up to %s, want the whole payment", money.New(bases, "INR"))
+            }
+            if payable := (tt.pkg + tt.want) * 100; res.Payable.MinorUnits != payable {
+                t.Errorf("payable %s, want %s", res.Payable, money.New(payable, "INR"))
+            }
+        })
+    }
+}
+
+func TestComputeGST(t *testing.T) {
+    tests := []struct {
+        name string
+        in   types.TaxInputs
+        // GST and TCS in rupees, and the labels of the GST lines
+        gst, tcs int64
+        labels   []string
+    }{
+        {"domestic, no states", types.TaxInputs{}, 50_000, 0, []string{"GST @ 5%"}},
+        {"same state", types.TaxInputs{SupplierState: "KA", PlaceOfSupply: "ka"}, 50_000, 0, []string{"CGST @ 2.5%", "SGST @ 2.5%"}},
+        {"other state", types.TaxInputs{SupplierState: "KA", PlaceOfSupply: "MH"}, 50_000, 0, []string{"IGST @ 5%"}},
+        {"service fee", types.TaxInputs{GSTScheme: SchemeServiceFee, ServiceComponent: inr(50_000)}, 9_000, 0, []string{"GST @ 18%"}},
+        // TCS is on the GST inclusive 10,50,000, which crosses the threshold
+        {"overseas", types.TaxInputs{Overseas: true, PAN: "ABCDE1234F"}, 50_000, 60_000, []string{"GST @ 5%"}},
+    }
+    for _, tt := range tests {
+        t.Run(tt.name, func(t *testing.T) {
+            res, err := Compute(*inr(10_00_000), tt.in, DefaultRules())
+            if err != nil {
+                t.Fatal(err)
+            }
+            if res.GST.MinorUnits != tt.gst*100 || res.TCS.MinorUnits != tt.tcs*100 {
+                t.Errorf("GST %s and TCS %s, want %s and %s", res.GST, res.TCS, money.FromMajor(tt.gst, "INR"), money.FromMajor(tt.tcs, "INR"))
+            }
+            var labels []string
+            for _, line := range res.Lines {
+                if line.Kind == KindGST {
+                    labels = append(labels, line.Label.String())
+                }
+            }
+            if len(labels) != len(tt.labels) {
+                t.Fatalf("GST lines %q, want %q", labels, tt.labels)
+            }
+            for i := range labels {
+                if labels[i] != tt.labels[i] {
+                    t.Errorf("GST lines %q, want %q", labels, tt.labels)
+                }
+            }
+        })
+    }
+}
+
+func TestComputeErrors(t *testing.T) {
+    usd := money.FromMajor(100, "USD")
+    tests := []struct {
+        name string
+        pkg  money.Money
+        in   types.TaxInputs
+    }{
+        {"package not in INR", usd, types.TaxInputs{}},
+        {"service fee without a component", *inr(1000), types.TaxInputs{GSTScheme: SchemeServiceFee}},
+        {"unknown scheme", *inr(1000), types.TaxInputs{GSTScheme: "reverseCharge"}},
+        {"prior remittances not in INR", *inr(1000), types.TaxInputs{Overseas: true, PriorRemittances: &usd}},
+    }
+    for _, tt := range tests {
+        if _, err := Compute(tt.pkg, tt.in, DefaultRules()); err == nil {
+            t.Errorf("%s: no error", tt.name)
+        }
+    }
+}
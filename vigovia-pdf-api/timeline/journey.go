@@ -0,0 +1,119 @@
+// timeline/journey.go
+package timeline
+
+import (
+    "strings"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/iata"
+    "vigovia-pdf-api/types"
+)
+
+// maxConnection is the longest wait between two flights on the same PNR
+// that still makes them one journey
+const maxConnection = 24 * time.Hour
+
+// Journey is a flight, or connecting flights flown one after another
+type Journey struct {
+    Name     string
+    Segments []types.Flight
+    // Indexes are the segments' positions in the flights Journeys was given
+    Indexes []int
+    // Layovers[i] is the wait between Segments[i] and Segments[i+1]
+    Layovers []Layover
+}
+
+// Layover is the wait at an airport between two segments
+type Layover struct {
+    Airport  string
+    Duration time.Duration
+    // Known is false when a time is missing or can't be placed in a zone
+    Known bool
+}
+
+// Journeys groups flights into journeys in departure order. Segments with
+// the same Journey are grouped by name; others join the journey before them
+// when they share its PNR and leave from where it landed within a day.
+// Times without a zone are read as Times reads them.
+func Journeys(flights []types.Flight, zone *time.Location) []Journey {
+    var journeys []Journey
+    named := map[string]int{}
+    for _, i := range flightOrder(flights) {
+        f := flights[i]
+        at := -1
+        if f.Journey != "" {
+            if j, ok := named[f.Journey]; ok {
+                at = j
+            }
+        } else if n := len(journeys); n > 0 && journeys[n-1].Name == "" && connects(journeys[n-1].last(), f, zone) {
+            at = n - 1
+        }
+        if at < 0 {
+            journeys = append(journeys, Journey{Name: f.Journey})
+            at = len(journeys) - 1
+            if f.Journey != "" {
+                named[f.Journey] = at
+            }
+        }
+        j := &journeys[at]
+        if len(j.Segments) > 0 {
+            j.Layovers = append(j.Layovers, layover(j.last(), f, zone))
+        }
+        j.Segments = append(j.Segments, f)
+        j.Indexes = append(j.Indexes, i)
+    }
+    return journeys
+}
+
+func (j Journey) last() types.Flight {
+    return j.Segments[len(j.Segments)-1]
+}
+
+// connects reports whether next is a connection from prev on the same booking
+func connects(prev, next types.Flight, zone *time.Location) bool {
+    if prev.PNR == "" || !strings.EqualFold(prev.PNR, next.PNR) || !sameAirport(prev.To, next.From) {
+        return false
+    }
+    l := layover(prev, next, zone)
+    return l.Known && l.Duration > 0 && l.Duration <= maxConnection
+}
+
+func layover(prev, next types.Flight, zone *time.Location) Layover {
+    l := Layover{Airport: next.From}
+    _, arr := Times(prev, zone)
+    dep, _ := Times(next, zone)
+    if arr.IsZero() || dep.IsZero() {
+        return l
+    }
+    d, err := dep.Sub(arr)
+    if err != nil {
+        return l
+    }
+    l.Duration, l.Known = d, true
+    return l
+}
+
+// sameAirport reports whether two places name one airport, e.g. BLR and
+// Bangalore
+func sameAirport(a, b string) bool {
+    if x, ok := iata.FindAirport(a); ok {
+        if y, ok := iata.FindAirport(b); ok {
+            return x.Code == y.Code
+        }
+    }
+    return a != "" && strings.EqualFold(strings.TrimSpace(a), strings.TrimSpace(b))
+}
+
+// Times are a flight's departure and arrival placed in the zones of the
+// airports it flies between. Times without a zone at an airport missing from
+// the reference data are read in zone, which may be nil.
+func Times(f types.Flight, zone *time.Location) (dep, arr datetime.DateTime) {
+    return f.Departure.WithZone(airportZone(f.From, zone)), f.Arrival.WithZone(airportZone(f.To, zone))
+}
+
+func airportZone(place string, fallback *time.Location) *time.Location {
+    if loc := iata.AirportZone(place); loc != nil {
+        return loc
+    }
+    return fallback
+}
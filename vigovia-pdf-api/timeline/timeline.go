@@ -0,0 +1,376 @@
+// timeline/timeline.go
+package timeline
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/iata"
+    "vigovia-pdf-api/types"
+    "vigovia-pdf-api/visa"
+)
+
+// Event kinds, in the order events on the same day are listed
+const (
+    KindDay     = "day"
+    KindFlight  = "flight"
+    KindHotel   = "hotel"
+    KindPayment = "payment"
+    KindVisa    = "visa"
+)
+
+var kindOrder = map[string]int{KindPayment: 0, KindVisa: 1, KindFlight: 2, KindHotel: 3, KindDay: 4}
+
+// Event is one dated item of an itinerary. Path is where it is in the
+// payload, e.g. $.flights[1].
+type Event struct {
+    Kind  string            `json:"kind" schema:"enum=day|flight|hotel|payment|visa"`
+    Path  string            `json:"path"`
+    Title string            `json:"title"`
+    Date  datetime.Date     `json:"date"`
+    End   datetime.Date     `json:"end"`
+    Start datetime.DateTime `json:"start"`
+    // Duration is set for flights with departure and arrival times
+    Duration        string `json:"duration,omitempty"`
+    DurationMinutes int    `json:"durationMinutes,omitempty"`
+    // Layover is the wait before a connecting flight
+    Layover string `json:"layover,omitempty"`
+}
+
+// Warning is a date problem found in an itinerary
+type Warning struct {
+    Path    string `json:"path"`
+    Message string `json:"message"`
+}
+
+// Report lists an itinerary's events in date order with any problems found
+type Report struct {
+    Start    datetime.Date `json:"start"`
+    End      datetime.Date `json:"end"`
+    Nights   int           `json:"nights"`
+    Events   []Event       `json:"events"`
+    Warnings []Warning     `json:"warnings"`
+}
+
+// Build sorts the itinerary's dated items into a timeline and checks them:
+// ranges that end before they start, hotel stays that overlap, flights that
+// land before they take off, connections that don't line up, passports that
+// expire too soon, and anything dated outside the trip. Times without a zone
+// are read in the zone of their airport, or the trip's TimeZone for airports
+// the reference data lacks.
+func Build(data types.ItineraryData) *Report {
+    trip := data.TripDetails
+    r := &Report{Start: trip.DepartureDate, End: trip.ArrivalDate, Events: []Event{}, Warnings: []Warning{}}
+    warn := func(path, format string, args ...interface{}) {
+        r.Warnings = append(r.Warnings, Warning{Path: path, Message: fmt.Sprintf(format, args...)})
+    }
+
+    var zone *time.Location
+    if trip.TimeZone != "" {
+        zone, _ = datetime.LoadZone(trip.TimeZone)
+    }
+
+    switch {
+    case r.Start.IsZero() || r.End.IsZero():
+    case r.End.Before(r.Start):
+        warn("$.tripDetails.arrivalDate", "the trip ends on %s, before it starts on %s", r.End, r.Start)
+    default:
+        r.Nights = r.Start.DaysUntil(r.End)
+    }
+    // inTrip warns about a date outside the trip's dates
+    inTrip := func(path string, d datetime.Date) {
+        if d.IsZero() || r.Start.IsZero() || r.End.IsZero() || r.End.Before(r.Start) {
+            return
+        }
+        if d.Before(r.Start) || d.After(r.End) {
+            warn(path, "%s is outside the trip, which runs from %s to %s", d, r.Start, r.End)
+        }
+    }
+
+    for i, day := range data.DailyItinerary {
+        path := fmt.Sprintf("$.dailyItinerary[%d].date", i)
+        if day.Date.IsZero() {
+            continue
+        }
+        r.Events = append(r.Events, Event{Kind: KindDay, Path: path, Title: fmt.Sprintf("Day %d", day.Day), Date: day.Date})
+        inTrip(path, day.Date)
+        if !r.Start.IsZero() && day.Day > 0 {
+            if want := r.Start.AddDays(day.Day - 1); !want.Equal(day.Date) {
+                warn(path, "day %d falls on %s but is dated %s", day.Day, want, day.Date)
+            }
+        }
+    }
+
+    layovers := map[int]Layover{}
+    for _, j := range Journeys(data.Flights, zone) {
+        for k, l := range j.Layovers {
+            prev, i := j.Segments[k], j.Indexes[k+1]
+            layovers[i] = l
+            path := fmt.Sprintf("$.flights[%d]", i)
+            switch {
+            case !sameAirport(prev.To, j.Segments[k+1].From):
+                warn(path+".from", "leaves from %s but the journey's previous flight lands at %s", j.Segments[k+1].From, prev.To)
+            case l.Known && l.Duration <= 0:
+                warn(path+".departure", "departs before the journey's previous flight lands at %s", prev.To)
+            }
+        }
+    }
+
+    for i, flight := range data.Flights {
+        path := fmt.Sprintf("$.flights[%d]", i)
+        dep, arr := Times(flight, zone)
+        e := Event{Kind: KindFlight, Path: path, Title: flightTitle(flight), Date: FlightDate(flight), Start: dep}
+        if !dep.IsZero() && !flight.Date.IsZero() && !dep.Date().Equal(flight.Date) {
+            warn(path+".departure", "departs on %s but the flight is dated %s", dep.Date(), flight.Date)
+        }
+        if !dep.IsZero() && !arr.IsZero() {
+            e.End = arr.Date()
+            d, err := arr.Sub(dep)
+            switch {
+            case err != nil:
+                warn(path, "give both times a zone, or the trip a timeZone, to work out the flight's duration")
+            case d <= 0:
+                warn(path+".arrival", "lands at %s, before it departs at %s", arr, dep)
+            default:
+                e.Duration, e.DurationMinutes = datetime.FormatDuration(d), int(d.Minutes())
+            }
+        }
+        checkPlaces(path, flight, warn)
+        if l, ok := layovers[i]; ok && l.Known && l.Duration > 0 {
+            e.Layover = datetime.FormatDuration(l.Duration)
+        }
+        if !e.Date.IsZero() {
+            r.Events = append(r.Events, e)
+            inTrip(path+".date", e.Date)
+        }
+    }
+
+    for i, hotel := range data.Hotels {
+        path := fmt.Sprintf("$.hotels[%d]", i)
+        if !hotel.CheckIn.IsZero() && !hotel.CheckOut.IsZero() && !hotel.CheckOut.After(hotel.CheckIn) {
+            warn(path+".checkOut", "checks out on %s, not after checking in on %s", hotel.CheckOut, hotel.CheckIn)
+        }
+        if !hotel.CheckIn.IsZero() {
+            r.Events = append(r.Events, Event{Kind: KindHotel, Path: path, Title: hotel.Name, Date: hotel.CheckIn, End: hotel.CheckOut})
+        }
+        inTrip(path+".checkIn", hotel.CheckIn)
+        inTrip(path+".checkOut", hotel.CheckOut)
+    }
+    for _, pair := range overlappingStays(data.Hotels) {
+        a, b := data.Hotels[pair[0]], data.Hotels[pair[1]]
+        warn(fmt.Sprintf("$.hotels[%d]", pair[1]), "the stay at %s from %s overlaps the stay at %s until %s", b.Name, b.CheckIn, a.Name, a.CheckOut)
+    }
+
+    end := tripEnd(trip)
+    for i, traveller := range data.Travellers {
+        expiry := traveller.PassportExpiry
+        if !PassportExpiresSoon(trip, expiry) {
+            continue
+        }
+        path := fmt.Sprintf("$.travellers[%d].passportExpiry", i)
+        switch {
+        case !r.Start.IsZero() && expiry.Before(r.Start):
+            warn(path, "%s's passport expires on %s, before the trip starts on %s", traveller.Name, expiry, r.Start)
+        case expiry.Before(end):
+            warn(path, "%s's passport expires on %s, before the trip ends on %s", traveller.Name, expiry, end)
+        default:
+            warn(path, "%s's passport expires on %s, less than %d months after the trip ends on %s", traveller.Name, expiry, passportMonths, end)
+        }
+    }
+
+    for i, installment := range data.PaymentPlan.Installments {
+        if installment.DueDate.IsZero() {
+            continue
+        }
+        path := fmt.Sprintf("$.paymentPlan.installments[%d].dueDate", i)
+        r.Events = append(r.Events, Event{Kind: KindPayment, Path: path, Title: installment.Name, Date: installment.DueDate})
+        if !r.Start.IsZero() && installment.DueDate.After(r.Start) {
+            warn(path, "%s is due on %s, after the trip starts", installment.Name, installment.DueDate)
+        }
+    }
+
+    for i, v := range data.Visas {
+        path := fmt.Sprintf("$.visas[%d]", i)
+        title := visaTitle(data, v)
+        if v.Traveller != "" && len(data.Travellers) > 0 && !coversAnyone(v, data.Travellers) {
+            warn(path+".traveller", "the %s names %s, who isn't among the travellers", title, v.Traveller)
+        }
+        if v.ProcessingDate.IsZero() {
+            continue
+        }
+        if v.Traveller != "" {
+            title += " (" + v.Traveller + ")"
+        }
+        r.Events = append(r.Events, Event{Kind: KindVisa, Path: path + ".processingDate", Title: title, Date: v.ProcessingDate})
+        if !r.Start.IsZero() && !v.ProcessingDate.Before(r.Start) {
+            warn(path+".processingDate", "the %s is processed on %s, not before the trip starts on %s", title, v.ProcessingDate, r.Start)
+        }
+    }
+
+    sort.SliceStable(r.Events, func(i, j int) bool {
+        a, b := r.Events[i], r.Events[j]
+        if !a.Date.Equal(b.Date) {
+            return a.Date.Before(b.Date)
+        }
+        if !a.Start.IsZero() && !b.Start.IsZero() && !a.Start.Time().Equal(b.Start.Time()) {
+            return a.Start.Before(b.Start)
+        }
+        return kindOrder[a.Kind] < kindOrder[b.Kind]
+    })
+    return r
+}
+
+// checkPlaces warns about airports and airlines missing from the reference
+// data, suggesting the closest match for a likely typo
+func checkPlaces(path string, f types.Flight, warn func(path, format string, args ...interface{})) {
+    for _, place := range []struct{ field, value string }{{"from", f.From}, {"to", f.To}} {
+        if strings.TrimSpace(place.value) == "" || iata.AirportZone(place.value) != nil {
+            continue
+        }
+        if s := iata.SuggestAirports(place.value, 1); len(s) > 0 {
+            warn(path+"."+place.field, "%q isn't a known airport; did you mean %s?", place.value, s[0].Display())
+        } else {
+            warn(path+"."+place.field, "%q isn't a known airport, so its times are read in the trip's timeZone", place.value)
+        }
+    }
+    if strings.TrimSpace(f.Airline) == "" {
+        return
+    }
+    if _, ok := iata.FindAirline(f.Airline); !ok {
+        if s := iata.SuggestAirlines(f.Airline, 1); len(s) > 0 {
+            warn(path+".airline", "%q isn't a known airline; did you mean %s (%s)?", f.Airline, s[0].Name, s[0].Code)
+        }
+    }
+}
+
+// visaTitle names a visa by its type and country, e.g. "Schengen visa for
+// France"
+func visaTitle(data types.ItineraryData, v types.Visa) string {
+    title := strings.TrimSpace(v.VisaType + " visa")
+    if strings.Contains(strings.ToLower(v.VisaType), "visa") {
+        title = strings.TrimSpace(v.VisaType)
+    }
+    if country := visa.Country(data, v); country != "" {
+        title += " for " + visa.CountryName(country)
+    }
+    return title
+}
+
+func coversAnyone(v types.Visa, travellers []types.Traveller) bool {
+    for _, traveller := range travellers {
+        if visa.Covers(v, traveller) {
+            return true
+        }
+    }
+    return false
+}
+
+// overlappingStays returns pairs of hotels whose nights overlap, earlier
+// check-in first. Checking out on the day of the next check-in is fine.
+func overlappingStays(hotels []types.Hotel) [][2]int {
+    order := make([]int, 0, len(hotels))
+    for i, h := range hotels {
+        if !h.CheckIn.IsZero() && h.CheckOut.After(h.CheckIn) {
+            order = append(order, i)
+        }
+    }
+    sort.SliceStable(order, func(a, b int) bool {
+        return hotels[order[a]].CheckIn.Before(hotels[order[b]].CheckIn)
+    })
+    var pairs [][2]int
+    for a := 0; a < len(order); a++ {
+        for b := a + 1; b < len(order); b++ {
+            if !hotels[order[b]].CheckIn.Before(hotels[order[a]].CheckOut) {
+                break
+            }
+            pairs = append(pairs, [2]int{order[a], order[b]})
+        }
+    }
+    return pairs
+}
+
+func flightTitle(f types.Flight) string {
+    from, to := iata.AirportName(f.From), iata.AirportName(f.To)
+    if f.FlightNumber != "" {
+        return fmt.Sprintf("%s %s %s-%s", iata.AirlineName(f.Airline), f.FlightNumber, from, to)
+    }
+    return fmt.Sprintf("%s %s-%s", iata.AirlineName(f.Airline), from, to)
+}
+
+// FlightDate is the day a flight departs, from its date or departure time
+func FlightDate(f types.Flight) datetime.Date {
+    if f.Date.IsZero() {
+        return f.Departure.Date()
+    }
+    return f.Date
+}
+
+// passportMonths is how long after leaving many countries want a visitor's
+// passport to stay valid
+const passportMonths = 6
+
+// PassportExpiresSoon reports whether a passport expiring on expiry runs out
+// less than six months after the trip ends
+func PassportExpiresSoon(trip types.TripDetails, expiry datetime.Date) bool {
+    end := tripEnd(trip)
+    return !expiry.IsZero() && !end.IsZero() && expiry.Before(end.AddMonths(passportMonths))
+}
+
+// tripEnd is the day the trip ends, or starts when its end isn't known
+func tripEnd(trip types.TripDetails) datetime.Date {
+    if trip.ArrivalDate.IsZero() {
+        return trip.DepartureDate
+    }
+    return trip.ArrivalDate
+}
+
+// DayDate is the day's date, or the date it falls on counting from the
+// trip's departure when it has none
+func DayDate(trip types.TripDetails, day types.DayItinerary) datetime.Date {
+    if day.Date.IsZero() && !trip.DepartureDate.IsZero() && day.Day > 0 {
+        return trip.DepartureDate.AddDays(day.Day - 1)
+    }
+    return day.Date
+}
+
+// Flights returns the flights in departure order; undated flights keep their
+// place at the end
+func Flights(flights []types.Flight) []types.Flight {
+    sorted := make([]types.Flight, 0, len(flights))
+    for _, i := range flightOrder(flights) {
+        sorted = append(sorted, flights[i])
+    }
+    return sorted
+}
+
+// flightOrder returns the flights' indexes in departure order
+func flightOrder(flights []types.Flight) []int {
+    order := make([]int, len(flights))
+    for i := range order {
+        order[i] = i
+    }
+    sort.SliceStable(order, func(i, j int) bool {
+        fi, fj := flights[order[i]], flights[order[j]]
+        a, b := FlightDate(fi), FlightDate(fj)
+        switch {
+        case a.IsZero() || b.IsZero():
+            return !a.IsZero() && b.IsZero()
+        case !a.Equal(b):
+            return a.Before(b)
+        }
+        return !fi.Departure.IsZero() && !fj.Departure.IsZero() && fi.Departure.Before(fj.Departure)
+    })
+    return order
+}
+
+// Hotels returns the hotels in check-in order; undated stays go last
+func Hotels(hotels []types.Hotel) []types.Hotel {
+    sorted := append([]types.Hotel(nil), hotels...)
+    sort.SliceStable(sorted, func(i, j int) bool {
+        a, b := sorted[i].CheckIn, sorted[j].CheckIn
+        return !a.IsZero() && (b.IsZero() || a.Before(b))
+    })
+    return sorted
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+    "encoding/json"
+    "net/http"
+    "vigovia-pdf-api/timeline"
+)
+
+// timelineHandler lists an itinerary's dated items in order and reports
+// overlapping stays, inverted ranges and dates outside the trip
+func timelineHandler(w http.ResponseWriter, r *http.Request) {
+    if r.Method == http.MethodOptions {
+        w.WriteHeader(http.StatusOK)
+        return
+    }
+
+    itineraryData, ok := decodeItinerary(w, r)
+    if !ok {
+        return
+    }
+
+    w.Header().Set("Content-Type", "application/json")
+    json.NewEncoder(w).Encode(timeline.Build(itineraryData))
+}
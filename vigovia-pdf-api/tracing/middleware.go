@@ -0,0 +1,48 @@
+// tracing/middleware.go
+package tracing
+
+import (
+    "net/http"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/propagation"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+    "go.opentelemetry.io/otel/trace"
+    "github.com/felixge/httpsnoop"
+    "github.com/gorilla/mux"
+    "vigovia-pdf-api/logging"
+)
+
+// Middleware starts a server span per request, continuing any trace passed
+// in W3C traceparent headers. Use it with mux's Router.Use so the span can be
+// named after the matched route template.
+func Middleware(next http.Handler) http.Handler {
+    return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+        ctx := otel.GetTextMapPropagator().Extract(r.Context(), propagation.HeaderCarrier(r.Header))
+
+        route := r.URL.Path
+        if current := mux.CurrentRoute(r); current != nil {
+            if tpl, err := current.GetPathTemplate(); err == nil {
+                route = tpl
+            }
+        }
+
+        ctx, span := Tracer().Start(ctx, r.Method+" "+route,
+            trace.WithSpanKind(trace.SpanKindServer),
+            trace.WithAttributes(
+                semconv.HTTPRequestMethodKey.String(r.Method),
+                semconv.HTTPRoute(route),
+                semconv.URLPath(r.URL.Path),
+                attribute.String("request.id", logging.RequestIDFromContext(r.Context())),
+            ),
+        )
+        defer span.End()
+
+        m := httpsnoop.CaptureMetrics(next, w, r.WithContext(ctx))
+        span.SetAttributes(semconv.HTTPResponseStatusCode(m.Code))
+        if m.Code >= http.StatusInternalServerError {
+            span.SetStatus(codes.Error, http.StatusText(m.Code))
+        }
+    })
+}
@@ -0,0 +1,31 @@
+// tracing/observer.go
+package tracing
+
+import (
+    "context"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+)
+
+// RenderObserver turns GeneratePDF sections into child spans of the span in ctx
+type RenderObserver struct {
+    ctx context.Context
+}
+
+func NewRenderObserver(ctx context.Context) RenderObserver {
+    return RenderObserver{ctx: ctx}
+}
+
+func (o RenderObserver) StartSection(section string) func() {
+    _, span := StartSpan(o.ctx, "render."+section, attribute.String("pdf.section", section))
+    return func() {
+        span.End()
+    }
+}
+
+func (o RenderObserver) Rendered(pages, size int) {
+    trace.SpanFromContext(o.ctx).SetAttributes(
+        attribute.Int("pdf.pages", pages),
+        attribute.Int("pdf.size_bytes", size),
+    )
+}
@@ -0,0 +1,91 @@
+// tracing/tracing.go
+package tracing
+
+import (
+    "context"
+    "fmt"
+    "go.opentelemetry.io/otel"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+    "go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "vigovia-pdf-api"
+
+// Config selects where spans are exported. With Enabled false a no-op tracer
+// is installed so instrumented code paths cost next to nothing.
+type Config struct {
+    Enabled     bool    `json:"enabled"`
+    Endpoint    string  `json:"endpoint"` // host:port of the OTLP/HTTP collector, defaults to OTEL_EXPORTER_OTLP_ENDPOINT
+    Insecure    bool    `json:"insecure"`
+    ServiceName string  `json:"serviceName"`
+    SampleRatio float64 `json:"sampleRatio"`
+}
+
+// Setup installs the global tracer provider and propagator. The returned
+// func flushes pending spans and must be called on shutdown.
+func Setup(ctx context.Context, cfg Config) (func(context.Context) error, error) {
+    otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(
+        propagation.TraceContext{},
+        propagation.Baggage{},
+    ))
+
+    if !cfg.Enabled {
+        return func(context.Context) error { return nil }, nil
+    }
+
+    var opts []otlptracehttp.Option
+    if cfg.Endpoint != "" {
+        opts = append(opts, otlptracehttp.WithEndpoint(cfg.Endpoint))
+    }
+    if cfg.Insecure {
+        opts = append(opts, otlptracehttp.WithInsecure())
+    }
+    exporter, err := otlptracehttp.New(ctx, opts...)
+    if err != nil {
+        return nil, fmt.Errorf("creating OTLP exporter: %w", err)
+    }
+
+    provider := NewTracerProvider(exporter, cfg.ServiceName, cfg.SampleRatio)
+    otel.SetTracerProvider(provider)
+    return provider.Shutdown, nil
+}
+
+// NewTracerProvider builds a provider around any exporter. Tests pass an
+// in-process exporter such as tracetest.NewInMemoryExporter().
+func NewTracerProvider(exporter sdktrace.SpanExporter, serviceName string, sampleRatio float64) *sdktrace.TracerProvider {
+    if serviceName == "" {
+        serviceName = instrumentationName
+    }
+    res := resource.NewSchemaless(semconv.ServiceName(serviceName))
+
+    return sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(res),
+        sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(sampleRatio))),
+    )
+}
+
+// Tracer returns the service tracer from the global provider
+func Tracer() trace.Tracer {
+    return otel.Tracer(instrumentationName)
+}
+
+// StartSpan starts a child span of whatever span is in ctx
+func StartSpan(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+    return Tracer().Start(ctx, name, trace.WithAttributes(attrs...))
+}
+
+// RecordError marks the span as failed
+func RecordError(span trace.Span, err error) {
+    if err == nil {
+        return
+    }
+    span.RecordError(err)
+    span.SetStatus(codes.Error, err.Error())
+}
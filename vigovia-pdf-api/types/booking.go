@@ -0,0 +1,16 @@
+// types/booking.go
+package types
+
+// Supplier is who delivers a booked service: the operator a traveller calls
+// about a transfer or activity, or the wholesaler a stay was booked through
+type Supplier struct {
+    Name  string `json:"name" schema:"required"`
+    Phone string `json:"phone,omitempty"`
+    Email string `json:"email,omitempty"`
+}
+
+// Whether voucher pages are printed after the itinerary
+const (
+    VouchersAppend = "append"
+    VouchersOmit   = "omit"
+)
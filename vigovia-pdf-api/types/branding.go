@@ -0,0 +1,25 @@
+// types/branding.go
+package types
+
+// Branding holds the company details printed in the header and footer of every PDF
+type Branding struct {
+    CompanyName  string   `json:"companyName"`
+    Tagline      string   `json:"tagline"`
+    AddressLines []string `json:"addressLines"`
+    Phone        string   `json:"phone"`
+    Email        string   `json:"email"`
+}
+
+// DefaultBranding is the Vigovia branding used when nothing else is configured
+func DefaultBranding() Branding {
+    return Branding{
+        CompanyName: "Vigovia Tech Pvt. Ltd",
+        Tagline:     "PLAN.PACK.GO",
+        AddressLines: []string{
+            "Registered Office: Hd-109 Cinnabar Hills,",
+            "Links Business Park, Karnataka, India",
+        },
+        Phone: "+91-99X9999999",
+        Email: "Contact@Vigovia.Com",
+    }
+}
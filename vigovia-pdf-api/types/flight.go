@@ -0,0 +1,44 @@
+// types/flight.go
+package types
+
+import "vigovia-pdf-api/datetime"
+
+// Cabin classes a segment can be booked in
+const (
+    CabinEconomy        = "economy"
+    CabinPremiumEconomy = "premiumEconomy"
+    CabinBusiness       = "business"
+    CabinFirst          = "first"
+)
+
+// Flight is one flown segment. Connecting segments, such as DEL-DXB and
+// DXB-CDG on one ticket, share a Journey and the layovers between them are
+// worked out from their times.
+type Flight struct {
+    ID           string        `json:"id"`
+    Airline      string        `json:"airline"`
+    Date         datetime.Date `json:"date"`
+    From         string        `json:"from"`
+    To           string        `json:"to"`
+    FlightNumber string        `json:"flightNumber"`
+    // Optional local departure and arrival times, ideally with the airports'
+    // zones so the flight's duration can be worked out
+    Departure         datetime.DateTime `json:"departure,omitempty"`
+    Arrival           datetime.DateTime `json:"arrival,omitempty"`
+    DepartureTerminal string            `json:"departureTerminal,omitempty"`
+    ArrivalTerminal   string            `json:"arrivalTerminal,omitempty"`
+    Cabin             string            `json:"cabin,omitempty" schema:"enum=economy|premiumEconomy|business|first"`
+    Baggage           *Baggage          `json:"baggage,omitempty"`
+    // PNR is the airline booking reference
+    PNR string `json:"pnr,omitempty" schema:"pattern=^[A-Za-z0-9]{6}$"`
+    // Journey names the trip the segment is part of, e.g. "outbound". Without
+    // one, segments on the same PNR that connect within a day are grouped.
+    Journey string `json:"journey,omitempty"`
+}
+
+// Baggage is the allowance for one traveller on a segment
+type Baggage struct {
+    CheckedPieces int `json:"checkedPieces,omitempty" schema:"min=0"`
+    CheckedKg     int `json:"checkedKg,omitempty" schema:"min=0"`
+    CabinKg       int `json:"cabinKg,omitempty" schema:"min=0"`
+}
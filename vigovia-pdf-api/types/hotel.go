@@ -0,0 +1,61 @@
+// types/hotel.go
+package types
+
+import (
+    "fmt"
+    "vigovia-pdf-api/datetime"
+)
+
+// Meal plans as Indian hotels quote them
+const (
+    MealPlanEP  = "EP"  // room only
+    MealPlanCP  = "CP"  // breakfast
+    MealPlanMAP = "MAP" // breakfast and one other meal
+    MealPlanAP  = "AP"  // all meals
+    MealPlanAI  = "AI"  // all meals, drinks and snacks
+)
+
+// Hotel is one stay. Nights may be left out and is then worked out from the
+// check-in and check-out dates.
+type Hotel struct {
+    ID       string        `json:"id"`
+    City     string        `json:"city"`
+    CheckIn  datetime.Date `json:"checkIn"`
+    CheckOut datetime.Date `json:"checkOut"`
+    Nights   int           `json:"nights" schema:"min=0"`
+    Name     string        `json:"name"`
+    Address  string        `json:"address,omitempty"`
+    Phone    string        `json:"phone,omitempty"`
+    // RoomType is as the hotel names it, e.g. "Deluxe Double, Sea View"
+    RoomType string `json:"roomType,omitempty"`
+    Rooms    int    `json:"rooms,omitempty" schema:"min=0"`
+    MealPlan string `json:"mealPlan,omitempty" schema:"enum=EP|CP|MAP|AP|AI" doc:"EP room only, CP breakfast, MAP breakfast and dinner, AP all meals, AI all inclusive"`
+    // ConfirmationNumber is the hotel's reference. Stays with one, or with a
+    // Supplier they were booked through, get a voucher page.
+    ConfirmationNumber string `json:"confirmationNumber,omitempty"`
+    Supplier *Supplier `json:"supplier,omitempty"`
+}
+
+// StayNights is the number of nights between check-in and check-out, or 0
+// when either date is missing
+func (h Hotel) StayNights() int {
+    if h.CheckIn.IsZero() || h.CheckOut.IsZero() {
+        return 0
+    }
+    return h.CheckIn.DaysUntil(h.CheckOut)
+}
+
+// CheckNights fills in Nights from the dates when it is left out, and
+// reports a Nights that disagrees with them
+func (h *Hotel) CheckNights() error {
+    stay := h.StayNights()
+    switch {
+    case stay <= 0:
+        return nil
+    case h.Nights == 0:
+        h.Nights = stay
+    case h.Nights != stay:
+        return fmt.Errorf("%d nights given, but %s to %s is %d", h.Nights, h.CheckIn, h.CheckOut, stay)
+    }
+    return nil
+}
@@ -0,0 +1,15 @@
+// types/invoice.go
+package types
+
+// Party is the supplier or the recipient named on a tax invoice. Recipients
+// without a GSTIN are unregistered and invoiced as consumers.
+type Party struct {
+    Name         string   `json:"name" schema:"required"`
+    GSTIN        string   `json:"gstin,omitempty" schema:"pattern=^[0-9]{2}[A-Z]{5}[0-9]{4}[A-Z][1-9A-Z]Z[0-9A-Z]$"`
+    PAN          string   `json:"pan,omitempty" schema:"pattern=^[A-Z]{5}[0-9]{4}[A-Z]$"`
+    AddressLines []string `json:"addressLines,omitempty"`
+    // Two letter state code, the same codes TaxInputs uses
+    State string `json:"state,omitempty" schema:"pattern=^[A-Z]{2}$"`
+    Email string `json:"email,omitempty"`
+    Phone string `json:"phone,omitempty"`
+}
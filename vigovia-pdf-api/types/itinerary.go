@@ -1,58 +1,56 @@
 // types/itinerary.go
 package types
 
+import (
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/money"
+)
+
 type TripDetails struct {
-    CustomerName      string `json:"customerName"`
-    Destination       string `json:"destination"`
-    Days              int    `json:"days"`
-    Nights            int    `json:"nights"`
-    DepartureFrom     string `json:"departureFrom"`
-    DepartureDate     string `json:"departureDate"`
-    ArrivalDate       string `json:"arrivalDate"`
-    NumberOfTravelers int    `json:"numberOfTravelers"`
+    CustomerName      string `json:"customerName" schema:"required"`
+    Destination       string `json:"destination" schema:"required"`
+    Days              int    `json:"days" schema:"min=0"`
+    Nights            int    `json:"nights" schema:"min=0"`
+    DepartureFrom     string        `json:"departureFrom"`
+    DepartureDate     datetime.Date `json:"departureDate"`
+    ArrivalDate       datetime.Date `json:"arrivalDate"`
+    NumberOfTravelers int           `json:"numberOfTravelers" schema:"min=0"`
+    // TimeZone is the destination's IANA zone, used for times given without one
+    TimeZone string `json:"timeZone,omitempty" schema:"format=time-zone"`
 }
 
 type Activity struct {
     ID          string `json:"id"`
     Name        string `json:"name"`
     Description string `json:"description"`
-    Price       int    `json:"price"`
+    Price       money.Money `json:"price"`
     Duration    string `json:"duration"`
-    Type        string `json:"type"`
+    Type        string `json:"type" schema:"enum=morning|afternoon|evening"`
+    // ConfirmationNumber and Supplier mark a booked activity, which gets a
+    // voucher page
+    ConfirmationNumber string    `json:"confirmationNumber,omitempty"`
+    Supplier           *Supplier `json:"supplier,omitempty"`
 }
 
 type Transfer struct {
     ID          string `json:"id"`
     Type        string `json:"type"`
     Timing      string `json:"timing"`
-    Price       int    `json:"price"`
-    Capacity    int    `json:"capacity"`
+    Price       money.Money `json:"price"`
+    Capacity    int    `json:"capacity" schema:"min=0"`
     Description string `json:"description"`
+    // ConfirmationNumber and Supplier mark a booked transfer, which gets a
+    // voucher page
+    ConfirmationNumber string    `json:"confirmationNumber,omitempty"`
+    Supplier           *Supplier `json:"supplier,omitempty"`
 }
 
 type DayItinerary struct {
-    Day        int        `json:"day"`
-    Date       string     `json:"date"`
+    Day        int           `json:"day"`
+    Date       datetime.Date `json:"date"`
     Activities []Activity `json:"activities"`
     Transfers  []Transfer `json:"transfers"`
-}
-
-type Flight struct {
-    ID           string `json:"id"`
-    Airline      string `json:"airline"`
-    Date         string `json:"date"`
-    From         string `json:"from"`
-    To           string `json:"to"`
-    FlightNumber string `json:"flightNumber"`
-}
-
-type Hotel struct {
-    ID        string `json:"id"`
-    City      string `json:"city"`
-    CheckIn   string `json:"checkIn"`
-    CheckOut  string `json:"checkOut"`
-    Nights    int    `json:"nights"`
-    Name      string `json:"name"`
+    Image      string     `json:"image,omitempty"`
 }
 
 type ActivityTableEntry struct {
@@ -66,21 +64,16 @@ type ActivityTableEntry struct {
 type PaymentInstallment struct {
     ID          string `json:"id"`
     Name        string `json:"name"`
-    Amount      int    `json:"amount"`
-    DueDate     string `json:"dueDate"`
+    Amount      money.Money `json:"amount"`
+    DueDate     datetime.Date `json:"dueDate"`
     Description string `json:"description"`
 }
 
 type PaymentPlan struct {
-    TotalAmount  int                `json:"totalAmount"`
+    TotalAmount  money.Money        `json:"totalAmount"`
     TCSCollected bool               `json:"tcsCollected"`
     Installments []PaymentInstallment `json:"installments"`
-}
-
-type VisaDetails struct {
-    VisaType       string `json:"visaType"`
-    Validity       string `json:"validity"`
-    ProcessingDate string `json:"processingDate"`
+    Tax          *TaxInputs         `json:"tax,omitempty"`
 }
 
 type ImportantNote struct {
@@ -103,15 +96,79 @@ type InclusionItem struct {
     Status   string `json:"status"`
 }
 
+// CurrentSchemaVersion is the payload version the generator works with. Older
+// payloads are upgraded by the migrate package before they are decoded.
+const CurrentSchemaVersion = 4
+
 type ItineraryData struct {
+    SchemaVersion  int                 `json:"schemaVersion,omitempty" schema:"min=1"`
     TripDetails    TripDetails         `json:"tripDetails"`
     DailyItinerary []DayItinerary      `json:"dailyItinerary"`
     Flights        []Flight            `json:"flights"`
     Hotels         []Hotel             `json:"hotels"`
     Activities     []ActivityTableEntry `json:"activities"`
     PaymentPlan    PaymentPlan         `json:"paymentPlan"`
-    VisaDetails    VisaDetails         `json:"visaDetails"`
+    Visas          []Visa              `json:"visas"`
     ImportantNotes []ImportantNote     `json:"importantNotes"`
     ServiceScope   []ServiceScope      `json:"serviceScope"`
     Inclusions     []InclusionItem     `json:"inclusions"`
-}
\ No newline at end of file
+
+    // Optional display of prices in a second currency. ExchangeRates
+    // overrides the server's configured rates for this document.
+    SecondaryCurrency string           `json:"secondaryCurrency,omitempty" schema:"pattern=^[A-Z]{3}$"`
+    ExchangeRates     *money.RateTable `json:"exchangeRates,omitempty"`
+
+    // Optional pricing rules; without them the breakdown only sums prices
+    Pricing *PricingRules `json:"pricing,omitempty"`
+
+    // Optional language the PDF is printed in, e.g. "hi" or "fr-FR"; without
+    // it the Accept-Language header decides
+    Locale string `json:"locale,omitempty" schema:"pattern=^[A-Za-z]+([-_][A-Za-z0-9]+)*$"`
+
+    // Optional list of everyone travelling, printed as a manifest and on
+    // vouchers; without it vouchers name the customer. When given it must
+    // match TripDetails.NumberOfTravelers.
+    Travellers []Traveller `json:"travellers,omitempty"`
+    // Optional link to the itinerary online, printed as a QR code on the
+    // first page
+    ItineraryURL string `json:"itineraryUrl,omitempty" schema:"pattern=^https?://"`
+    // Vouchers decides whether booked hotels, transfers and activities get
+    // voucher pages after the itinerary
+    Vouchers string `json:"vouchers,omitempty" schema:"enum=append|omit" doc:"Defaults to append"`
+}
+
+// Discount reduces the package price, either by a percentage of the marked up
+// subtotal or by a fixed amount
+type Discount struct {
+    Name    string       `json:"name"`
+    Percent float64      `json:"percent,omitempty" schema:"min=0"`
+    Amount  *money.Money `json:"amount,omitempty"`
+}
+
+// PricingRules drive the cost breakdown computed from activity and transfer prices
+type PricingRules struct {
+    MarkupPercent float64    `json:"markupPercent,omitempty" schema:"min=0"`
+    Discounts     []Discount `json:"discounts,omitempty"`
+    GSTPercent    float64    `json:"gstPercent,omitempty" schema:"min=0"`
+    TCSPercent    float64    `json:"tcsPercent,omitempty" schema:"min=0"`
+}
+
+// TaxInputs switch the payment plan to computed Indian taxes. With them
+// PaymentPlan.TotalAmount is the package price before GST and TCS, without
+// them it includes GST (tax.TotalIsPreTax).
+type TaxInputs struct {
+    // Overseas marks an overseas tour program package, which attracts TCS
+    Overseas bool `json:"overseas"`
+    // PAN of the traveller paying; without it TCS is collected at the higher rate
+    PAN string `json:"pan,omitempty" schema:"pattern=^[A-Z]{5}[0-9]{4}[A-Z]$"`
+    // PriorRemittances already paid for overseas packages by this PAN in the
+    // current financial year, counted against the TCS threshold
+    PriorRemittances *money.Money `json:"priorRemittances,omitempty"`
+    // GSTScheme is "tourOperator" (5% of the gross package, no input credit),
+    // "serviceFee" (18% of ServiceComponent) or "none"
+    GSTScheme        string       `json:"gstScheme,omitempty" schema:"enum=tourOperator|serviceFee|none"`
+    ServiceComponent *money.Money `json:"serviceComponent,omitempty"`
+    // Two letter state codes decide between CGST+SGST and IGST
+    SupplierState string `json:"supplierState,omitempty"`
+    PlaceOfSupply string `json:"placeOfSupply,omitempty"`
+}
@@ -0,0 +1,29 @@
+// types/payment.go
+package types
+
+import (
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/money"
+)
+
+// Payment methods accepted on payments and receipts
+const (
+    PaymentUPI          = "upi"
+    PaymentCard         = "card"
+    PaymentNetBanking   = "netBanking"
+    PaymentBankTransfer = "bankTransfer"
+    PaymentCash         = "cash"
+    PaymentCheque       = "cheque"
+)
+
+// Payment is money received from the customer. With an InstallmentID it is
+// applied to that installment first; otherwise it settles installments in
+// the order they fall due.
+type Payment struct {
+    ID            string        `json:"id,omitempty"`
+    InstallmentID string        `json:"installmentId,omitempty"`
+    Amount        money.Money   `json:"amount" schema:"required"`
+    Date          datetime.Date `json:"date" schema:"required"`
+    Method        string        `json:"method,omitempty" schema:"enum=upi|card|netBanking|bankTransfer|cash|cheque"`
+    Reference     string        `json:"reference,omitempty"`
+}
@@ -0,0 +1,40 @@
+// types/traveller.go
+package types
+
+import (
+    "fmt"
+    "vigovia-pdf-api/datetime"
+)
+
+// Age categories as airlines and hotels price them
+const (
+    AgeAdult  = "adult"
+    AgeChild  = "child"
+    AgeInfant = "infant"
+)
+
+// Traveller is someone on the trip, as named in their passport
+type Traveller struct {
+    Name string `json:"name" schema:"required"`
+    // AgeCategory is adult unless given
+    AgeCategory    string        `json:"ageCategory,omitempty" schema:"enum=adult|child|infant"`
+    PassportNumber string        `json:"passportNumber,omitempty" schema:"pattern=^[A-Za-z0-9]+$"`
+    PassportExpiry datetime.Date `json:"passportExpiry,omitempty"`
+    Nationality    string        `json:"nationality,omitempty" schema:"pattern=^[A-Z]{2}$" doc:"ISO 3166-1 alpha-2 code"`
+    MealPreference string        `json:"mealPreference,omitempty" schema:"enum=vegetarian|nonVegetarian|vegan|jain|halal|kosher|glutenFree"`
+}
+
+// CheckTravellers fills in the number of travellers from the list when it is
+// left out, and reports a list that disagrees with it
+func (d *ItineraryData) CheckTravellers() error {
+    listed, count := len(d.Travellers), d.TripDetails.NumberOfTravelers
+    switch {
+    case listed == 0:
+        return nil
+    case count == 0:
+        d.TripDetails.NumberOfTravelers = listed
+    case count != listed:
+        return fmt.Errorf("%d travellers listed, but numberOfTravelers is %d", listed, count)
+    }
+    return nil
+}
@@ -0,0 +1,31 @@
+// types/visa.go
+package types
+
+import "vigovia-pdf-api/datetime"
+
+// Visa application statuses, in the order an application moves through them
+const (
+    VisaDocumentsPending = "documentsPending"
+    VisaSubmitted        = "submitted"
+    VisaApproved         = "approved"
+)
+
+// Visa is one visa application: for a country, and for one traveller or the
+// whole group
+type Visa struct {
+    Country        string        `json:"country,omitempty" schema:"pattern=^[A-Z]{2}$" doc:"ISO 3166-1 alpha-2 code of the country the visa is for, defaulting to the destination's"`
+    Traveller      string        `json:"traveller,omitempty" doc:"Name of the traveller the visa is for, as in travellers; without it the visa covers everyone"`
+    VisaType       string        `json:"visaType"`
+    Validity       string        `json:"validity"`
+    ProcessingDate datetime.Date `json:"processingDate"`
+    Status         string        `json:"status,omitempty" schema:"enum=documentsPending|submitted|approved" doc:"Defaults to documentsPending"`
+}
+
+// CurrentStatus is the application's status, documentsPending until it is
+// given
+func (v Visa) CurrentStatus() string {
+    if v.Status == "" {
+        return VisaDocumentsPending
+    }
+    return v.Status
+}
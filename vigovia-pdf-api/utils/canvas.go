@@ -0,0 +1,178 @@
+package utils
+
+import (
+    "io"
+    "vigovia-pdf-api/barcode"
+    "vigovia-pdf-api/bidi"
+    "github.com/jung-kurt/gofpdf"
+)
+
+// canvas draws on a document in left-to-right coordinates. For right-to-left
+// locales every shape is mirrored around the middle of the page and text is
+// right-aligned at the mirrored position, so generators lay a page out once
+// and get the timeline on the right, reversed tables and a swapped footer for
+// Arabic and Hebrew. Text is shaped and put in display order in both
+// directions, so Arabic names print correctly in English documents too.
+//
+// The gofpdf document isn't embedded: canvas only offers drawing that is
+// mirrored and settings that don't depend on the direction, so a generator
+// can't place anything that would skip the mirroring.
+type canvas struct {
+    pdf *gofpdf.Fpdf
+    rtl bool
+    // x and y are where the next text is drawn: set by SetXY and moved on by
+    // Cell, in left-to-right coordinates
+    x, y float64
+}
+
+// mirror returns where a box starting at x with width w is drawn
+func (c *canvas) mirror(x, w float64) float64 {
+    if !c.rtl {
+        return x
+    }
+    return a4Width - x - w
+}
+
+// display shapes text and puts it in the order it is drawn in
+func (c *canvas) display(text string) string {
+    return bidi.Visual(bidi.Shape(text), c.rtl)
+}
+
+func (c *canvas) SetXY(x, y float64) {
+    c.x, c.y = x, y
+}
+
+// Cell draws text starting at the current position, or ending there when the
+// layout is mirrored, and moves the position past the cell: w wide, or as
+// wide as the text when w is 0
+func (c *canvas) Cell(w, h float64, text string) {
+    text = c.display(text)
+    width := c.pdf.GetStringWidth(text)
+    c.pdf.SetXY(c.mirror(c.x, width), c.y)
+    c.pdf.Cell(w, h, text)
+    if w > 0 {
+        width = w
+    }
+    c.x += width
+}
+
+func (c *canvas) GetStringWidth(text string) float64 {
+    return c.pdf.GetStringWidth(c.display(text))
+}
+
+func (c *canvas) Rect(x, y, w, h float64, style string) {
+    c.pdf.Rect(c.mirror(x, w), y, w, h, style)
+}
+
+// RoundedRect mirrors the box and which of its corners are rounded; corners
+// are numbered clockwise from the top left as in gofpdf
+func (c *canvas) RoundedRect(x, y, w, h, r float64, corners, style string) {
+    if c.rtl {
+        swapped := []byte(corners)
+        for i, corner := range swapped {
+            swapped[i] = map[byte]byte{'1': '2', '2': '1', '3': '4', '4': '3'}[corner]
+        }
+        corners = string(swapped)
+    }
+    c.pdf.RoundedRect(c.mirror(x, w), y, w, h, r, corners, style)
+}
+
+func (c *canvas) Circle(x, y, r float64, style string) {
+    c.pdf.Circle(c.mirror(x, 0), y, r, style)
+}
+
+func (c *canvas) Line(x1, y1, x2, y2 float64) {
+    c.pdf.Line(c.mirror(x1, 0), y1, c.mirror(x2, 0), y2)
+}
+
+// Bars draws a barcode from the widths of its bars and spaces in modules,
+// starting with a bar. On mirrored pages the code moves to the mirrored
+// position but still reads left to right, so it scans.
+func (c *canvas) Bars(x, y, module, h float64, widths []int) {
+    total := 0
+    for _, w := range widths {
+        total += w
+    }
+    left := c.mirror(x, float64(total)*module)
+    for i, w := range widths {
+        if i%2 == 0 {
+            c.pdf.Rect(left, y, float64(w)*module, h, "F")
+        }
+        left += float64(w) * module
+    }
+}
+
+// QRCode draws a QR code size points square, its quiet zone included, on a
+// white panel. Like barcodes it is moved but never flipped on mirrored pages.
+func (c *canvas) QRCode(x, y, size float64, code *barcode.QR) {
+    module := size / float64(code.Size+2*barcode.QRQuietZone)
+    left := c.mirror(x, size) + barcode.QRQuietZone*module
+    top := y + barcode.QRQuietZone*module
+    c.SetFillColor(255, 255, 255)
+    c.pdf.Rect(c.mirror(x, size), y, size, size, "F")
+    c.SetFillColor(0, 0, 0)
+    for row := 0; row < code.Size; row++ {
+        // Dark runs are drawn as one rectangle to keep the document small
+        for col := 0; col < code.Size; {
+            if !code.Dark(col, row) {
+                col++
+                continue
+            }
+            start := col
+            for col < code.Size && code.Dark(col, row) {
+                col++
+            }
+            c.pdf.Rect(left+float64(start)*module, top+float64(row)*module, float64(col-start)*module, module, "F")
+        }
+    }
+}
+
+// Settings, pages and output, which are the same in both directions
+
+func (c *canvas) SetFont(family, style string, size float64) {
+    c.pdf.SetFont(family, style, size)
+}
+
+func (c *canvas) SetTextColor(r, g, b int) {
+    c.pdf.SetTextColor(r, g, b)
+}
+
+func (c *canvas) SetFillColor(r, g, b int) {
+    c.pdf.SetFillColor(r, g, b)
+}
+
+func (c *canvas) SetDrawColor(r, g, b int) {
+    c.pdf.SetDrawColor(r, g, b)
+}
+
+func (c *canvas) SetLineWidth(width float64) {
+    c.pdf.SetLineWidth(width)
+}
+
+func (c *canvas) SetDashPattern(dashArray []float64, dashPhase float64) {
+    c.pdf.SetDashPattern(dashArray, dashPhase)
+}
+
+func (c *canvas) AddPage() {
+    c.pdf.AddPage()
+}
+
+func (c *canvas) SetPage(n int) {
+    c.pdf.SetPage(n)
+}
+
+func (c *canvas) PageNo() int {
+    return c.pdf.PageNo()
+}
+
+func (c *canvas) PageCount() int {
+    return c.pdf.PageCount()
+}
+
+func (c *canvas) Output(w io.Writer) error {
+    return c.pdf.Output(w)
+}
+
+func (c *canvas) Error() error {
+    return c.pdf.Error()
+}
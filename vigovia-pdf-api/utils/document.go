@@ -0,0 +1,144 @@
+package utils
+
+import (
+    "bytes"
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "vigovia-pdf-api/fonts"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/types"
+    "github.com/jung-kurt/gofpdf"
+)
+
+// A4 page size in points, the unit every generator works in
+const (
+    a4Width  = 595.0
+    a4Height = 842.0
+)
+
+// ErrUnsupportedScript is returned when a document's locale is written in a
+// script the embedded font can't print and no font is configured for it
+var ErrUnsupportedScript = errors.New("no font available for script")
+
+// newDocument returns an empty A4 document with the font for the locale's
+// script loaded, laid out right-to-left for locales written that way. All
+// document types start from here so they share fonts and page setup.
+func newDocument(opts Options) (*canvas, error) {
+    font, err := opts.font()
+    if err != nil {
+        return nil, err
+    }
+    pdf := gofpdf.New("P", "pt", "A4", "")
+    pdf.AddUTF8FontFromBytes(fontFamily, "", font)
+    pdf.SetFont(fontFamily, "", 12)
+    // Page breaks are handled by the generators; gofpdf's own would fire on the footer
+    pdf.SetAutoPageBreak(false, 0)
+    return &canvas{pdf: pdf, rtl: opts.locale().Dir == i18n.RTL}, pdf.Error()
+}
+
+// locale returns the catalogue labels are printed from, English by default
+func (o Options) locale() *i18n.Locale {
+    if o.Locale == nil {
+        return i18n.Default()
+    }
+    return o.Locale
+}
+
+// font picks a configured font for the locale's script, falling back to
+// the embedded one for the scripts it covers
+func (o Options) font() ([]byte, error) {
+    script := o.locale().Script
+    if font, ok := o.Fonts[script]; ok {
+        return font, nil
+    }
+    if !fonts.Covers(script) {
+        return nil, fmt.Errorf("%w %s: set locale.fonts.%s to a TrueType font that has it", ErrUnsupportedScript, script, script)
+    }
+    return fonts.Arial, nil
+}
+
+// drawFooters prints the company footer on every page of the document
+func drawFooters(pdf *canvas, branding types.Branding, loc *i18n.Locale) {
+    for i := 1; i <= pdf.PageCount(); i++ {
+        pdf.SetPage(i)
+
+        footerY := a4Height - 40
+        pdf.SetLineWidth(0.5)
+        pdf.SetDrawColor(200, 200, 200)
+        pdf.Line(20, footerY-5, a4Width-20, footerY-5)
+
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetTextColor(100, 100, 100)
+        // Left side company info
+        pdf.SetXY(20, footerY)
+        pdf.Cell(0, 0, branding.CompanyName)
+        for i, line := range branding.AddressLines {
+            pdf.SetXY(20, footerY+4*float64(i+1))
+            pdf.Cell(0, 0, line)
+        }
+
+        // Center contact info
+        pdf.SetXY(a4Width/2-30, footerY)
+        pdf.Cell(0, 0, loc.T("footer.phone", branding.Phone))
+        pdf.SetXY(a4Width/2-30, footerY+4)
+        pdf.Cell(0, 0, loc.T("footer.email", branding.Email))
+
+        // Right side logo
+        pdf.SetFont(fontFamily, "", 12)
+        pdf.SetTextColor(84, 28, 156)
+        pdf.SetXY(a4Width-50, footerY)
+        pdf.Cell(0, 0, "vigovia")
+        pdf.SetFont(fontFamily, "", 6)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(a4Width-50, footerY+4)
+        pdf.Cell(0, 0, branding.Tagline)
+    }
+}
+
+// sectionTitle prints a heading with its second word in the accent colour,
+// e.g. "Payment Plan"
+func sectionTitle(pdf *canvas, x, y float64, first, second string) {
+    pdf.SetFont(fontFamily, "", 14)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(x, y)
+    pdf.Cell(0, 0, first+" ")
+    pdf.SetTextColor(147, 51, 234)
+    pdf.SetXY(x+pdf.GetStringWidth(first+" "), y)
+    pdf.Cell(0, 0, second)
+}
+
+// localTitle prints a translated section title, colouring its last word as
+// sectionTitle does
+func localTitle(pdf *canvas, x, y float64, title string) {
+    i := strings.LastIndex(title, " ")
+    if i < 0 {
+        sectionTitle(pdf, x, y, "", title)
+        return
+    }
+    sectionTitle(pdf, x, y, title[:i], title[i+1:])
+}
+
+// writeDocument adds the footers, writes the document and reports the
+// result to the observer
+func writeDocument(ctx context.Context, pdf *canvas, opts Options) ([]byte, error) {
+    endSection := opts.startSection("footer")
+    drawFooters(pdf, opts.Branding, opts.locale())
+    endSection()
+
+    endSection = opts.startSection("output")
+    var buf bytes.Buffer
+    err := pdf.Output(&buf)
+    endSection()
+    if err != nil {
+        logging.FromContext(ctx).Error("Error writing PDF", "error", err)
+        return nil, err
+    }
+
+    if opts.Observer != nil {
+        opts.Observer.Rendered(pdf.PageCount(), buf.Len())
+    }
+    return buf.Bytes(), nil
+}
@@ -0,0 +1,217 @@
+package utils
+
+import (
+    "fmt"
+    "strings"
+    "time"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/iata"
+    "vigovia-pdf-api/timeline"
+    "vigovia-pdf-api/types"
+)
+
+const (
+    cardHeight = 40.0
+    // stubX is where the tear-off stub with the PNR and baggage starts
+    stubX = 455.0
+    // pathLeft and pathRight are the ends of the flight path between the
+    // airports
+    pathLeft  = 160.0
+    pathRight = 320.0
+    // airportWidth is the widest an airport name is printed
+    airportWidth = 120.0
+)
+
+// flightCard draws a segment as a boarding pass: the airline and date across
+// the top, the airports with their times and terminals, the duration and
+// cabin between them, and a stub with the PNR and baggage allowance. It
+// returns the height used. Times without a zone are read in their airport's
+// zone, or zone for airports the reference data lacks.
+func flightCard(pdf *canvas, loc *i18n.Locale, y float64, flight types.Flight, zone *time.Location) float64 {
+    right := a4Width - 20
+    pdf.SetDrawColor(84, 28, 156)
+    pdf.SetLineWidth(0.3)
+    pdf.SetFillColor(248, 240, 255)
+    pdf.RoundedRect(20, y, right-20, cardHeight, 3, "1234", "FD")
+    pdf.SetFillColor(84, 28, 156)
+    pdf.RoundedRect(20, y, right-20, 9, 3, "12", "F")
+
+    pdf.SetTextColor(255, 255, 255)
+    pdf.SetFont(fontFamily, "", 9)
+    pdf.SetXY(25, y+4.5)
+    pdf.Cell(0, 0, strings.Join(nonEmpty(iata.AirlineName(flight.Airline), flight.FlightNumber), " · "))
+    if date := loc.FormatDate(timeline.FlightDate(flight)); date != "" {
+        pdf.SetXY(right-5-pdf.GetStringWidth(date), y+4.5)
+        pdf.Cell(0, 0, date)
+    }
+
+    dep, arr := timeline.Times(flight, zone)
+    arrival := ""
+    if !arr.IsZero() {
+        arrival = arr.Clock()
+        if !dep.IsZero() {
+            if days := dep.Date().DaysUntil(arr.Date()); days != 0 {
+                arrival += fmt.Sprintf(" %+d", days)
+            }
+        }
+    }
+    departure := ""
+    if !dep.IsZero() {
+        departure = dep.Clock()
+    }
+    airportBlock(pdf, loc, 30, y, flight.From, departure, flight.DepartureTerminal, false)
+    airportBlock(pdf, loc, stubX-15, y, flight.To, arrival, flight.ArrivalTerminal, true)
+
+    // Flight path with the duration above it and the cabin below
+    pdf.SetDrawColor(160, 130, 200)
+    pdf.Line(pathLeft, y+19, pathRight, y+19)
+    pdf.SetFillColor(84, 28, 156)
+    pdf.Circle(pathLeft, y+19, 1.2, "F")
+    pdf.Circle(pathRight, y+19, 1.2, "F")
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(84, 28, 156)
+    if d, err := arr.Sub(dep); err == nil && !dep.IsZero() && !arr.IsZero() && d > 0 {
+        centred(pdf, (pathLeft+pathRight)/2, y+15, datetime.FormatDuration(d))
+    }
+    if flight.Cabin != "" {
+        pdf.SetTextColor(100, 100, 100)
+        centred(pdf, (pathLeft+pathRight)/2, y+24, loc.T("cabin."+flight.Cabin))
+    }
+
+    // Tear-off stub
+    pdf.SetDrawColor(160, 130, 200)
+    pdf.SetDashPattern([]float64{1, 1}, 0)
+    pdf.Line(stubX, y+11, stubX, y+cardHeight-2)
+    pdf.SetDashPattern([]float64{}, 0)
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(stubX+5, y+14)
+    pdf.Cell(0, 0, loc.T("flights.pnr"))
+    pdf.SetFont(fontFamily, "", 12)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(stubX+5, y+20)
+    pnr := strings.ToUpper(flight.PNR)
+    if pnr == "" {
+        pnr = "-"
+    }
+    pdf.Cell(0, 0, pnr)
+    if lines := baggageLines(loc, flight.Baggage); len(lines) > 0 {
+        pdf.SetFont(fontFamily, "", 7)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(stubX+5, y+27)
+        pdf.Cell(0, 0, loc.T("flights.baggage"))
+        pdf.SetTextColor(0, 0, 0)
+        for i, line := range lines {
+            pdf.SetXY(stubX+5, y+31+float64(i)*4)
+            pdf.Cell(0, 0, line)
+        }
+    }
+    pdf.SetDrawColor(0, 0, 0)
+    pdf.SetLineWidth(0.2)
+    return cardHeight + 4
+}
+
+// airportBlock writes an airport with its local time and terminal below,
+// starting at x or, with alignRight, ending there. Known airports show their
+// code large with the city under it; other names are shrunk to fit.
+func airportBlock(pdf *canvas, loc *i18n.Locale, x, y float64, airport, clock, terminal string, alignRight bool) {
+    at := func(text string) float64 {
+        if alignRight {
+            return x - pdf.GetStringWidth(text)
+        }
+        return x
+    }
+    city := ""
+    if a, ok := iata.FindAirport(airport); ok {
+        airport, city = a.Code, a.City
+    }
+    pdf.SetTextColor(0, 0, 0)
+    size := 18.0
+    pdf.SetFont(fontFamily, "", size)
+    for size > 8 && pdf.GetStringWidth(airport) > airportWidth {
+        size--
+        pdf.SetFont(fontFamily, "", size)
+    }
+    pdf.SetXY(at(airport), y+18)
+    pdf.Cell(0, 0, airport)
+    if city != "" {
+        pdf.SetFont(fontFamily, "", 7)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(at(city), y+23.5)
+        pdf.Cell(0, 0, city)
+        pdf.SetTextColor(0, 0, 0)
+    }
+    if clock != "" {
+        pdf.SetFont(fontFamily, "", 10)
+        pdf.SetXY(at(clock), y+29)
+        pdf.Cell(0, 0, clock)
+    }
+    if terminal != "" {
+        text := loc.T("flights.terminal", terminal)
+        pdf.SetFont(fontFamily, "", 7)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(at(text), y+34.5)
+        pdf.Cell(0, 0, text)
+    }
+}
+
+// layoverStrip draws the wait between two connecting segments and returns
+// the height used
+func layoverStrip(pdf *canvas, loc *i18n.Locale, y float64, l timeline.Layover) float64 {
+    airport := iata.AirportName(l.Airport)
+    text := loc.T("flights.connection", airport)
+    if l.Known && l.Duration > 0 {
+        text = loc.T("flights.layover", airport, datetime.FormatDuration(l.Duration))
+    }
+    pdf.SetDrawColor(160, 130, 200)
+    pdf.SetDashPattern([]float64{1, 1}, 0)
+    pdf.Line(40, y-4, 40, y+6)
+    pdf.SetDashPattern([]float64{}, 0)
+    pdf.SetDrawColor(0, 0, 0)
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(84, 28, 156)
+    pdf.SetXY(45, y+1)
+    pdf.Cell(0, 0, text)
+    return 10
+}
+
+// baggageLines describes a baggage allowance, e.g. "Checked: 2 × 23 kg"
+func baggageLines(loc *i18n.Locale, b *types.Baggage) []string {
+    if b == nil {
+        return nil
+    }
+    var lines []string
+    checked := ""
+    switch {
+    case b.CheckedPieces > 0 && b.CheckedKg > 0:
+        checked = fmt.Sprintf("%d × %s", b.CheckedPieces, loc.T("flights.kg", b.CheckedKg))
+    case b.CheckedKg > 0:
+        checked = loc.T("flights.kg", b.CheckedKg)
+    case b.CheckedPieces > 0:
+        checked = loc.N("flights.pieces", b.CheckedPieces)
+    }
+    if checked != "" {
+        lines = append(lines, loc.T("flights.checked", checked))
+    }
+    if b.CabinKg > 0 {
+        lines = append(lines, loc.T("flights.cabinBaggage", loc.T("flights.kg", b.CabinKg)))
+    }
+    return lines
+}
+
+// centred writes text centred on x
+func centred(pdf *canvas, x, y float64, text string) {
+    pdf.SetXY(x-pdf.GetStringWidth(text)/2, y)
+    pdf.Cell(0, 0, text)
+}
+
+func nonEmpty(values ...string) []string {
+    var out []string
+    for _, v := range values {
+        if v = strings.TrimSpace(v); v != "" {
+            out = append(out, v)
+        }
+    }
+    return out
+}
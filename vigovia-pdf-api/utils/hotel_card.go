@@ -0,0 +1,136 @@
+package utils
+
+import (
+    "strings"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/types"
+)
+
+const (
+    hotelCardHeight = 44.0
+    // confirmationX is where the stub with the hotel's confirmation starts
+    confirmationX = 455.0
+)
+
+// hotelCard draws a stay: the hotel and city across the top, its address,
+// phone and room below, the dates and nights in the middle and the
+// confirmation number on a stub. It returns the height used.
+func hotelCard(pdf *canvas, loc *i18n.Locale, y float64, hotel types.Hotel) float64 {
+    right := a4Width - 20
+    pdf.SetDrawColor(84, 28, 156)
+    pdf.SetLineWidth(0.3)
+    pdf.SetFillColor(248, 240, 255)
+    pdf.RoundedRect(20, y, right-20, hotelCardHeight, 3, "1234", "FD")
+    pdf.SetFillColor(84, 28, 156)
+    pdf.RoundedRect(20, y, right-20, 9, 3, "12", "F")
+
+    pdf.SetTextColor(255, 255, 255)
+    pdf.SetFont(fontFamily, "", 9)
+    pdf.SetXY(25, y+4.5)
+    pdf.Cell(0, 0, fitText(pdf, hotel.Name, 300))
+    if hotel.City != "" {
+        pdf.SetXY(right-5-pdf.GetStringWidth(hotel.City), y+4.5)
+        pdf.Cell(0, 0, hotel.City)
+    }
+
+    // Where the hotel is and what was booked
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    lineY := y + 16
+    for _, line := range nonEmpty(hotel.Address, phoneLine(loc, hotel.Phone)) {
+        pdf.SetXY(25, lineY)
+        pdf.Cell(0, 0, fitText(pdf, line, 250))
+        lineY += 6
+    }
+    if room := roomLine(loc, hotel); room != "" {
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetTextColor(0, 0, 0)
+        pdf.SetXY(25, y+36)
+        pdf.Cell(0, 0, fitText(pdf, room, 250))
+    }
+
+    // Dates with the nights between them
+    for i, stay := range []struct {
+        label string
+        date  string
+    }{{loc.T("hotels.checkIn"), loc.FormatDate(hotel.CheckIn)}, {loc.T("hotels.checkOut"), loc.FormatDate(hotel.CheckOut)}} {
+        x := 290 + float64(i)*80
+        pdf.SetFont(fontFamily, "", 7)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(x, y+16)
+        pdf.Cell(0, 0, stay.label)
+        pdf.SetFont(fontFamily, "", 10)
+        pdf.SetTextColor(0, 0, 0)
+        pdf.SetXY(x, y+23)
+        pdf.Cell(0, 0, orDash(stay.date))
+    }
+    if nights := hotel.Nights; nights > 0 {
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetTextColor(84, 28, 156)
+        pdf.SetXY(290, y+34)
+        pdf.Cell(0, 0, loc.N("trip.nights", nights))
+    }
+
+    // Confirmation stub
+    pdf.SetDrawColor(160, 130, 200)
+    pdf.SetDashPattern([]float64{1, 1}, 0)
+    pdf.Line(confirmationX, y+11, confirmationX, y+hotelCardHeight-2)
+    pdf.SetDashPattern([]float64{}, 0)
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(confirmationX+5, y+16)
+    pdf.Cell(0, 0, loc.T("hotels.confirmation"))
+    pdf.SetFont(fontFamily, "", 11)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(confirmationX+5, y+23)
+    pdf.Cell(0, 0, fitText(pdf, orDash(hotel.ConfirmationNumber), right-confirmationX-10))
+
+    pdf.SetDrawColor(0, 0, 0)
+    pdf.SetLineWidth(0.2)
+    return hotelCardHeight + 5
+}
+
+// roomLine describes what was booked, e.g. "Deluxe Double · 2 rooms ·
+// Breakfast included"
+func roomLine(loc *i18n.Locale, hotel types.Hotel) string {
+    parts := nonEmpty(hotel.RoomType)
+    if hotel.Rooms > 0 {
+        parts = append(parts, loc.N("hotels.rooms", hotel.Rooms))
+    }
+    if hotel.MealPlan != "" {
+        parts = append(parts, mealPlanLabel(loc, hotel.MealPlan))
+    }
+    return strings.Join(parts, " · ")
+}
+
+// mealPlanLabel names a meal plan with its code, e.g. "Breakfast included (CP)"
+func mealPlanLabel(loc *i18n.Locale, plan string) string {
+    return loc.T("hotels.mealPlan", loc.T("mealPlan."+plan), plan)
+}
+
+func phoneLine(loc *i18n.Locale, phone string) string {
+    if phone = strings.TrimSpace(phone); phone == "" {
+        return ""
+    }
+    return loc.T("footer.phone", phone)
+}
+
+// fitText shortens text with an ellipsis until it is at most width wide in
+// the current font
+func fitText(pdf *canvas, text string, width float64) string {
+    if pdf.GetStringWidth(text) <= width {
+        return text
+    }
+    runes := []rune(text)
+    for len(runes) > 0 && pdf.GetStringWidth(string(runes)+"…") > width {
+        runes = runes[:len(runes)-1]
+    }
+    return strings.TrimSpace(string(runes)) + "…"
+}
+
+func orDash(s string) string {
+    if strings.TrimSpace(s) == "" {
+        return "-"
+    }
+    return s
+}
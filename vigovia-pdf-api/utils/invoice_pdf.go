@@ -0,0 +1,365 @@
+package utils
+
+import (
+    "context"
+    "fmt"
+    "strings"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/invoice"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/tax"
+    "vigovia-pdf-api/types"
+)
+
+// GenerateInvoice renders a GST tax invoice
+func GenerateInvoice(ctx context.Context, inv *invoice.Invoice, opts Options) ([]byte, error) {
+    logger := logging.FromContext(ctx)
+    pdf, err := newDocument(opts)
+    if err != nil {
+        return nil, err
+    }
+    pdf.AddPage()
+    pageWidth := a4Width
+
+    endSection := opts.startSection("invoice_header")
+    yPos := documentHeader(pdf, opts.Branding, "TAX INVOICE")
+    yPos = documentMeta(pdf, yPos, [][2]string{
+        {"Invoice No.", inv.Number},
+        {"Invoice Date", documentDay(inv.Date)},
+        {"Place of Supply", inv.PlaceOfSupply},
+    })
+
+    // Supplier and recipient side by side
+    colWidth := (pageWidth - 50) / 2
+    supplierHeight := partyBox(pdf, 20, yPos, colWidth, "Supplier", inv.Seller)
+    recipientHeight := partyBox(pdf, 30+colWidth, yPos, colWidth, "Bill To", inv.Buyer)
+    yPos += max(supplierHeight, recipientHeight) + 15
+    endSection()
+
+    endSection = opts.startSection("invoice_items")
+    colX := []float64{25, 45, 330, 390, 460}
+    pdf.SetFillColor(84, 28, 156)
+    pdf.Rect(20, yPos, pageWidth-40, 12, "F")
+    pdf.SetTextColor(255, 255, 255)
+    pdf.SetFont(fontFamily, "", 8)
+    for i, header := range []string{"#", "Description", "SAC", "Pax", "Taxable Value"} {
+        pdf.SetXY(colX[i], yPos+7)
+        pdf.Cell(0, 0, header)
+    }
+    yPos += 12
+
+    for i, item := range inv.Items {
+        if i%2 == 0 {
+            pdf.SetFillColor(248, 240, 255)
+        } else {
+            pdf.SetFillColor(255, 255, 255)
+        }
+        pdf.Rect(20, yPos, pageWidth-40, 12, "F")
+        pdf.SetTextColor(0, 0, 0)
+        pdf.SetFont(fontFamily, "", 8)
+        for j, value := range []string{fmt.Sprintf("%d", i+1), item.Description, item.SAC, fmt.Sprintf("%d", item.Quantity), money.Format(item.Taxable, i18n.DefaultLocale)} {
+            pdf.SetXY(colX[j], yPos+7)
+            pdf.Cell(0, 0, value)
+        }
+        yPos += 12
+    }
+    yPos += 8
+    endSection()
+
+    endSection = opts.startSection("invoice_taxes")
+    // Totals: GST makes up the invoice value, TCS is collected on top of it
+    taxes := inv.Tax
+    totalRow := func(label, value string, bold bool) {
+        if bold {
+            pdf.SetFillColor(220, 200, 255)
+        } else {
+            pdf.SetFillColor(240, 230, 255)
+        }
+        pdf.Rect(pageWidth/2, yPos, pageWidth/2-20, 12, "F")
+        pdf.SetTextColor(0, 0, 0)
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetXY(pageWidth/2+5, yPos+7)
+        pdf.Cell(0, 0, label)
+        pdf.SetXY(colX[4], yPos+7)
+        pdf.Cell(0, 0, value)
+        yPos += 13
+    }
+    totalRow("Taxable Value", money.Format(taxes.Package, i18n.DefaultLocale), false)
+    for _, line := range taxes.Lines {
+        if line.Kind == tax.KindGST {
+            totalRow(line.Label.String(), money.Format(line.Amount, i18n.DefaultLocale), false)
+        }
+    }
+    invoiceValue, _ := taxes.Package.Add(taxes.GST)
+    totalRow("Invoice Value", money.Format(invoiceValue, i18n.DefaultLocale), true)
+    if !taxes.TCS.IsZero() {
+        for _, line := range taxes.Lines {
+            if line.Kind == tax.KindTCS {
+                totalRow(line.Label.String()+" u/s 206C(1G)", money.Format(line.Amount, i18n.DefaultLocale), false)
+            }
+        }
+        totalRow("Amount Payable", money.Format(taxes.Payable, i18n.DefaultLocale), true)
+    }
+    yPos += 5
+
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    if inv.Buyer.GSTIN == "" {
+        pdf.SetXY(20, yPos)
+        pdf.Cell(0, 0, "Supply to an unregistered recipient (B2C).")
+        yPos += 9
+    }
+    for _, note := range taxes.Notes {
+        pdf.SetXY(20, yPos)
+        pdf.Cell(0, 0, "Note: "+note.String())
+        yPos += 9
+    }
+    yPos += 10
+    endSection()
+
+    endSection = opts.startSection("invoice_schedule")
+    if len(inv.Installments) > 0 && yPos < a4Height-200 {
+        sectionTitle(pdf, 20, yPos, "Payment", "Schedule")
+        yPos += 12
+        pdf.SetFillColor(84, 28, 156)
+        pdf.Rect(20, yPos, pageWidth-40, 10, "F")
+        pdf.SetTextColor(255, 255, 255)
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetXY(25, yPos+6)
+        pdf.Cell(0, 0, "Installment")
+        pdf.SetXY(200, yPos+6)
+        pdf.Cell(0, 0, "Amount")
+        pdf.SetXY(330, yPos+6)
+        pdf.Cell(0, 0, "Due Date")
+        yPos += 10
+
+        for i, installment := range inv.Installments {
+            if yPos > a4Height-140 {
+                logger.Warn("Payment schedule truncated on invoice", "installments", len(inv.Installments), "shown", i)
+                break
+            }
+            if i%2 == 0 {
+                pdf.SetFillColor(248, 240, 255)
+            } else {
+                pdf.SetFillColor(255, 255, 255)
+            }
+            pdf.Rect(20, yPos, pageWidth-40, 10, "F")
+            pdf.SetTextColor(0, 0, 0)
+            pdf.SetFont(fontFamily, "", 7)
+            pdf.SetXY(25, yPos+6)
+            pdf.Cell(0, 0, installment.Name)
+            pdf.SetXY(200, yPos+6)
+            pdf.Cell(0, 0, money.Format(installment.Amount, i18n.DefaultLocale))
+            pdf.SetXY(330, yPos+6)
+            pdf.Cell(0, 0, documentDay(installment.DueDate))
+            yPos += 10
+        }
+        yPos += 15
+    }
+    endSection()
+
+    signatureBlock(pdf, yPos, inv.Seller.Name,
+        "Certified that the particulars given above are true and correct. Tax is not payable under reverse charge.")
+
+    out, err := writeDocument(ctx, pdf, opts)
+    if err != nil {
+        return nil, err
+    }
+    logger.Debug("Invoice rendered", "number", inv.Number, "bytes", len(out))
+    return out, nil
+}
+
+// GenerateReceipt renders a payment receipt for one installment
+func GenerateReceipt(ctx context.Context, receipt *invoice.Receipt, opts Options) ([]byte, error) {
+    logger := logging.FromContext(ctx)
+    pdf, err := newDocument(opts)
+    if err != nil {
+        return nil, err
+    }
+    pdf.AddPage()
+    pageWidth := a4Width
+
+    endSection := opts.startSection("receipt")
+    yPos := documentHeader(pdf, opts.Branding, "PAYMENT RECEIPT")
+    meta := [][2]string{
+        {"Receipt No.", receipt.Number},
+        {"Receipt Date", documentDay(receipt.Date)},
+    }
+    if receipt.InvoiceNumber != "" {
+        meta = append(meta, [2]string{"Against Invoice", receipt.InvoiceNumber})
+    }
+    yPos = documentMeta(pdf, yPos, meta)
+
+    pdf.SetFont(fontFamily, "", 10)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(20, yPos)
+    pdf.Cell(0, 0, fmt.Sprintf("Received with thanks from %s the sum of %s.", receipt.ReceivedFrom, money.Format(receipt.Amount, i18n.DefaultLocale)))
+    yPos += 20
+
+    rows := [][2]string{
+        {"Trip", receipt.Trip},
+        {"Installment", fmt.Sprintf("%s (%d of %d)", receipt.Installment.Name, receipt.Position, receipt.Count)},
+        {"Due Date", documentDay(receipt.Installment.DueDate)},
+        {"Installment Amount", money.Format(receipt.Installment.Amount, i18n.DefaultLocale)},
+        {"Amount Received", money.Format(receipt.Amount, i18n.DefaultLocale)},
+    }
+    if receipt.Amount.MinorUnits < receipt.Installment.Amount.MinorUnits {
+        outstanding, _ := receipt.Installment.Amount.Sub(receipt.Amount)
+        rows = append(rows, [2]string{"Outstanding on Installment", money.Format(outstanding, i18n.DefaultLocale)})
+    }
+    if receipt.Method != "" {
+        rows = append(rows, [2]string{"Payment Method", paymentMethodLabel(receipt.Method)})
+    }
+    if receipt.Reference != "" {
+        rows = append(rows, [2]string{"Reference", receipt.Reference})
+    }
+    for _, row := range rows {
+        pdf.SetFillColor(240, 230, 255)
+        pdf.Rect(20, yPos, pageWidth-40, 12, "F")
+        pdf.SetTextColor(0, 0, 0)
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetXY(25, yPos+7)
+        pdf.Cell(0, 0, row[0])
+        pdf.SetXY(160, yPos+7)
+        pdf.Cell(0, 0, row[1])
+        yPos += 15
+    }
+    yPos += 15
+    endSection()
+
+    signatureBlock(pdf, yPos, receipt.Seller.Name,
+        "This receipt is subject to realisation of the payment.")
+
+    out, err := writeDocument(ctx, pdf, opts)
+    if err != nil {
+        return nil, err
+    }
+    logger.Debug("Receipt rendered", "number", receipt.Number, "bytes", len(out))
+    return out, nil
+}
+
+// documentHeader prints the brand on the left and the document title on the
+// right, returning the y position below them
+func documentHeader(pdf *canvas, branding types.Branding, title string) float64 {
+    yPos := 30.0
+    pdf.SetFont(fontFamily, "", 20)
+    pdf.SetTextColor(84, 28, 156)
+    pdf.SetXY(20, yPos)
+    pdf.Cell(0, 0, "vigovia")
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(20, yPos+12)
+    pdf.Cell(0, 0, branding.Tagline)
+
+    pdf.SetFont(fontFamily, "", 16)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(a4Width-20-pdf.GetStringWidth(title), yPos)
+    pdf.Cell(0, 0, title)
+
+    yPos += 25
+    pdf.SetLineWidth(1)
+    pdf.SetDrawColor(84, 28, 156)
+    pdf.Line(20, yPos, a4Width-20, yPos)
+    return yPos + 15
+}
+
+// documentMeta prints label/value pairs such as the document number and date
+func documentMeta(pdf *canvas, yPos float64, fields [][2]string) float64 {
+    for _, field := range fields {
+        pdf.SetFont(fontFamily, "", 8)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(20, yPos)
+        pdf.Cell(0, 0, field[0])
+        pdf.SetFont(fontFamily, "", 9)
+        pdf.SetTextColor(0, 0, 0)
+        pdf.SetXY(110, yPos)
+        pdf.Cell(0, 0, field[1])
+        yPos += 12
+    }
+    return yPos + 8
+}
+
+// partyBox prints a supplier or recipient block and returns its height
+func partyBox(pdf *canvas, x, y, width float64, title string, party types.Party) float64 {
+    lines := append([]string{}, party.AddressLines...)
+    if party.State != "" {
+        lines = append(lines, "State: "+party.State)
+    }
+    if party.GSTIN != "" {
+        lines = append(lines, "GSTIN: "+party.GSTIN)
+    } else {
+        lines = append(lines, "GSTIN: Unregistered")
+    }
+    if party.PAN != "" {
+        lines = append(lines, "PAN: "+party.PAN)
+    }
+    contact := strings.TrimSpace(strings.Join([]string{party.Phone, party.Email}, "  "))
+    if contact != "" {
+        lines = append(lines, contact)
+    }
+
+    height := 30 + 10*float64(len(lines))
+    pdf.SetFillColor(245, 245, 245)
+    pdf.Rect(x, y, width, height, "F")
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(147, 51, 234)
+    pdf.SetXY(x+8, y+9)
+    pdf.Cell(0, 0, strings.ToUpper(title))
+    pdf.SetFont(fontFamily, "", 10)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(x+8, y+20)
+    pdf.Cell(0, 0, party.Name)
+    pdf.SetFont(fontFamily, "", 8)
+    for i, line := range lines {
+        pdf.SetXY(x+8, y+31+10*float64(i))
+        pdf.Cell(0, 0, line)
+    }
+    return height
+}
+
+// signatureBlock prints the declaration and the authorised signatory line
+func signatureBlock(pdf *canvas, yPos float64, company, declaration string) {
+    yPos = min(yPos, a4Height-120)
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(20, yPos)
+    pdf.Cell(0, 0, declaration)
+
+    pdf.SetFont(fontFamily, "", 9)
+    pdf.SetTextColor(0, 0, 0)
+    label := "For " + company
+    pdf.SetXY(a4Width-20-pdf.GetStringWidth(label), yPos+15)
+    pdf.Cell(0, 0, label)
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetXY(a4Width-20-pdf.GetStringWidth("Authorised Signatory"), yPos+45)
+    pdf.Cell(0, 0, "Authorised Signatory")
+}
+
+// documentDay writes a date on invoices and receipts, which are in English
+func documentDay(d datetime.Date) string {
+    if d.IsZero() {
+        return ""
+    }
+    return d.Format("2 Jan 2006")
+}
+
+// Helper function to print a payment method the way customers know it
+func paymentMethodLabel(method string) string {
+    switch method {
+    case types.PaymentUPI:
+        return "UPI"
+    case types.PaymentCard:
+        return "Card"
+    case types.PaymentNetBanking:
+        return "Net Banking"
+    case types.PaymentBankTransfer:
+        return "Bank Transfer"
+    case types.PaymentCash:
+        return "Cash"
+    case types.PaymentCheque:
+        return "Cheque"
+    }
+    return method
+}
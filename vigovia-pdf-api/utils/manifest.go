@@ -0,0 +1,83 @@
+package utils
+
+import (
+    "fmt"
+    "strings"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/timeline"
+    "vigovia-pdf-api/types"
+)
+
+const manifestRowHeight = 12.0
+
+// manifestColumns are where the manifest's columns start
+var manifestColumns = []float64{25, 40, 200, 265, 330, 410, 480}
+
+// manifestHeader draws the manifest's column headings and returns their height
+func manifestHeader(pdf *canvas, loc *i18n.Locale, y float64) float64 {
+    pdf.SetFillColor(84, 28, 156)
+    pdf.Rect(20, y, a4Width-40, 10, "F")
+    pdf.SetTextColor(255, 255, 255)
+    pdf.SetFont(fontFamily, "", 8)
+    for i, heading := range []string{"#", loc.T("travellers.name"), loc.T("travellers.category"), loc.T("travellers.nationality"),
+        loc.T("travellers.passport"), loc.T("travellers.expiry"), loc.T("travellers.meal")} {
+        pdf.SetXY(manifestColumns[i], y+6)
+        pdf.Cell(0, 0, heading)
+    }
+    return 10
+}
+
+// manifestRow draws one traveller with their passport number masked and its
+// expiry in red when it runs out too soon after the trip, and returns the
+// height used
+func manifestRow(pdf *canvas, loc *i18n.Locale, trip types.TripDetails, y float64, i int, traveller types.Traveller) float64 {
+    if i%2 == 0 {
+        pdf.SetFillColor(248, 240, 255)
+    } else {
+        pdf.SetFillColor(255, 255, 255)
+    }
+    pdf.Rect(20, y, a4Width-40, manifestRowHeight, "F")
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(0, 0, 0)
+
+    category := traveller.AgeCategory
+    if category == "" {
+        category = types.AgeAdult
+    }
+    meal := "-"
+    if traveller.MealPreference != "" {
+        meal = loc.T("meal." + traveller.MealPreference)
+    }
+    values := []string{
+        fmt.Sprintf("%d", i+1),
+        fitText(pdf, traveller.Name, manifestColumns[2]-manifestColumns[1]-5),
+        loc.T("age." + category),
+        orDash(traveller.Nationality),
+        orDash(maskNumber(traveller.PassportNumber)),
+        "",
+        meal,
+    }
+    for j, value := range values {
+        pdf.SetXY(manifestColumns[j], y+7)
+        pdf.Cell(0, 0, value)
+    }
+
+    if timeline.PassportExpiresSoon(trip, traveller.PassportExpiry) {
+        pdf.SetTextColor(200, 40, 40)
+    }
+    pdf.SetXY(manifestColumns[5], y+7)
+    pdf.Cell(0, 0, orDash(loc.FormatDate(traveller.PassportExpiry)))
+    pdf.SetTextColor(0, 0, 0)
+    return manifestRowHeight
+}
+
+// maskNumber hides all but the last four characters of a document number,
+// or all of a number too short to keep any
+func maskNumber(number string) string {
+    number = strings.TrimSpace(number)
+    keep := 4
+    if len(number) <= keep+2 {
+        keep = 0
+    }
+    return strings.Repeat("X", len(number)-keep) + number[len(number)-keep:]
+}
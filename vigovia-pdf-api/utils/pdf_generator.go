@@ -1,84 +1,145 @@
 package utils
 
 import (
-    "bytes"
+    "context"
     "fmt"
-    "log"
+    "strings"
+    "vigovia-pdf-api/barcode"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/payments"
+    "vigovia-pdf-api/pricing"
+    "vigovia-pdf-api/tax"
+    "vigovia-pdf-api/timeline"
     "vigovia-pdf-api/types"
-    "github.com/jung-kurt/gofpdf"
+    "vigovia-pdf-api/visa"
+    "vigovia-pdf-api/voucher"
 )
 
-func GeneratePDF(data types.ItineraryData) ([]byte, error) {
-    pdf := gofpdf.New("P", "pt", "A4", "")
-    pdf.SetFont("Helvetica", "", 12)
-    pageWidth, pageHeight := 595.0, 842.0
-    yPos := 20.0
+// fontFamily is the embedded UTF-8 font used for all text so currency
+// symbols and non-Latin names render correctly
+const fontFamily = "Arial"
 
-    // Helper function to check for page breaks
-    checkPageBreak := func(neededHeight float64) {
-        if yPos+neededHeight > pageHeight-50 {
-            pdf.AddPage()
-            yPos = 20.0
+// Observer is notified while a PDF is rendered, e.g. to record metrics.
+// StartSection returns a func that must be called when the section is done.
+type Observer interface {
+    StartSection(section string) func()
+    Rendered(pages, size int)
+}
+
+// Options controls how GeneratePDF renders an itinerary
+type Options struct {
+    Branding types.Branding
+    Observer Observer
+    // SecondaryCurrency, when set, prints converted amounts next to prices
+    // using Rates; a request's own rate table is layered over these.
+    SecondaryCurrency string
+    Rates             money.RateTable
+    // PaymentStatus, when set, adds Paid/Due/Overdue badges to the installments
+    PaymentStatus *payments.Summary
+    // Locale translates labels and formats dates; nil prints English
+    Locale *i18n.Locale
+    // Fonts are TrueType fonts by script for locales the embedded font can't print
+    Fonts map[string][]byte
+    // UPI, when it has an ID, adds a QR code to pay each unpaid rupee
+    // installment
+    UPI payments.UPIPayee
+}
+
+// MultiObserver fans render notifications out to several observers
+type MultiObserver []Observer
+
+func (m MultiObserver) StartSection(section string) func() {
+    ends := make([]func(), 0, len(m))
+    for _, o := range m {
+        ends = append(ends, o.StartSection(section))
+    }
+    return func() {
+        for i := len(ends) - 1; i >= 0; i-- {
+            ends[i]()
         }
     }
+}
 
-    // Company footer function
-    addFooter := func() {
-        footerY := pageHeight - 40
-        pdf.SetLineWidth(0.5)
-        pdf.SetDrawColor(200, 200, 200)
-        pdf.Line(20, footerY-5, pageWidth-20, footerY-5)
+func (m MultiObserver) Rendered(pages, size int) {
+    for _, o := range m {
+        o.Rendered(pages, size)
+    }
+}
 
-        pdf.SetFont("Helvetica", "", 8)
-        pdf.SetTextColor(100, 100, 100)
-        // Left side company info
-        pdf.SetXY(20, footerY)
-        pdf.Cell(0, 0, "Vigovia Tech Pvt. Ltd")
-        pdf.SetXY(20, footerY+4)
-        pdf.Cell(0, 0, "Registered Office: Hd-109 Cinnabar Hills,")
-        pdf.SetXY(20, footerY+8)
-        pdf.Cell(0, 0, "Links Business Park, Karnataka, India")
-
-        // Center contact info
-        pdf.SetXY(pageWidth/2-30, footerY)
-        pdf.Cell(0, 0, "Phone: +91-99X9999999")
-        pdf.SetXY(pageWidth/2-30, footerY+4)
-        pdf.Cell(0, 0, "Email: Contact@Vigovia.Com")
-
-        // Right side logo
-        pdf.SetFont("Helvetica", "", 12)
-        pdf.SetTextColor(84, 28, 156)
-        pdf.SetXY(pageWidth-50, footerY)
-        pdf.Cell(0, 0, "vigovia")
-        pdf.SetFont("Helvetica", "", 6)
-        pdf.SetTextColor(100, 100, 100)
-        pdf.SetXY(pageWidth-50, footerY+4)
-        pdf.Cell(0, 0, "PLAN.PACK.GO")
+func (o Options) startSection(section string) func() {
+    if o.Observer == nil {
+        return func() {}
     }
+    return o.Observer.StartSection(section)
+}
 
-    // Add footer to all pages at the end
-    addFooterToAllPages := func() {
-        pageCount := pdf.PageCount()
-        for i := 1; i <= pageCount; i++ {
-            pdf.SetPage(i)
-            addFooter()
+// DefaultOptions returns the options used when the caller has no configuration
+func DefaultOptions() Options {
+    return Options{
+        Branding: types.DefaultBranding(),
+    }
+}
+
+func GeneratePDF(ctx context.Context, data types.ItineraryData, opts Options) ([]byte, error) {
+    logger := logging.FromContext(ctx)
+    loc := opts.locale()
+
+    // Prices are printed in their own currency, optionally followed by the
+    // secondary currency the customer thinks in
+    secondary := opts.SecondaryCurrency
+    if data.SecondaryCurrency != "" {
+        secondary = data.SecondaryCurrency
+    }
+    rates := opts.Rates
+    if data.ExchangeRates != nil {
+        rates = rates.Merge(*data.ExchangeRates)
+    }
+    formatAmount := func(m money.Money) string {
+        formatted := money.Format(m, loc.Tag)
+        if secondary == "" || strings.EqualFold(secondary, m.Currency) {
+            return formatted
+        }
+        converted, err := rates.Convert(m, secondary)
+        if err != nil {
+            logger.Warn("Skipping secondary currency", "currency", secondary, "error", err)
+            return formatted
+        }
+        return loc.T("money.approx", formatted, money.Format(converted, loc.Tag))
+    }
+    pdf, err := newDocument(opts)
+    if err != nil {
+        return nil, err
+    }
+    pageWidth, pageHeight := a4Width, a4Height
+    yPos := 20.0
+
+    // Helper function to check for page breaks
+    checkPageBreak := func(neededHeight float64) {
+        if yPos+neededHeight > pageHeight-50 {
+            pdf.AddPage()
+            yPos = 20.0
         }
     }
 
     // Add first page
     pdf.AddPage()
 
+    endSection := opts.startSection("header")
+
     // Page 1: Header and Trip Overview
     // Company logo and branding
-    pdf.SetFont("Helvetica", "", 20)
+    pdf.SetFont(fontFamily, "", 20)
     pdf.SetTextColor(84, 28, 156)
     pdf.SetXY(pageWidth/2-20, yPos)
     pdf.Cell(0, 0, "vigovia")
     yPos += 6
-    pdf.SetFont("Helvetica", "", 8)
+    pdf.SetFont(fontFamily, "", 8)
     pdf.SetTextColor(100, 100, 100)
     pdf.SetXY(pageWidth/2-20, yPos)
-    pdf.Cell(0, 0, "PLAN.PACK.GO")
+    pdf.Cell(0, 0, opts.Branding.Tagline)
     yPos += 20
 
     // Main header with solid background (approximating gradient)
@@ -86,35 +147,56 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
     pdf.SetFillColor(84, 28, 156)
     pdf.Rect(40, yPos, pageWidth-80, headerHeight, "F")
     pdf.SetTextColor(255, 255, 255)
-    pdf.SetFont("Helvetica", "", 16)
+    pdf.SetFont(fontFamily, "", 16)
     pdf.SetXY(pageWidth/2-50, yPos+12)
-    pdf.Cell(0, 0, fmt.Sprintf("Hi, %s!", data.TripDetails.CustomerName))
-    pdf.SetFont("Helvetica", "", 14)
+    pdf.Cell(0, 0, loc.T("header.greeting", data.TripDetails.CustomerName))
+    pdf.SetFont(fontFamily, "", 14)
     pdf.SetXY(pageWidth/2-50, yPos+22)
-    pdf.Cell(0, 0, fmt.Sprintf("%s Itinerary", data.TripDetails.Destination))
-    pdf.SetFont("Helvetica", "", 10)
+    pdf.Cell(0, 0, loc.T("header.itinerary", data.TripDetails.Destination))
+    pdf.SetFont(fontFamily, "", 10)
     pdf.SetXY(pageWidth/2-50, yPos+30)
-    pdf.Cell(0, 0, fmt.Sprintf("%d Days %d Nights", data.TripDetails.Days, data.TripDetails.Nights))
+    pdf.Cell(0, 0, loc.N("trip.days", data.TripDetails.Days)+" "+loc.N("trip.nights", data.TripDetails.Nights))
     yPos += headerHeight + 15
 
     // Travel icons (text placeholders)
     iconY := yPos
     iconSpacing := 15.0
     startX := pageWidth/2 - (5*iconSpacing)/2
-    pdf.SetFont("Helvetica", "", 10)
+    pdf.SetFont(fontFamily, "", 10)
     pdf.SetTextColor(0, 0, 0)
     pdf.SetXY(startX, iconY)
-    pdf.Cell(0, 0, "[Flight]")
+    pdf.Cell(0, 0, loc.T("icons.flight"))
     pdf.SetXY(startX+iconSpacing, iconY)
-    pdf.Cell(0, 0, "[Hotel]")
+    pdf.Cell(0, 0, loc.T("icons.hotel"))
     pdf.SetXY(startX+iconSpacing*2, iconY)
-    pdf.Cell(0, 0, "[Time]")
+    pdf.Cell(0, 0, loc.T("icons.time"))
     pdf.SetXY(startX+iconSpacing*3, iconY)
-    pdf.Cell(0, 0, "[Car]")
+    pdf.Cell(0, 0, loc.T("icons.car"))
     pdf.SetXY(startX+iconSpacing*4, iconY)
-    pdf.Cell(0, 0, "[Calendar]")
+    pdf.Cell(0, 0, loc.T("icons.calendar"))
     yPos += 15
 
+    // A code to open the itinerary online
+    if data.ItineraryURL != "" {
+        if code, err := barcode.EncodeQR(data.ItineraryURL, barcode.LevelM); err == nil {
+            pdf.QRCode(20, yPos, 56, code)
+            pdf.SetFont(fontFamily, "", 9)
+            pdf.SetTextColor(84, 28, 156)
+            pdf.SetXY(84, yPos+22)
+            pdf.Cell(0, 0, loc.T("link.scan"))
+            pdf.SetFont(fontFamily, "", 7)
+            pdf.SetTextColor(100, 100, 100)
+            pdf.SetXY(84, yPos+34)
+            pdf.Cell(0, 0, fitText(pdf, data.ItineraryURL, 400))
+            yPos += 66
+        } else {
+            logger.Warn("Skipping itinerary link QR code", "error", err)
+        }
+    }
+
+    endSection()
+    endSection = opts.startSection("trip_details")
+
     // Trip details table
     checkPageBreak(35)
     pdf.SetFillColor(245, 245, 245)
@@ -126,57 +208,81 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
     colWidth := tableWidth / 5
 
     pdf.SetTextColor(0, 0, 0)
-    pdf.SetFont("Helvetica", "", 8)
+    pdf.SetFont(fontFamily, "", 8)
     pdf.SetXY(25, yPos+6)
-    pdf.Cell(0, 0, "Departure From")
+    pdf.Cell(0, 0, loc.T("trip.departureFrom"))
     pdf.SetXY(25+colWidth, yPos+6)
-    pdf.Cell(0, 0, "Departure")
+    pdf.Cell(0, 0, loc.T("trip.departure"))
     pdf.SetXY(25+colWidth*2, yPos+6)
-    pdf.Cell(0, 0, "Arrival")
+    pdf.Cell(0, 0, loc.T("trip.arrival"))
     pdf.SetXY(25+colWidth*3, yPos+6)
-    pdf.Cell(0, 0, "Destination")
+    pdf.Cell(0, 0, loc.T("trip.destination"))
     pdf.SetXY(25+colWidth*4, yPos+6)
-    pdf.Cell(0, 0, "No. Of Travellers")
+    pdf.Cell(0, 0, loc.T("trip.travellers"))
 
-    pdf.SetFont("Helvetica", "", 9)
+    pdf.SetFont(fontFamily, "", 9)
     pdf.SetXY(25, yPos+14)
     pdf.Cell(0, 0, data.TripDetails.DepartureFrom)
     pdf.SetXY(25+colWidth, yPos+14)
-    pdf.Cell(0, 0, data.TripDetails.DepartureDate)
+    pdf.Cell(0, 0, loc.FormatDate(data.TripDetails.DepartureDate))
     pdf.SetXY(25+colWidth*2, yPos+14)
-    pdf.Cell(0, 0, data.TripDetails.ArrivalDate)
+    pdf.Cell(0, 0, loc.FormatDate(data.TripDetails.ArrivalDate))
     pdf.SetXY(25+colWidth*3, yPos+14)
     pdf.Cell(0, 0, data.TripDetails.Destination)
     pdf.SetXY(25+colWidth*4, yPos+14)
     pdf.Cell(0, 0, fmt.Sprintf("%d", data.TripDetails.NumberOfTravelers))
     yPos += 35
 
+    endSection()
+    endSection = opts.startSection("travellers")
+
+    // Traveller manifest, with passport numbers masked
+    if len(data.Travellers) > 0 {
+        checkPageBreak(15 + 10 + manifestRowHeight)
+        localTitle(pdf, 20, yPos, loc.T("travellers.title"))
+        yPos += 15
+        yPos += manifestHeader(pdf, loc, yPos)
+        for i, traveller := range data.Travellers {
+            page := pdf.PageNo()
+            checkPageBreak(manifestRowHeight)
+            if pdf.PageNo() != page {
+                yPos += manifestHeader(pdf, loc, yPos)
+            }
+            yPos += manifestRow(pdf, loc, data.TripDetails, yPos, i, traveller)
+        }
+        checkPageBreak(10)
+        pdf.SetFont(fontFamily, "", 7)
+        pdf.SetTextColor(100, 100, 100)
+        pdf.SetXY(25, yPos+6)
+        pdf.Cell(0, 0, loc.T("travellers.note"))
+        yPos += 25
+    }
+
+    endSection()
+    endSection = opts.startSection("daily_itinerary")
+
     // Daily itinerary
     for _, day := range data.DailyItinerary {
         checkPageBreak(80)
         pdf.SetFillColor(84, 28, 156)
         pdf.Rect(20, yPos, 30, 60, "F")
         pdf.SetTextColor(255, 255, 255)
-        pdf.SetFont("Helvetica", "", 8)
+        pdf.SetFont(fontFamily, "", 8)
         pdf.SetXY(35, yPos+20)
-        pdf.Cell(0, 0, "Day")
-        pdf.SetFont("Helvetica", "", 14)
+        pdf.Cell(0, 0, loc.T("day.label"))
+        pdf.SetFont(fontFamily, "", 14)
         pdf.SetXY(35, yPos+35)
         pdf.Cell(0, 0, fmt.Sprintf("%d", day.Day))
 
         pdf.SetTextColor(0, 0, 0)
-        pdf.SetFont("Helvetica", "", 10)
+        pdf.SetFont(fontFamily, "", 10)
         pdf.SetXY(60, yPos+15)
-        dateStr := day.Date
-        if dateStr == "" {
-            dateStr = "27th November"
-        }
-        pdf.Cell(0, 0, dateStr)
-        pdf.SetFont("Helvetica", "", 8)
+        pdf.Cell(0, 0, loc.FormatDate(timeline.DayDate(data.TripDetails, day)))
+        pdf.SetFont(fontFamily, "", 8)
         pdf.SetXY(60, yPos+22)
-        pdf.Cell(0, 0, fmt.Sprintf("Arrival In %s & City", data.TripDetails.Destination))
+        pdf.Cell(0, 0, loc.T("day.arrival", data.TripDetails.Destination))
         pdf.SetXY(60, yPos+28)
-        pdf.Cell(0, 0, "Exploration")
+        pdf.Cell(0, 0, loc.T("day.exploration"))
 
         timelineY := yPos + 35
         timelineX := 60.0
@@ -188,12 +294,12 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
             pdf.SetDrawColor(84, 28, 156)
             pdf.Line(timelineX, timelineY, timelineX, timelineY+12)
             pdf.SetTextColor(0, 0, 0)
-            pdf.SetFont("Helvetica", "", 8)
+            pdf.SetFont(fontFamily, "", 8)
             pdf.SetXY(timelineX+5, timelineY-1)
-            pdf.Cell(0, 0, "Morning")
+            pdf.Cell(0, 0, loc.T("time.morning"))
             timelineY += 6
             for _, activity := range morningActivities {
-                pdf.SetFont("Helvetica", "", 7)
+                pdf.SetFont(fontFamily, "", 7)
                 pdf.SetXY(timelineX+8, timelineY)
                 pdf.Cell(0, 0, fmt.Sprintf("• %s", activity.Name))
                 timelineY += 6
@@ -213,12 +319,12 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
             pdf.SetDrawColor(84, 28, 156)
             pdf.Line(timelineX, timelineY, timelineX, timelineY+12)
             pdf.SetTextColor(0, 0, 0)
-            pdf.SetFont("Helvetica", "", 8)
+            pdf.SetFont(fontFamily, "", 8)
             pdf.SetXY(timelineX+5, timelineY-1)
-            pdf.Cell(0, 0, "Afternoon")
+            pdf.Cell(0, 0, loc.T("time.afternoon"))
             timelineY += 6
             for _, activity := range afternoonActivities {
-                pdf.SetFont("Helvetica", "", 7)
+                pdf.SetFont(fontFamily, "", 7)
                 pdf.SetXY(timelineX+8, timelineY)
                 pdf.Cell(0, 0, fmt.Sprintf("• %s", activity.Name))
                 timelineY += 6
@@ -236,12 +342,12 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
             pdf.SetFillColor(84, 28, 156)
             pdf.Circle(timelineX, timelineY, 1.5, "F")
             pdf.SetTextColor(0, 0, 0)
-            pdf.SetFont("Helvetica", "", 8)
+            pdf.SetFont(fontFamily, "", 8)
             pdf.SetXY(timelineX+5, timelineY-1)
-            pdf.Cell(0, 0, "Evening")
+            pdf.Cell(0, 0, loc.T("time.evening"))
             timelineY += 6
             for _, activity := range eveningActivities {
-                pdf.SetFont("Helvetica", "", 7)
+                pdf.SetFont(fontFamily, "", 7)
                 pdf.SetXY(timelineX+8, timelineY)
                 pdf.Cell(0, 0, fmt.Sprintf("• %s", activity.Name))
                 timelineY += 6
@@ -257,152 +363,123 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
         yPos += max(70, timelineY-yPos+15)
     }
 
+    endSection()
+    endSection = opts.startSection("flights")
+
     // Flight Summary Section
     if len(data.Flights) > 0 {
         checkPageBreak(60)
-        pdf.SetFont("Helvetica", "", 14)
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetXY(20, yPos)
-        pdf.Cell(0, 0, "Flight ")
-        pdf.SetTextColor(147, 51, 234)
-        pdf.SetXY(42, yPos)
-        pdf.Cell(0, 0, "Summary")
+        localTitle(pdf, 20, yPos, loc.T("flights.title"))
         yPos += 15
 
-        for _, flight := range data.Flights {
-            checkPageBreak(20)
-            pdf.SetFillColor(240, 230, 255)
-            pdf.Rect(20, yPos, pageWidth-40, 15, "F")
-            arrowWidth := 60.0
-            pdf.SetFillColor(220, 200, 255)
-            pdf.Rect(20, yPos, arrowWidth, 15, "F")
-            pdf.SetTextColor(84, 28, 156)
-            pdf.SetFont("Helvetica", "", 8)
-            pdf.SetXY(25, yPos+9)
-            dateStr := flight.Date
-            if dateStr == "" {
-                dateStr = "Thu 10 Jan'24"
+        // Connecting segments are drawn together with the layover between them
+        zone, _ := datetime.LoadZone(data.TripDetails.TimeZone)
+        for _, journey := range timeline.Journeys(data.Flights, zone) {
+            for k, flight := range journey.Segments {
+                if k > 0 {
+                    checkPageBreak(cardHeight + 14)
+                    yPos += layoverStrip(pdf, loc, yPos, journey.Layovers[k-1])
+                } else {
+                    checkPageBreak(cardHeight + 4)
+                }
+                yPos += flightCard(pdf, loc, yPos, flight, zone)
             }
-            pdf.Cell(0, 0, dateStr)
-            pdf.SetTextColor(0, 0, 0)
-            pdf.SetXY(95, yPos+9)
-            pdf.Cell(0, 0, fmt.Sprintf("%s From %s To %s", flight.Airline, flight.From, flight.To))
-            yPos += 18
+            yPos += 2
         }
 
-        pdf.SetFont("Helvetica", "", 7)
+        pdf.SetFont(fontFamily, "", 7)
         pdf.SetTextColor(100, 100, 100)
         pdf.SetXY(20, yPos+5)
-        pdf.Cell(0, 0, "Note: All Flights Include Meals, Seat Choice (Excluding XL), And 20kg/25Kg Checked Baggage.")
+        pdf.Cell(0, 0, loc.T("flights.note"))
         yPos += 20
     }
 
+    endSection()
+    endSection = opts.startSection("hotels")
+
     // Hotel Bookings Section
     if len(data.Hotels) > 0 {
-        checkPageBreak(80)
-        pdf.SetFont("Helvetica", "", 14)
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetXY(20, yPos)
-        pdf.Cell(0, 0, "Hotel ")
-        pdf.SetTextColor(147, 51, 234)
-        pdf.SetXY(40, yPos)
-        pdf.Cell(0, 0, "Bookings")
+        checkPageBreak(hotelCardHeight + 20)
+        localTitle(pdf, 20, yPos, loc.T("hotels.title"))
         yPos += 15
 
-        pdf.SetFillColor(84, 28, 156)
-        pdf.Rect(20, yPos, pageWidth-40, 10, "F")
-        pdf.SetTextColor(255, 255, 255)
-        pdf.SetFont("Helvetica", "", 8)
-        colWidths := []float64{30, 30, 30, 20, 60}
-        xPos := 25.0
-        pdf.SetXY(xPos, yPos+6)
-        pdf.Cell(0, 0, "City")
-        xPos += colWidths[0]
-        pdf.SetXY(xPos, yPos+6)
-        pdf.Cell(0, 0, "Check In")
-        xPos += colWidths[1]
-        pdf.SetXY(xPos, yPos+6)
-        pdf.Cell(0, 0, "Check Out")
-        xPos += colWidths[2]
-        pdf.SetXY(xPos, yPos+6)
-        pdf.Cell(0, 0, "Nights")
-        xPos += colWidths[3]
-        pdf.SetXY(xPos, yPos+6)
-        pdf.Cell(0, 0, "Hotel Name")
-        yPos += 10
-
-        for i, hotel := range data.Hotels {
-            checkPageBreak(12)
-            if i%2 == 0 {
-                pdf.SetFillColor(248, 240, 255)
-            } else {
-                pdf.SetFillColor(255, 255, 255)
-            }
-            pdf.Rect(20, yPos, pageWidth-40, 10, "F")
-            pdf.SetTextColor(0, 0, 0)
-            pdf.SetFont("Helvetica", "", 7)
-            xPos = 25
-            pdf.SetXY(xPos, yPos+6)
-            pdf.Cell(0, 0, hotel.City)
-            xPos += colWidths[0]
-            pdf.SetXY(xPos, yPos+6)
-            pdf.Cell(0, 0, hotel.CheckIn)
-            xPos += colWidths[1]
-            pdf.SetXY(xPos, yPos+6)
-            pdf.Cell(0, 0, hotel.CheckOut)
-            xPos += colWidths[2]
-            pdf.SetXY(xPos, yPos+6)
-            pdf.Cell(0, 0, fmt.Sprintf("%d", hotel.Nights))
-            xPos += colWidths[3]
-            pdf.SetXY(xPos, yPos+6)
-            pdf.Cell(0, 0, hotel.Name)
-            yPos += 10
+        for _, hotel := range timeline.Hotels(data.Hotels) {
+            checkPageBreak(hotelCardHeight + 5)
+            yPos += hotelCard(pdf, loc, yPos, hotel)
         }
         yPos += 15
     }
 
+    endSection()
+    endSection = opts.startSection("payment_plan")
+
     // Payment Plan Section
-    if data.PaymentPlan.TotalAmount > 0 {
+    if data.PaymentPlan.TotalAmount.MinorUnits > 0 {
         checkPageBreak(100)
-        pdf.SetFont("Helvetica", "", 14)
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetXY(20, yPos)
-        pdf.Cell(0, 0, "Payment ")
-        pdf.SetTextColor(147, 51, 234)
-        pdf.SetXY(55, yPos)
-        pdf.Cell(0, 0, "Plan")
+        localTitle(pdf, 20, yPos, loc.T("payment.title"))
         yPos += 15
 
-        pdf.SetFillColor(240, 230, 255)
-        pdf.Rect(20, yPos, pageWidth-40, 12, "F")
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetFont("Helvetica", "", 8)
-        pdf.SetXY(25, yPos+7)
-        pdf.Cell(0, 0, "Total Amount")
-        pdf.SetXY(110, yPos+7)
-        pdf.Cell(0, 0, fmt.Sprintf("₹%d For %d Pax (Inclusive of GST)", data.PaymentPlan.TotalAmount, data.TripDetails.NumberOfTravelers))
-        yPos += 15
+        // Helper to print one label/value row of the payment summary
+        paymentRow := func(label, value string) {
+            checkPageBreak(15)
+            pdf.SetFillColor(240, 230, 255)
+            pdf.Rect(20, yPos, pageWidth-40, 12, "F")
+            pdf.SetTextColor(0, 0, 0)
+            pdf.SetFont(fontFamily, "", 8)
+            pdf.SetXY(25, yPos+7)
+            pdf.Cell(0, 0, label)
+            pdf.SetXY(110, yPos+7)
+            pdf.Cell(0, 0, value)
+            yPos += 15
+        }
 
-        pdf.SetFillColor(240, 230, 255)
-        pdf.Rect(20, yPos, pageWidth-40, 12, "F")
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetXY(25, yPos+7)
-        pdf.Cell(0, 0, "TCS")
-        pdf.SetXY(110, yPos+7)
-        pdf.Cell(0, 0, mapBoolToString(data.PaymentPlan.TCSCollected))
-        yPos += 20
+        var taxes *tax.Result
+        if tax.TotalIsPreTax(data.PaymentPlan) {
+            var err error
+            taxes, err = tax.Compute(data.PaymentPlan.TotalAmount, *data.PaymentPlan.Tax, tax.DefaultRules())
+            if err != nil {
+                logger.Warn("Falling back to the TCS flag, taxes could not be computed", "error", err)
+            }
+        }
+
+        if taxes != nil {
+            paymentRow(loc.T("payment.package"), loc.T("payment.packageValue", formatAmount(taxes.Package), data.TripDetails.NumberOfTravelers))
+            for _, line := range taxes.Lines {
+                paymentRow(loc.M(line.Label), loc.T("payment.taxLine", formatAmount(line.Amount), money.Format(line.Base, loc.Tag)))
+            }
+            paymentRow(loc.T("payment.payable"), formatAmount(taxes.Payable))
+            for _, note := range taxes.Notes {
+                checkPageBreak(10)
+                pdf.SetFont(fontFamily, "", 7)
+                pdf.SetTextColor(100, 100, 100)
+                pdf.SetXY(25, yPos+3)
+                pdf.Cell(0, 0, loc.T("payment.note", loc.M(note)))
+                yPos += 9
+            }
+            yPos += 5
+        } else {
+            paymentRow(loc.T("payment.total"), loc.T("payment.totalValue", formatAmount(data.PaymentPlan.TotalAmount), data.TripDetails.NumberOfTravelers))
+            paymentRow(loc.T("payment.tcs"), mapBoolToString(loc, data.PaymentPlan.TCSCollected))
+            yPos += 5
+        }
 
         if len(data.PaymentPlan.Installments) > 0 {
             pdf.SetFillColor(84, 28, 156)
             pdf.Rect(20, yPos, pageWidth-40, 10, "F")
             pdf.SetTextColor(255, 255, 255)
-            pdf.SetFont("Helvetica", "", 8)
+            pdf.SetFont(fontFamily, "", 8)
             pdf.SetXY(25, yPos+6)
-            pdf.Cell(0, 0, "Installment")
+            pdf.Cell(0, 0, loc.T("payment.installment"))
             pdf.SetXY(70, yPos+6)
-            pdf.Cell(0, 0, "Amount")
+            pdf.Cell(0, 0, loc.T("payment.amount"))
             pdf.SetXY(115, yPos+6)
-            pdf.Cell(0, 0, "Due Date")
+            pdf.Cell(0, 0, loc.T("payment.dueDate"))
+            var statuses map[string]payments.InstallmentStatus
+            if opts.PaymentStatus != nil {
+                statuses = opts.PaymentStatus.ByInstallment()
+                pdf.SetXY(170, yPos+6)
+                pdf.Cell(0, 0, loc.T("payment.status"))
+            }
             yPos += 10
 
             for i, installment := range data.PaymentPlan.Installments {
@@ -414,55 +491,210 @@ func GeneratePDF(data types.ItineraryData) ([]byte, error) {
                 }
                 pdf.Rect(20, yPos, pageWidth-40, 10, "F")
                 pdf.SetTextColor(0, 0, 0)
-                pdf.SetFont("Helvetica", "", 7)
+                pdf.SetFont(fontFamily, "", 7)
                 pdf.SetXY(25, yPos+6)
                 pdf.Cell(0, 0, installment.Name)
                 pdf.SetXY(70, yPos+6)
-                pdf.Cell(0, 0, fmt.Sprintf("₹%d", installment.Amount))
+                pdf.Cell(0, 0, formatAmount(installment.Amount))
                 pdf.SetXY(115, yPos+6)
-                pdf.Cell(0, 0, installment.DueDate)
+                pdf.Cell(0, 0, loc.FormatDate(installment.DueDate))
+                if status, ok := statuses[installment.ID]; ok {
+                    statusBadge(pdf, loc, 170, yPos+1.5, status)
+                }
+                yPos += 10
+            }
+
+            if status := opts.PaymentStatus; status != nil {
+                yPos += 5
+                paymentRow(loc.T("payment.paidSoFar"), formatAmount(status.Paid))
+                outstanding := formatAmount(status.Outstanding)
+                if !status.Overdue.IsZero() {
+                    outstanding = loc.T("payment.overdue", outstanding, money.Format(status.Overdue, loc.Tag))
+                }
+                paymentRow(loc.T("payment.outstanding", loc.FormatDate(status.AsOf)), outstanding)
+            }
+
+            // A UPI code for every installment still to pay, four to a row
+            if codes := upiCodes(ctx, data, opts); len(codes) > 0 {
                 yPos += 10
+                checkPageBreak(15 + upiCardHeight)
+                localTitle(pdf, 20, yPos, loc.T("payment.upiTitle"))
+                yPos += 15
+                for i, code := range codes {
+                    if i%4 == 0 {
+                        if i > 0 {
+                            yPos += upiCardHeight + 10
+                        }
+                        checkPageBreak(upiCardHeight)
+                    }
+                    upiCard(pdf, loc, 20+float64(i%4)*(upiCardWidth+upiCardGap), yPos, code)
+                }
+                yPos += upiCardHeight
             }
         }
         yPos += 15
     }
 
-    // Visa Details Section
-    if data.VisaDetails.VisaType != "" {
-        checkPageBreak(40)
-        pdf.SetFont("Helvetica", "", 14)
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetXY(20, yPos)
-        pdf.Cell(0, 0, "Visa ")
-        pdf.SetTextColor(147, 51, 234)
-        pdf.SetXY(40, yPos)
-        pdf.Cell(0, 0, "Details")
+    endSection()
+    endSection = opts.startSection("cost_breakdown")
+
+    // Cost Breakdown Section
+    breakdown := pricing.Calculator{Rates: rates}.Compute(data)
+    if !breakdown.Subtotal.IsZero() || data.Pricing != nil {
+        checkPageBreak(100)
+        localTitle(pdf, 20, yPos, loc.T("costs.title"))
+        yPos += 15
+
+        colX := []float64{25, 70, 170, 300, 430}
+        pdf.SetFillColor(84, 28, 156)
+        pdf.Rect(20, yPos, pageWidth-40, 10, "F")
+        pdf.SetTextColor(255, 255, 255)
+        pdf.SetFont(fontFamily, "", 8)
+        for i, header := range []string{loc.T("costs.day"), loc.T("costs.date"), loc.T("costs.activities"), loc.T("costs.transfers"), loc.T("costs.total")} {
+            pdf.SetXY(colX[i], yPos+6)
+            pdf.Cell(0, 0, header)
+        }
+        yPos += 10
+
+        for i, day := range breakdown.Days {
+            checkPageBreak(12)
+            if i%2 == 0 {
+                pdf.SetFillColor(248, 240, 255)
+            } else {
+                pdf.SetFillColor(255, 255, 255)
+            }
+            pdf.Rect(20, yPos, pageWidth-40, 10, "F")
+            pdf.SetTextColor(0, 0, 0)
+            pdf.SetFont(fontFamily, "", 7)
+            for j, value := range []string{fmt.Sprintf("%d", day.Day), loc.FormatDate(day.Date), formatAmount(day.Activities), formatAmount(day.Transfers), formatAmount(day.Total)} {
+                pdf.SetXY(colX[j], yPos+6)
+                pdf.Cell(0, 0, value)
+            }
+            yPos += 10
+        }
+        yPos += 5
+
+        // Totals, adjustments and the final package price
+        lines := []pricing.Line{
+            {Label: i18n.Msg("costs.activities"), Amount: breakdown.Categories[pricing.CategoryActivities]},
+            {Label: i18n.Msg("costs.transfers"), Amount: breakdown.Categories[pricing.CategoryTransfers]},
+            {Label: i18n.Msg("costs.subtotal"), Amount: breakdown.Subtotal},
+        }
+        lines = append(lines, breakdown.Adjustments...)
+        lines = append(lines, pricing.Line{Label: i18n.Msg("costs.total"), Amount: breakdown.Total})
+        if breakdown.Travellers > 0 {
+            lines = append(lines, pricing.Line{Label: i18n.Msg("costs.perPerson", breakdown.Travellers), Amount: breakdown.PerPerson})
+        }
+        for _, line := range lines {
+            checkPageBreak(12)
+            pdf.SetFillColor(240, 230, 255)
+            pdf.Rect(20, yPos, pageWidth-40, 12, "F")
+            pdf.SetTextColor(0, 0, 0)
+            pdf.SetFont(fontFamily, "", 8)
+            pdf.SetXY(25, yPos+7)
+            pdf.Cell(0, 0, loc.M(line.Label))
+            pdf.SetXY(300, yPos+7)
+            pdf.Cell(0, 0, formatAmount(line.Amount))
+            yPos += 13
+        }
+
+        if !breakdown.Reconciled() {
+            boxHeight := 14 + 9*float64(len(breakdown.Warnings))
+            checkPageBreak(boxHeight + 5)
+            yPos += 3
+            pdf.SetFillColor(255, 235, 235)
+            pdf.SetDrawColor(200, 40, 40)
+            pdf.Rect(20, yPos, pageWidth-40, boxHeight, "FD")
+            pdf.SetTextColor(200, 40, 40)
+            pdf.SetFont(fontFamily, "", 8)
+            pdf.SetXY(25, yPos+8)
+            pdf.Cell(0, 0, loc.T("costs.warning"))
+            for i, warning := range breakdown.Warnings {
+                pdf.SetFont(fontFamily, "", 7)
+                pdf.SetXY(30, yPos+17+9*float64(i))
+                pdf.Cell(0, 0, "• "+loc.M(warning))
+            }
+            yPos += boxHeight
+        }
+        yPos += 15
+    }
+
+    endSection()
+    endSection = opts.startSection("visa")
+
+    // Visas, each with the documents its travellers gather
+    if len(data.Visas) > 0 {
+        checkPageBreak(15 + visaCardHeight)
+        localTitle(pdf, 20, yPos, loc.T("visa.title"))
+        yPos += 15
+        checklists := visa.Checklists(data)
+        for i, v := range data.Visas {
+            checkPageBreak(visaCardHeight)
+            yPos += visaCard(pdf, loc, yPos, data, v)
+            for _, c := range checklists {
+                if c.Visa != i {
+                    continue
+                }
+                checkPageBreak(checklistHeight(c))
+                yPos += visaChecklist(pdf, loc, yPos, c)
+            }
+        }
+        if len(checklists) > 0 {
+            checkPageBreak(10)
+            pdf.SetFont(fontFamily, "", 7)
+            pdf.SetTextColor(100, 100, 100)
+            pdf.SetXY(25, yPos+4)
+            pdf.Cell(0, 0, loc.T("visa.note"))
+            yPos += 10
+        }
         yPos += 15
-        pdf.SetFillColor(245, 245, 245)
-        pdf.Rect(20, yPos, pageWidth-40, 20, "F")
-        pdf.SetTextColor(0, 0, 0)
-        pdf.SetFont("Helvetica", "", 8)
-        pdf.SetXY(30, yPos+8)
-        pdf.Cell(0, 0, fmt.Sprintf("Visa Type: %s", data.VisaDetails.VisaType))
-        pdf.SetXY(100, yPos+8)
-        pdf.Cell(0, 0, fmt.Sprintf("Validity: %s", data.VisaDetails.Validity))
-        pdf.SetXY(30, yPos+15)
-        pdf.Cell(0, 0, fmt.Sprintf("Processing Date: %s", data.VisaDetails.ProcessingDate))
-        yPos += 35
     }
 
-    // Add footer to all pages
-    addFooterToAllPages()
+    endSection()
+    endSection = opts.startSection("vouchers")
 
-    // Generate PDF as byte array
-    var buf bytes.Buffer
-    err := pdf.Output(&buf)
+    // A voucher page for every booking, unless they are printed separately
+    if data.Vouchers != types.VouchersOmit {
+        for _, v := range voucher.Build(data) {
+            voucherPage(pdf, loc, opts.Branding, data.TripDetails, v)
+        }
+    }
+
+    endSection()
+
+    out, err := writeDocument(ctx, pdf, opts)
     if err != nil {
-        log.Printf("Error writing PDF: %v", err)
         return nil, err
     }
+    logger.Debug("PDF rendered", "pages", pdf.PageCount(), "bytes", len(out))
+    return out, nil
+}
 
-    return buf.Bytes(), nil
+// statusBadge draws a coloured Paid/Due/Overdue label for an installment
+func statusBadge(pdf *canvas, loc *i18n.Locale, x, y float64, status payments.InstallmentStatus) {
+    label := loc.T("status.due")
+    switch status.Status {
+    case payments.StatusPaid:
+        label = loc.T("status.paid")
+        pdf.SetFillColor(34, 139, 34)
+    case payments.StatusOverdue:
+        label = loc.T("status.overdue")
+        pdf.SetFillColor(200, 40, 40)
+    default:
+        pdf.SetFillColor(230, 150, 0)
+    }
+    if status.Status != payments.StatusPaid && !status.Paid.IsZero() {
+        label = loc.T("status.partial", label, money.Format(status.Paid, loc.Tag))
+    }
+
+    pdf.SetFont(fontFamily, "", 6)
+    width := pdf.GetStringWidth(label) + 8
+    pdf.RoundedRect(x, y, width, 7, 2, "1234", "F")
+    pdf.SetTextColor(255, 255, 255)
+    pdf.SetXY(x+4, y+3.5)
+    pdf.Cell(0, 0, label)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetFont(fontFamily, "", 7)
 }
 
 // Helper function to filter activities by type
@@ -477,11 +709,11 @@ func filterActivities(activities []types.Activity, activityType string) []types.
 }
 
 // Helper function to map boolean to string
-func mapBoolToString(b bool) string {
+func mapBoolToString(loc *i18n.Locale, b bool) string {
     if b {
-        return "Collected"
+        return loc.T("payment.tcsCollected")
     }
-    return "Not Collected"
+    return loc.T("payment.tcsNotCollected")
 }
 
 // Helper function to get maximum of two float64 values
@@ -0,0 +1,85 @@
+package utils
+
+import (
+    "context"
+    "vigovia-pdf-api/barcode"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/money"
+    "vigovia-pdf-api/payments"
+    "vigovia-pdf-api/types"
+)
+
+const (
+    upiCardWidth  = 125.0
+    upiCardHeight = 124.0
+    // upiCardGap spreads four cards across the page
+    upiCardGap = (a4Width - 40 - 4*upiCardWidth) / 3
+)
+
+// upiCode is a QR code to pay what is left of one installment
+type upiCode struct {
+    name   string
+    amount money.Money
+    due    datetime.Date
+    code   *barcode.QR
+}
+
+// upiCodes lists a pay code for every rupee installment not yet paid, for
+// what is still outstanding on it when payments are known
+func upiCodes(ctx context.Context, data types.ItineraryData, opts Options) []upiCode {
+    if opts.UPI.ID == "" {
+        return nil
+    }
+    var statuses map[string]payments.InstallmentStatus
+    if opts.PaymentStatus != nil {
+        statuses = opts.PaymentStatus.ByInstallment()
+    }
+    var out []upiCode
+    for _, installment := range data.PaymentPlan.Installments {
+        amount := installment.Amount
+        if status, ok := statuses[installment.ID]; ok {
+            if status.Status == payments.StatusPaid {
+                continue
+            }
+            amount = status.Outstanding
+        }
+        if amount.Currency != "INR" || amount.MinorUnits <= 0 {
+            continue
+        }
+        link, err := payments.UPILink(opts.UPI, amount, data.TripDetails.Destination+" - "+installment.Name)
+        if err != nil {
+            continue
+        }
+        code, err := barcode.EncodeQR(link, barcode.LevelM)
+        if err != nil {
+            logging.FromContext(ctx).Warn("Skipping UPI QR code", "installment", installment.ID, "error", err)
+            continue
+        }
+        out = append(out, upiCode{name: installment.Name, amount: amount, due: installment.DueDate, code: code})
+    }
+    return out
+}
+
+// upiCard draws a pay code with the installment, amount and due date below it
+func upiCard(pdf *canvas, loc *i18n.Locale, x, y float64, code upiCode) {
+    pdf.SetDrawColor(200, 200, 200)
+    pdf.SetLineWidth(0.5)
+    pdf.RoundedRect(x, y, upiCardWidth, upiCardHeight, 3, "1234", "D")
+    pdf.QRCode(x+(upiCardWidth-84)/2, y+4, 84, code.code)
+
+    middle := x + upiCardWidth/2
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(0, 0, 0)
+    centred(pdf, middle, y+96, fitText(pdf, code.name, upiCardWidth-10))
+    pdf.SetFont(fontFamily, "", 10)
+    pdf.SetTextColor(84, 28, 156)
+    centred(pdf, middle, y+107, money.Format(code.amount, loc.Tag))
+    if !code.due.IsZero() {
+        pdf.SetFont(fontFamily, "", 7)
+        pdf.SetTextColor(100, 100, 100)
+        centred(pdf, middle, y+117, loc.T("payment.upiDue", loc.FormatDate(code.due)))
+    }
+    pdf.SetLineWidth(0.2)
+}
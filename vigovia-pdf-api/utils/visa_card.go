@@ -0,0 +1,110 @@
+package utils
+
+import (
+    "strings"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/types"
+    "vigovia-pdf-api/visa"
+)
+
+const (
+    visaCardHeight = 30.0
+    // checklistRowHeight is the height of a row of two documents
+    checklistRowHeight = 10.0
+)
+
+// visaCard draws a visa: the country and who it is for, its type, validity
+// and processing date, and its status. It returns the height used.
+func visaCard(pdf *canvas, loc *i18n.Locale, y float64, data types.ItineraryData, v types.Visa) float64 {
+    right := a4Width - 20
+    pdf.SetFillColor(245, 245, 245)
+    pdf.Rect(20, y, right-20, visaCardHeight, "F")
+
+    // A visa for a destination the airport data can't place is shown by it
+    country := orDash(data.TripDetails.Destination)
+    if code := visa.Country(data, v); code != "" {
+        country = visa.CountryName(code)
+    }
+    who := v.Traveller
+    if who == "" {
+        who = loc.T("visa.everyone")
+    }
+    pdf.SetFont(fontFamily, "", 9)
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetXY(30, y+9)
+    pdf.Cell(0, 0, fitText(pdf, country+" · "+who, 380))
+
+    var details []string
+    if v.VisaType != "" {
+        details = append(details, loc.T("visa.type", v.VisaType))
+    }
+    if v.Validity != "" {
+        details = append(details, loc.T("visa.validity", v.Validity))
+    }
+    if !v.ProcessingDate.IsZero() {
+        details = append(details, loc.T("visa.processingDate", loc.FormatDate(v.ProcessingDate)))
+    }
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(30, y+21)
+    pdf.Cell(0, 0, fitText(pdf, strings.Join(details, " · "), right-40))
+
+    visaStatusBadge(pdf, loc, right-10, y+5, v.CurrentStatus())
+    return visaCardHeight + 5
+}
+
+// visaStatusBadge draws a coloured label for where an application stands,
+// ending at right
+func visaStatusBadge(pdf *canvas, loc *i18n.Locale, right, y float64, status string) {
+    switch status {
+    case types.VisaApproved:
+        pdf.SetFillColor(34, 139, 34)
+    case types.VisaSubmitted:
+        pdf.SetFillColor(40, 90, 200)
+    default:
+        pdf.SetFillColor(230, 150, 0)
+    }
+    label := loc.T("visa.status." + status)
+    pdf.SetFont(fontFamily, "", 6)
+    width := pdf.GetStringWidth(label) + 8
+    pdf.RoundedRect(right-width, y, width, 7, 2, "1234", "F")
+    pdf.SetTextColor(255, 255, 255)
+    pdf.SetXY(right-width+4, y+3.5)
+    pdf.Cell(0, 0, label)
+    pdf.SetTextColor(0, 0, 0)
+}
+
+// checklistHeight is the height visaChecklist uses for a checklist
+func checklistHeight(c visa.Checklist) float64 {
+    return 22 + float64((len(c.Documents)+1)/2)*checklistRowHeight + 5
+}
+
+// visaChecklist draws the documents a group gathers for a visa as boxes to
+// tick, two to a row, under what the visa requires and whose passports it is
+// for. It returns the height used.
+func visaChecklist(pdf *canvas, loc *i18n.Locale, y float64, c visa.Checklist) float64 {
+    who := strings.Join(c.Travellers, ", ")
+    if who == "" {
+        who = loc.T("visa.everyone")
+    }
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(84, 28, 156)
+    pdf.SetXY(30, y+6)
+    pdf.Cell(0, 0, loc.T("visa.checklist", loc.T("visa.requirement."+c.Rule.Requirement)))
+    pdf.SetFont(fontFamily, "", 7)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(30, y+15)
+    pdf.Cell(0, 0, fitText(pdf, loc.T("visa.checklistFor", visa.CountryName(c.Rule.Nationality), who), a4Width-60))
+
+    pdf.SetTextColor(0, 0, 0)
+    pdf.SetDrawColor(84, 28, 156)
+    for i, code := range c.Documents {
+        x := 30 + float64(i%2)*260
+        rowY := y + 22 + float64(i/2)*checklistRowHeight
+        pdf.Rect(x, rowY+2, 5, 5, "D")
+        pdf.SetXY(x+10, rowY+4.5)
+        pdf.Cell(0, 0, fitText(pdf, loc.T("visaDoc."+code), 240))
+    }
+    pdf.SetDrawColor(0, 0, 0)
+    return checklistHeight(c)
+}
@@ -0,0 +1,180 @@
+package utils
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "strings"
+    "vigovia-pdf-api/barcode"
+    "vigovia-pdf-api/i18n"
+    "vigovia-pdf-api/logging"
+    "vigovia-pdf-api/types"
+    "vigovia-pdf-api/voucher"
+)
+
+// ErrNoVouchers is returned when vouchers are asked for an itinerary with
+// nothing booked
+var ErrNoVouchers = errors.New("no booked hotels, transfers or activities")
+
+// GenerateVouchers renders an itinerary's vouchers on their own, one page per
+// booked hotel, transfer and activity
+func GenerateVouchers(ctx context.Context, data types.ItineraryData, opts Options) ([]byte, error) {
+    logger := logging.FromContext(ctx)
+    vouchers := voucher.Build(data)
+    if len(vouchers) == 0 {
+        return nil, ErrNoVouchers
+    }
+    pdf, err := newDocument(opts)
+    if err != nil {
+        return nil, err
+    }
+
+    endSection := opts.startSection("vouchers")
+    for _, v := range vouchers {
+        voucherPage(pdf, opts.locale(), opts.Branding, data.TripDetails, v)
+    }
+    endSection()
+
+    out, err := writeDocument(ctx, pdf, opts)
+    if err != nil {
+        return nil, err
+    }
+    logger.Debug("Vouchers rendered", "pages", pdf.PageCount(), "bytes", len(out))
+    return out, nil
+}
+
+var voucherTitles = map[string]string{
+    voucher.KindHotel:    "voucher.hotelTitle",
+    voucher.KindTransfer: "voucher.transferTitle",
+    voucher.KindActivity: "voucher.activityTitle",
+}
+
+// voucherPage adds a page the traveller shows the supplier: who is booked,
+// what for and when, the confirmation number with codes to scan, and whom
+// to call
+func voucherPage(pdf *canvas, loc *i18n.Locale, branding types.Branding, trip types.TripDetails, v voucher.Voucher) {
+    pdf.AddPage()
+    yPos := documentHeader(pdf, branding, loc.T(voucherTitles[v.Kind]))
+
+    // The confirmation number is what the supplier looks for first
+    pdf.SetFillColor(248, 240, 255)
+    pdf.SetDrawColor(84, 28, 156)
+    pdf.SetLineWidth(0.5)
+    pdf.RoundedRect(20, yPos, a4Width-40, 72, 3, "1234", "FD")
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(30, yPos+26)
+    pdf.Cell(0, 0, loc.T("hotels.confirmation"))
+    pdf.SetFont(fontFamily, "", 16)
+    pdf.SetTextColor(84, 28, 156)
+    pdf.SetXY(30, yPos+42)
+    pdf.Cell(0, 0, fitText(pdf, orDash(v.Confirmation), 230))
+    // A barcode of the number for desk scanners, and a QR code with the
+    // booking for phones
+    qrX := a4Width - 24 - 64
+    confirmationBarcode(pdf, qrX-10, yPos+16, 40, v.Confirmation)
+    if code, err := barcode.EncodeQR(v.Text(), barcode.LevelM); err == nil {
+        pdf.QRCode(qrX, yPos+4, 64, code)
+    }
+    pdf.SetLineWidth(0.2)
+    yPos += 88
+
+    var fields [][2]string
+    add := func(label, value string) {
+        if strings.TrimSpace(value) != "" {
+            fields = append(fields, [2]string{label, value})
+        }
+    }
+    if len(v.Travellers) > 1 {
+        add(loc.T("voucher.travellers"), strings.Join(v.Travellers, ", "))
+    } else if len(v.Travellers) == 1 {
+        add(loc.T("voucher.guest"), v.Travellers[0])
+    }
+    if trip.NumberOfTravelers > 0 {
+        add(loc.T("trip.travellers"), fmt.Sprintf("%d", trip.NumberOfTravelers))
+    }
+
+    switch v.Kind {
+    case voucher.KindHotel:
+        hotel := v.Hotel
+        add(loc.T("voucher.hotel"), hotel.Name)
+        add(loc.T("voucher.address"), joinNonEmpty(", ", hotel.Address, hotel.City))
+        add(loc.T("voucher.phone"), hotel.Phone)
+        add(loc.T("hotels.checkIn"), orDash(loc.FormatDate(hotel.CheckIn)))
+        add(loc.T("hotels.checkOut"), orDash(loc.FormatDate(hotel.CheckOut)))
+        if hotel.Nights > 0 {
+            add(loc.T("voucher.stay"), loc.N("trip.nights", hotel.Nights))
+        }
+        add(loc.T("voucher.roomType"), hotel.RoomType)
+        if hotel.Rooms > 0 {
+            add(loc.T("voucher.rooms"), fmt.Sprintf("%d", hotel.Rooms))
+        }
+        if hotel.MealPlan != "" {
+            add(loc.T("voucher.mealPlan"), mealPlanLabel(loc, hotel.MealPlan))
+        }
+    case voucher.KindTransfer:
+        transfer := v.Transfer
+        add(loc.T("voucher.service"), transfer.Type)
+        add(loc.T("voucher.date"), orDash(loc.FormatDate(v.Date)))
+        add(loc.T("voucher.pickup"), transfer.Timing)
+        if transfer.Capacity > 0 {
+            add(loc.T("voucher.capacity"), loc.N("voucher.seats", transfer.Capacity))
+        }
+        add(loc.T("voucher.details"), transfer.Description)
+    case voucher.KindActivity:
+        activity := v.Activity
+        add(loc.T("voucher.activity"), activity.Name)
+        add(loc.T("voucher.date"), orDash(loc.FormatDate(v.Date)))
+        if activity.Type != "" {
+            add(loc.T("voucher.session"), loc.T("time."+activity.Type))
+        }
+        add(loc.T("voucher.duration"), activity.Duration)
+        add(loc.T("voucher.details"), activity.Description)
+    }
+
+    // A stay booked directly is already contacted through the hotel
+    if v.Kind != voucher.KindHotel || v.Hotel.Supplier != nil {
+        add(loc.T("voucher.supplier"), v.Supplier.Name)
+        add(loc.T("voucher.phone"), v.Supplier.Phone)
+        add(loc.T("voucher.email"), v.Supplier.Email)
+    }
+    add(loc.T("voucher.bookedBy"), joinNonEmpty(" · ", branding.CompanyName, branding.Phone))
+
+    pdf.SetFont(fontFamily, "", 9)
+    for i := range fields {
+        fields[i][1] = fitText(pdf, fields[i][1], a4Width-130)
+    }
+    yPos = documentMeta(pdf, yPos, fields)
+
+    pdf.SetFont(fontFamily, "", 8)
+    pdf.SetTextColor(100, 100, 100)
+    pdf.SetXY(20, yPos+5)
+    pdf.Cell(0, 0, loc.T("voucher."+v.Kind+"Note"))
+}
+
+// confirmationBarcode draws a Code 128 barcode of the confirmation number on
+// a white panel ending at right, narrowing its bars to fit 200 points.
+// Numbers that can't be encoded, such as ones in other scripts, get none.
+func confirmationBarcode(pdf *canvas, right, y, h float64, confirmation string) {
+    widths, err := barcode.Code128(strings.TrimSpace(confirmation))
+    if err != nil {
+        return
+    }
+    modules := 2 * barcode.QuietZone
+    for _, w := range widths {
+        modules += w
+    }
+    module := 200 / float64(modules)
+    if module > 1.2 {
+        module = 1.2
+    }
+    width := float64(modules) * module
+    pdf.SetFillColor(255, 255, 255)
+    pdf.Rect(right-width, y, width, h, "F")
+    pdf.SetFillColor(0, 0, 0)
+    pdf.Bars(right-width+barcode.QuietZone*module, y+4, module, h-8, widths)
+}
+
+func joinNonEmpty(sep string, values ...string) string {
+    return strings.Join(nonEmpty(values...), sep)
+}
@@ -0,0 +1,209 @@
+// visa/visa.go
+package visa
+
+import (
+    "embed"
+    "encoding/csv"
+    "fmt"
+    "io"
+    "strings"
+    "vigovia-pdf-api/iata"
+    "vigovia-pdf-api/types"
+)
+
+//go:embed data/*.csv
+var dataFiles embed.FS
+
+// Requirements, from the most paperwork to the least
+const (
+    Required  = "required"
+    EVisa     = "eVisa"
+    OnArrival = "onArrival"
+    Free      = "free"
+)
+
+// DefaultNationality is taken for travellers without one, as the rules are
+// written for Indian passport holders
+const DefaultNationality = "IN"
+
+// anyCountry matches every country in the rules table
+const anyCountry = "*"
+
+// Rule is what holders of one passport need to visit a country
+type Rule struct {
+    Nationality    string   `json:"nationality" doc:"ISO 3166-1 alpha-2 code"`
+    Destination    string   `json:"destination" doc:"ISO 3166-1 alpha-2 code"`
+    Requirement    string   `json:"requirement" schema:"enum=required|eVisa|onArrival|free"`
+    Documents      []string `json:"documents" doc:"Codes of the documents to gather, in the order they are usually gathered"`
+    MinorDocuments []string `json:"minorDocuments" doc:"Codes of the further documents children and infants need"`
+}
+
+// Checklist lists the documents a traveller of the age category gathers
+func (r Rule) Checklist(ageCategory string) []string {
+    if !minor(ageCategory) {
+        return r.Documents
+    }
+    return append(append([]string(nil), r.Documents...), r.MinorDocuments...)
+}
+
+var (
+    rules     = map[[2]string]Rule{}
+    countries = map[string]string{}
+)
+
+func init() {
+    for _, row := range readData("rules.csv", 5) {
+        for _, nationality := range strings.Split(row[0], "|") {
+            for _, destination := range strings.Split(row[1], "|") {
+                key := [2]string{nationality, destination}
+                if _, ok := rules[key]; ok {
+                    panic(fmt.Sprintf("visa: rules.csv: %s to %s listed twice", nationality, destination))
+                }
+                rules[key] = Rule{Requirement: row[2], Documents: list(row[3]), MinorDocuments: list(row[4])}
+            }
+        }
+    }
+    for _, row := range readData("countries.csv", 2) {
+        countries[row[0]] = row[1]
+    }
+}
+
+// readData reads an embedded CSV file, skipping its header row
+func readData(name string, fields int) [][]string {
+    f, err := dataFiles.Open("data/" + name)
+    if err != nil {
+        panic(err)
+    }
+    defer f.Close()
+    r := csv.NewReader(f)
+    r.Comment = '#'
+    r.FieldsPerRecord = fields
+    var rows [][]string
+    for header := true; ; header = false {
+        row, err := r.Read()
+        if err == io.EOF {
+            return rows
+        }
+        if err != nil {
+            panic(fmt.Sprintf("visa: %s: %v", name, err))
+        }
+        if !header {
+            rows = append(rows, row)
+        }
+    }
+}
+
+func list(field string) []string {
+    if field == "" {
+        return []string{}
+    }
+    return strings.Split(field, "|")
+}
+
+// Lookup finds what holders of a nationality's passport need to visit a
+// country, trying the pair, then anyone visiting the country, then the
+// nationality going anywhere. Nobody needs a visa for their own country.
+func Lookup(nationality, destination string) (Rule, bool) {
+    nationality = strings.ToUpper(strings.TrimSpace(nationality))
+    destination = strings.ToUpper(strings.TrimSpace(destination))
+    if nationality == "" {
+        nationality = DefaultNationality
+    }
+    if destination == "" || nationality == destination {
+        return Rule{}, false
+    }
+    for _, key := range [][2]string{
+        {nationality, destination},
+        {anyCountry, destination},
+        {nationality, anyCountry},
+        {anyCountry, anyCountry},
+    } {
+        if rule, ok := rules[key]; ok {
+            rule.Nationality, rule.Destination = nationality, destination
+            return rule, true
+        }
+    }
+    return Rule{}, false
+}
+
+// CountryName is a country's English name, or its code when it isn't known
+func CountryName(code string) string {
+    if name, ok := countries[code]; ok {
+        return name
+    }
+    return code
+}
+
+// Country is the country a visa is for: the one given, otherwise the one the
+// trip's destination is in when the airport data places it, otherwise ""
+func Country(data types.ItineraryData, v types.Visa) string {
+    if v.Country != "" {
+        return v.Country
+    }
+    return iata.AirportCountry(data.TripDetails.Destination)
+}
+
+// Covers reports whether the visa is for the traveller
+func Covers(v types.Visa, traveller types.Traveller) bool {
+    name := strings.TrimSpace(v.Traveller)
+    return name == "" || strings.EqualFold(name, strings.TrimSpace(traveller.Name))
+}
+
+// Checklist is the documents travellers of one nationality and age group
+// gather for one of the itinerary's visas
+type Checklist struct {
+    // Visa is the visa's index in the itinerary
+    Visa int
+    Rule Rule
+    // Travellers names who gathers the documents; it is empty for a group
+    // whose travellers aren't listed
+    Travellers []string
+    Documents  []string
+}
+
+// Checklists works out the documents for each of the itinerary's visas, one
+// checklist per nationality and age group the visa covers. Visas for a
+// country that can't be placed, or for the travellers' own, get none.
+func Checklists(data types.ItineraryData) []Checklist {
+    var out []Checklist
+    for i, v := range data.Visas {
+        country := Country(data, v)
+        if country == "" {
+            continue
+        }
+        var travellers []types.Traveller
+        for _, traveller := range data.Travellers {
+            if Covers(v, traveller) {
+                travellers = append(travellers, traveller)
+            }
+        }
+        if len(travellers) == 0 {
+            // Travellers who aren't listed are adults of the default
+            // nationality, named only when the visa names them
+            travellers = []types.Traveller{{Name: strings.TrimSpace(v.Traveller)}}
+        }
+
+        groups := map[string]int{}
+        for _, traveller := range travellers {
+            rule, ok := Lookup(traveller.Nationality, country)
+            if !ok {
+                continue
+            }
+            key := fmt.Sprintf("%s/%t", rule.Nationality, minor(traveller.AgeCategory))
+            g, ok := groups[key]
+            if !ok {
+                g = len(out)
+                groups[key] = g
+                out = append(out, Checklist{Visa: i, Rule: rule, Documents: rule.Checklist(traveller.AgeCategory)})
+            }
+            if traveller.Name != "" {
+                out[g].Travellers = append(out[g].Travellers, traveller.Name)
+            }
+        }
+    }
+    return out
+}
+
+func minor(ageCategory string) bool {
+    return ageCategory == types.AgeChild || ageCategory == types.AgeInfant
+}
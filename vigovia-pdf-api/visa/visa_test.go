@@ -0,0 +1,96 @@
+package visa
+
+import (
+    "fmt"
+    "strings"
+    "testing"
+    "vigovia-pdf-api/types"
+)
+
+func TestLookup(t *testing.T) {
+    tests := []struct {
+        nationality string
+        destination string
+        want        string
+    }{
+        {"IN", "FR", Required},
+        {" in ", "ae", EVisa},
+        {"", "TH", Free},
+        {"IN", "ID", OnArrival},
+        // Any nationality visiting India, then anyone going anywhere
+        {"US", "IN", EVisa},
+        {"US", "FR", Required},
+        // An Indian going somewhere not listed
+        {"IN", "ZZ", Required},
+        {"IN", "IN", ""},
+        {"IN", "", ""},
+    }
+    for _, tt := range tests {
+        rule, ok := Lookup(tt.nationality, tt.destination)
+        if ok != (tt.want != "") || rule.Requirement != tt.want {
+            t.Errorf("Lookup(%q, %q) = %q, %v; want %q", tt.nationality, tt.destination, rule.Requirement, ok, tt.want)
+        }
+    }
+    // The rule names the pair asked for, with the default nationality
+    if rule, _ := Lookup("", "th"); rule.Nationality != "IN" || rule.Destination != "TH" {
+        t.Errorf("rule for %s to %s", rule.Nationality, rule.Destination)
+    }
+
+    rule, _ := Lookup("IN", "LK")
+    if got := strings.Join(rule.Checklist(types.AgeChild), "|"); got != "passport|flightTickets|hotelBookings" {
+        t.Errorf("Sri Lanka child checklist %s", got)
+    }
+    rule, _ = Lookup("IN", "FR")
+    adult, child := rule.Checklist(types.AgeAdult), rule.Checklist(types.AgeInfant)
+    if len(child) != len(adult)+2 || child[len(child)-1] != "parentalConsent" || len(rule.Documents) != len(adult) {
+        t.Errorf("Schengen checklists %v and %v", adult, child)
+    }
+}
+
+func TestChecklists(t *testing.T) {
+    data := types.ItineraryData{
+        TripDetails: types.TripDetails{Destination: "Dubai"},
+        Travellers: []types.Traveller{
+            {Name: "Asha Rao", Nationality: "IN"},
+            {Name: "Kabir Rao", AgeCategory: types.AgeChild},
+            {Name: "John Smith", Nationality: "GB"},
+            {Name: "Meera Rao", Nationality: "IN"},
+        },
+        Visas: []types.Visa{
+            {},
+            {Country: "FR", Traveller: " asha rao "},
+            {Country: "IN", Traveller: "Meera Rao"},
+        },
+    }
+    var got []string
+    for _, c := range Checklists(data) {
+        got = append(got, fmt.Sprintf("%d %s>%s %s %d", c.Visa, c.Rule.Nationality, c.Rule.Destination,
+            strings.Join(c.Travellers, "+"), len(c.Documents)))
+    }
+    // Dubai's airports place the first visa in the UAE; Meera needs no visa
+    // for India
+    want := []string{
+        "0 IN>AE Asha Rao+Meera Rao 4",
+        "0 IN>AE Kabir Rao 5",
+        "0 GB>AE John Smith 6",
+        "1 IN>FR Asha Rao 11",
+    }
+    if strings.Join(got, "\n") != strings.Join(want, "\n") {
+        t.Errorf("checklists\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+    }
+}
+
+func TestChecklistsWithoutTravellers(t *testing.T) {
+    data := types.ItineraryData{
+        TripDetails: types.TripDetails{Destination: "Somewhere"},
+        Visas:       []types.Visa{{Country: "SG", Traveller: "Asha Rao"}, {}},
+    }
+    checklists := Checklists(data)
+    if len(checklists) != 1 {
+        t.Fatalf("checklists %+v, want only the Singapore one", checklists)
+    }
+    c := checklists[0]
+    if c.Rule.Nationality != DefaultNationality || c.Rule.Requirement != EVisa || len(c.Travellers) != 1 || c.Travellers[0] != "Asha Rao" {
+        t.Errorf("checklist %+v", c)
+    }
+}
@@ -0,0 +1,161 @@
+// voucher/voucher.go
+package voucher
+
+import (
+    "fmt"
+    "sort"
+    "strings"
+    "vigovia-pdf-api/datetime"
+    "vigovia-pdf-api/timeline"
+    "vigovia-pdf-api/types"
+)
+
+// Kinds of booking a voucher is printed for
+const (
+    KindHotel    = "hotel"
+    KindTransfer = "transfer"
+    KindActivity = "activity"
+)
+
+// Voucher is one booking a traveller shows to its supplier. Exactly one of
+// Hotel, Transfer and Activity is set, as Kind says.
+type Voucher struct {
+    Kind string
+    // Path is where the booking is in the itinerary, e.g. $.hotels[0]
+    Path string
+    // Date is the day of the service, or of check-in for a stay
+    Date         datetime.Date
+    Confirmation string
+    // Supplier is whom the traveller calls about the booking: its supplier,
+    // or for a stay booked directly the hotel itself
+    Supplier types.Supplier
+    // Travellers are the names the booking is under, the customer's when the
+    // itinerary lists no travellers
+    Travellers []string
+
+    Hotel    *types.Hotel
+    Transfer *types.Transfer
+    Activity *types.Activity
+}
+
+// Build lists a voucher for every booked hotel, transfer and activity in the
+// itinerary, in date order with undated bookings last. A booking is one with
+// a confirmation number or a supplier.
+func Build(data types.ItineraryData) []Voucher {
+    travellers := names(data)
+    var out []Voucher
+    for i := range data.Hotels {
+        h := &data.Hotels[i]
+        if !booked(h.ConfirmationNumber, h.Supplier) {
+            continue
+        }
+        supplier := types.Supplier{Name: h.Name, Phone: h.Phone}
+        if h.Supplier != nil {
+            supplier = *h.Supplier
+        }
+        out = append(out, Voucher{
+            Kind:         KindHotel,
+            Path:         fmt.Sprintf("$.hotels[%d]", i),
+            Date:         h.CheckIn,
+            Confirmation: h.ConfirmationNumber,
+            Supplier:     supplier,
+            Travellers:   travellers,
+            Hotel:        h,
+        })
+    }
+    for d := range data.DailyItinerary {
+        day := &data.DailyItinerary[d]
+        date := timeline.DayDate(data.TripDetails, *day)
+        for i := range day.Transfers {
+            t := &day.Transfers[i]
+            if !booked(t.ConfirmationNumber, t.Supplier) {
+                continue
+            }
+            out = append(out, Voucher{
+                Kind:         KindTransfer,
+                Path:         fmt.Sprintf("$.dailyItinerary[%d].transfers[%d]", d, i),
+                Date:         date,
+                Confirmation: t.ConfirmationNumber,
+                Supplier:     supplierOf(t.Supplier),
+                Travellers:   travellers,
+                Transfer:     t,
+            })
+        }
+        for i := range day.Activities {
+            a := &day.Activities[i]
+            if !booked(a.ConfirmationNumber, a.Supplier) {
+                continue
+            }
+            out = append(out, Voucher{
+                Kind:         KindActivity,
+                Path:         fmt.Sprintf("$.dailyItinerary[%d].activities[%d]", d, i),
+                Date:         date,
+                Confirmation: a.ConfirmationNumber,
+                Supplier:     supplierOf(a.Supplier),
+                Travellers:   travellers,
+                Activity:     a,
+            })
+        }
+    }
+    sort.SliceStable(out, func(i, j int) bool {
+        a, b := out[i].Date, out[j].Date
+        return !a.IsZero() && (b.IsZero() || a.Before(b))
+    })
+    return out
+}
+
+// Text is what the voucher's QR code holds, one item a line: the
+// confirmation number, what was booked and when, and the lead traveller
+func (v Voucher) Text() string {
+    lines := []string{v.Confirmation}
+    switch v.Kind {
+    case KindHotel:
+        lines = append(lines, v.Hotel.Name, joinDates(v.Hotel.CheckIn, v.Hotel.CheckOut))
+    case KindTransfer:
+        lines = append(lines, v.Transfer.Type, strings.TrimSpace(v.Date.String()+" "+v.Transfer.Timing))
+    case KindActivity:
+        lines = append(lines, v.Activity.Name, v.Date.String())
+    }
+    if len(v.Travellers) > 0 {
+        lines = append(lines, v.Travellers[0])
+    }
+    var out []string
+    for _, line := range lines {
+        if line = strings.TrimSpace(line); line != "" {
+            out = append(out, line)
+        }
+    }
+    return strings.Join(out, "\n")
+}
+
+func joinDates(from, to datetime.Date) string {
+    if to.IsZero() {
+        return from.String()
+    }
+    return from.String() + " - " + to.String()
+}
+
+func booked(confirmation string, supplier *types.Supplier) bool {
+    return confirmation != "" || supplier != nil
+}
+
+func supplierOf(s *types.Supplier) types.Supplier {
+    if s == nil {
+        return types.Supplier{}
+    }
+    return *s
+}
+
+// names lists the travellers' names, or the customer's when none are given
+func names(data types.ItineraryData) []string {
+    var out []string
+    for _, t := range data.Travellers {
+        if t.Name != "" {
+            out = append(out, t.Name)
+        }
+    }
+    if len(out) == 0 && data.TripDetails.CustomerName != "" {
+        out = []string{data.TripDetails.CustomerName}
+    }
+    return out
+}